@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/saisaravanan/healing-eval/internal/config"
+	"github.com/saisaravanan/healing-eval/internal/storage"
+	"github.com/saisaravanan/healing-eval/internal/worker"
+)
+
+func main() {
+	dateFlag := flag.String("date", "", "last day to roll up (YYYY-MM-DD, UTC); defaults to yesterday")
+	days := flag.Int("days", 1, "number of days to roll up, ending at -date")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	end := time.Now().UTC().AddDate(0, 0, -1)
+	if *dateFlag != "" {
+		end, err = time.Parse("2006-01-02", *dateFlag)
+		if err != nil {
+			log.Fatalf("Invalid -date %q: %v", *dateFlag, err)
+		}
+	}
+
+	if *days < 1 {
+		log.Fatalf("-days must be at least 1")
+	}
+
+	ctx := context.Background()
+
+	db, err := storage.NewPostgresDB(ctx, &cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	rollup := worker.NewAccuracyRollup(
+		storage.NewEvaluationRepo(db),
+		storage.NewAnnotationRepo(db),
+		storage.NewEvaluatorAccuracyRepo(db),
+	)
+
+	for i := *days - 1; i >= 0; i-- {
+		day := end.AddDate(0, 0, -i)
+		if err := rollup.RunForDate(ctx, day); err != nil {
+			log.Fatalf("Rollup for %s failed: %v", day.Format("2006-01-02"), err)
+		}
+	}
+
+	log.Printf("Accuracy rollup complete for %d day(s) ending %s", *days, end.Format("2006-01-02"))
+}
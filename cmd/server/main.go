@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"net/http"
 	"os"
@@ -11,11 +12,20 @@ import (
 
 	"github.com/saisaravanan/healing-eval/internal/api"
 	"github.com/saisaravanan/healing-eval/internal/config"
+	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/metrics"
 	"github.com/saisaravanan/healing-eval/internal/queue"
 	"github.com/saisaravanan/healing-eval/internal/storage"
+	"github.com/saisaravanan/healing-eval/internal/storage/memory"
+	"github.com/saisaravanan/healing-eval/internal/tracing"
+	"github.com/saisaravanan/healing-eval/internal/webhook"
+	"github.com/saisaravanan/healing-eval/internal/worker"
 )
 
 func main() {
+	standalone := flag.Bool("standalone", false, "run API, worker and in-memory storage and queue in one process")
+	flag.Parse()
+
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
@@ -24,19 +34,53 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	db, err := storage.NewPostgresDB(ctx, &cfg.Database)
+	shutdownTracing, err := tracing.Setup(ctx, &cfg.Tracing, "healing-eval-server")
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Failed to set up tracing: %v", err)
 	}
-	defer db.Close()
+	defer func() {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer flushCancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Printf("Tracing shutdown error: %v", err)
+		}
+	}()
 
-	q, err := queue.NewRedisQueue(&cfg.Redis, &cfg.Worker)
+	cache, err := llm.NewCache(&cfg.LLM, &cfg.Redis)
 	if err != nil {
-		log.Fatalf("Failed to connect to Redis: %v", err)
+		log.Printf("Warning: Failed to create LLM cache, caching disabled: %v", err)
+	} else if cache != nil {
+		defer cache.Close()
+	}
+
+	var repos *storage.Repositories
+	var q queue.Queue
+	var webhooks *webhook.Dispatcher
+	stopWorker := func() {}
+
+	if *standalone {
+		repos, q, webhooks, stopWorker = startStandalone(ctx, cfg, cache)
+	} else {
+		db, err := storage.NewPostgresDB(ctx, &cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer db.Close()
+
+		redisQueue, err := queue.NewRedisQueue(&cfg.Redis, &cfg.Worker)
+		if err != nil {
+			log.Fatalf("Failed to connect to Redis: %v", err)
+		}
+		defer redisQueue.Close()
+
+		repos = storage.NewPostgresRepositories(db)
+		q = redisQueue
+		webhooks = webhook.NewDispatcher(repos.Webhooks, &cfg.Webhook)
 	}
-	defer q.Close()
 
-	router := api.NewRouter(db, q)
+	metrics.RegisterState(q, repos.Reviews)
+
+	router := api.NewRouter(repos, q, webhooks, cache)
 
 	srv := &http.Server{
 		Addr:         cfg.Server.Addr(),
@@ -64,7 +108,51 @@ func main() {
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Fatalf("Server shutdown error: %v", err)
 	}
+	router.Close()
+	// The embedded worker emits webhooks, so it stops before the dispatcher.
+	stopWorker()
+	webhooks.Close()
 
 	log.Println("Server stopped")
 }
 
+// startStandalone creates in-memory storage and queue and runs a worker
+// against them until ctx is cancelled or the returned stop func is called,
+// which waits for the worker to finish. Nothing is persisted. The worker
+// and API share one webhook dispatcher and LLM cache.
+func startStandalone(ctx context.Context, cfg *config.Config, cache llm.Cache) (*storage.Repositories, queue.Queue, *webhook.Dispatcher, func()) {
+	log.Println("Running standalone: in-memory storage and queue, embedded worker")
+
+	repos := memory.NewRepositories()
+	q, err := queue.NewMemoryQueue(&cfg.Worker)
+	if err != nil {
+		log.Fatalf("Failed to create queue: %v", err)
+	}
+
+	webhooks := webhook.NewDispatcher(repos.Webhooks, &cfg.Webhook)
+
+	w, err := worker.NewFromConfig(cfg, repos, q, webhooks, cache)
+	if err != nil {
+		log.Fatalf("Failed to create worker: %v", err)
+	}
+
+	workerCtx, cancelWorker := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := w.Start(workerCtx); err != nil {
+			log.Printf("Worker error: %v", err)
+		}
+	}()
+	stop := func() {
+		cancelWorker()
+		<-done
+	}
+
+	if cfg.Worker.AccuracyRollupEnabled {
+		rollup := worker.NewAccuracyRollup(repos.Evaluations, repos.Annotations, repos.Accuracy)
+		go rollup.Start(workerCtx, cfg.Worker.AccuracyRollupInterval)
+	}
+
+	return repos, q, webhooks, stop
+}
@@ -3,15 +3,19 @@ package main
 import (
 	"context"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/saisaravanan/healing-eval/internal/config"
-	"github.com/saisaravanan/healing-eval/internal/evaluator"
 	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/metrics"
 	"github.com/saisaravanan/healing-eval/internal/queue"
 	"github.com/saisaravanan/healing-eval/internal/storage"
+	"github.com/saisaravanan/healing-eval/internal/tracing"
+	"github.com/saisaravanan/healing-eval/internal/webhook"
 	"github.com/saisaravanan/healing-eval/internal/worker"
 )
 
@@ -24,6 +28,18 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	shutdownTracing, err := tracing.Setup(ctx, &cfg.Tracing, "healing-eval-worker")
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer func() {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer flushCancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Printf("Tracing shutdown error: %v", err)
+		}
+	}()
+
 	db, err := storage.NewPostgresDB(ctx, &cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
@@ -36,31 +52,42 @@ func main() {
 	}
 	defer q.Close()
 
-	llmClient, err := llm.NewClient(&cfg.LLM)
+	repos := storage.NewPostgresRepositories(db)
+
+	webhooks := webhook.NewDispatcher(repos.Webhooks, &cfg.Webhook)
+	defer webhooks.Close()
+
+	cache, err := llm.NewCache(&cfg.LLM, &cfg.Redis)
 	if err != nil {
-		log.Fatalf("Failed to create LLM client: %v", err)
+		log.Fatalf("Failed to create LLM cache: %v", err)
+	}
+	if cache != nil {
+		defer cache.Close()
 	}
 
-	orchestrator := evaluator.NewOrchestrator(
-		evaluator.NewHeuristicEvaluator(1000),
-		evaluator.NewLLMJudgeEvaluator(llmClient),
-		evaluator.NewToolCallEvaluator(llmClient),
-		evaluator.NewCoherenceEvaluator(llmClient),
-	)
-
-	convRepo := storage.NewConversationRepo(db)
-	evalRepo := storage.NewEvaluationRepo(db)
-	reviewQueueRepo := storage.NewReviewQueueRepo(db)
-
-	w := worker.New(
-		q,
-		convRepo,
-		evalRepo,
-		reviewQueueRepo,
-		orchestrator,
-		cfg.Worker.Concurrency,
-		cfg.Worker.BatchSize,
-	)
+	w, err := worker.NewFromConfig(cfg, repos, q, webhooks, cache)
+	if err != nil {
+		log.Fatalf("Failed to create worker: %v", err)
+	}
+
+	if cfg.Worker.MetricsAddr != "" {
+		metrics.RegisterState(q, repos.Reviews)
+
+		mux := http.NewServeMux()
+		mux.Handle(metrics.Path, metrics.Handler())
+		go func() {
+			log.Printf("Metrics listening on %s%s", cfg.Worker.MetricsAddr, metrics.Path)
+			if err := http.ListenAndServe(cfg.Worker.MetricsAddr, mux); err != nil {
+				log.Printf("Metrics server error: %v", err)
+			}
+		}()
+	}
+
+	if cfg.Worker.AccuracyRollupEnabled {
+		rollup := worker.NewAccuracyRollup(repos.Evaluations, repos.Annotations, repos.Accuracy)
+		rollup.UseLeaser(q)
+		go rollup.Start(ctx, cfg.Worker.AccuracyRollupInterval)
+	}
 
 	go func() {
 		quit := make(chan os.Signal, 1)
@@ -77,4 +104,3 @@ func main() {
 
 	log.Println("Worker stopped")
 }
-
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saisaravanan/healing-eval/internal/queue"
+)
+
+// AdminHandler exposes queue maintenance: inspecting, requeueing and purging
+// dead-lettered messages.
+type AdminHandler struct {
+	queue queue.Queue
+}
+
+func NewAdminHandler(q queue.Queue) *AdminHandler {
+	return &AdminHandler{queue: q}
+}
+
+// GET /api/v1/admin/dead-letters
+func (h *AdminHandler) ListDeadLetters(c *gin.Context) {
+	limit := int64(50)
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.ParseInt(limitStr, 10, 64); err == nil && l > 0 && l <= 500 {
+			limit = l
+		}
+	}
+
+	ctx := c.Request.Context()
+	letters, err := h.queue.DeadLetters(ctx, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list dead letters"})
+		return
+	}
+
+	total, err := h.queue.DeadLetterCount(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count dead letters"})
+		return
+	}
+
+	if letters == nil {
+		letters = []queue.DeadLetter{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dead_letters": letters,
+		"count":        len(letters),
+		"total":        total,
+	})
+}
+
+// GET /api/v1/admin/dead-letters/:id
+func (h *AdminHandler) GetDeadLetter(c *gin.Context) {
+	letter, err := h.queue.GetDeadLetter(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, queue.ErrDeadLetterNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "dead letter not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch dead letter"})
+		return
+	}
+
+	// Include the decoded conversation when the payload is valid.
+	conv, decodeErr := letter.Conversation()
+	response := gin.H{"dead_letter": letter, "conversation": conv}
+	if decodeErr != nil {
+		response["decode_error"] = decodeErr.Error()
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// POST /api/v1/admin/dead-letters/:id/requeue
+func (h *AdminHandler) RequeueDeadLetter(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.queue.RequeueDeadLetter(c.Request.Context(), id); err != nil {
+		if errors.Is(err, queue.ErrDeadLetterNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "dead letter not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to requeue dead letter"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "requeued", "id": id})
+}
+
+// DELETE /api/v1/admin/dead-letters/:id
+func (h *AdminHandler) DeleteDeadLetter(c *gin.Context) {
+	id := c.Param("id")
+	n, err := h.queue.PurgeDeadLetters(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete dead letter"})
+		return
+	}
+	if n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "dead letter not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "deleted", "id": id})
+}
+
+// DELETE /api/v1/admin/dead-letters
+func (h *AdminHandler) PurgeDeadLetters(c *gin.Context) {
+	n, err := h.queue.PurgeDeadLetters(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to purge dead letters"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "purged", "deleted": n})
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/storage"
+)
+
+type AnnotationHandler struct {
+	repo     storage.AnnotationRepository
+	convRepo storage.ConversationRepository
+}
+
+func NewAnnotationHandler(repo storage.AnnotationRepository, convRepo storage.ConversationRepository) *AnnotationHandler {
+	return &AnnotationHandler{repo: repo, convRepo: convRepo}
+}
+
+type CreateAnnotationsRequest struct {
+	Annotations []*domain.Annotation `json:"annotations"`
+}
+
+// POST /api/v1/conversations/:id/annotations
+func (h *AnnotationHandler) Create(c *gin.Context) {
+	conversationID := c.Param("id")
+	if conversationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+		return
+	}
+
+	var req CreateAnnotationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if len(req.Annotations) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no annotations provided"})
+		return
+	}
+
+	for _, ann := range req.Annotations {
+		if ann.AnnotatorID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "annotator_id is required"})
+			return
+		}
+		if ann.Type == "" || ann.Label == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "type and label are required"})
+			return
+		}
+		if ann.Confidence < 0 || ann.Confidence > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "confidence must be between 0 and 1"})
+			return
+		}
+		ann.ConversationID = conversationID
+	}
+
+	conv, err := h.convRepo.GetByID(c.Request.Context(), conversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve conversation"})
+		return
+	}
+	if conv == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "conversation not found"})
+		return
+	}
+
+	if err := h.repo.CreateBatch(c.Request.Context(), req.Annotations); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store annotations"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"conversation_id": conversationID,
+		"annotations":     req.Annotations,
+		"count":           len(req.Annotations),
+	})
+}
+
+// GET /api/v1/conversations/:id/annotations
+func (h *AnnotationHandler) GetByConversationID(c *gin.Context) {
+	conversationID := c.Param("id")
+	if conversationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+		return
+	}
+
+	anns, err := h.repo.GetByConversationID(c.Request.Context(), conversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve annotations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"conversation_id": conversationID,
+		"annotations":     anns,
+		"count":           len(anns),
+	})
+}
+
+type CreateAnnotatorRequest struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// POST /api/v1/annotators
+func (h *AnnotationHandler) CreateAnnotator(c *gin.Context) {
+	var req CreateAnnotatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if req.ID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+		return
+	}
+
+	if req.Name == "" {
+		req.Name = req.ID
+	}
+
+	annotator := &domain.Annotator{ID: req.ID, Name: req.Name}
+	if err := h.repo.CreateAnnotator(c.Request.Context(), annotator); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store annotator"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, annotator)
+}
+
+// GET /api/v1/annotators
+func (h *AnnotationHandler) ListAnnotators(c *gin.Context) {
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 500 {
+			limit = l
+		}
+	}
+
+	offset := 0
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o > 0 {
+			offset = o
+		}
+	}
+
+	annotators, err := h.repo.ListAnnotators(c.Request.Context(), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list annotators"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"annotators": annotators,
+		"count":      len(annotators),
+	})
+}
+
+// GET /api/v1/annotators/:id
+func (h *AnnotationHandler) GetAnnotator(c *gin.Context) {
+	id := c.Param("id")
+
+	annotator, err := h.repo.GetAnnotator(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve annotator"})
+		return
+	}
+
+	if annotator == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "annotator not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, annotator)
+}
@@ -1,34 +1,60 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/ingest"
+	"github.com/saisaravanan/healing-eval/internal/llm"
 	"github.com/saisaravanan/healing-eval/internal/queue"
 	"github.com/saisaravanan/healing-eval/internal/storage"
+	"github.com/saisaravanan/healing-eval/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const MaxConversationsPerRequest = 30
 
 type ConversationHandler struct {
-	repo  *storage.ConversationRepo
-	queue *queue.RedisQueue
+	repo    storage.ConversationRepository
+	jobRepo storage.JobRepository
+	queue   queue.Queue
+
+	// Conversations from trace exports wait otlpDebounce for more spans
+	// before they are queued.
+	otlpDebounce time.Duration
+	otlpMu       sync.Mutex
+	otlpPending  map[string]*pendingOTLP
 }
 
-func NewConversationHandler(repo *storage.ConversationRepo, q *queue.RedisQueue) *ConversationHandler {
-	return &ConversationHandler{repo: repo, queue: q}
+func NewConversationHandler(repo storage.ConversationRepository, jobRepo storage.JobRepository, q queue.Queue) *ConversationHandler {
+	return &ConversationHandler{repo: repo, jobRepo: jobRepo, queue: q, otlpPending: make(map[string]*pendingOTLP)}
 }
 
+// IngestRequest carries conversations in the native schema or, when Format
+// is "openai" or "anthropic", as that provider's message arrays; see
+// ingest.Decode.
 type IngestRequest struct {
-	Conversations []*domain.Conversation `json:"conversations"`
-	Priority      string                 `json:"priority,omitempty"`
+	Conversations []json.RawMessage `json:"conversations"`
+	Format        string            `json:"format,omitempty"`
+	Priority      string            `json:"priority,omitempty"`
+	// BypassCache re-judges the conversations with fresh LLM responses
+	// instead of cached ones.
+	BypassCache bool `json:"bypass_cache,omitempty"`
 }
 
 type IngestResponse struct {
-	Accepted int      `json:"accepted"`
-	JobID    string   `json:"job_id,omitempty"`
-	IDs      []string `json:"ids"`
+	Accepted int            `json:"accepted"`
+	JobID    string         `json:"job_id,omitempty"`
+	Priority queue.Priority `json:"priority"`
+	IDs      []string       `json:"ids"`
 }
 
 func (h *ConversationHandler) Ingest(c *gin.Context) {
@@ -48,7 +74,27 @@ func (h *ConversationHandler) Ingest(c *gin.Context) {
 		return
 	}
 
-	for _, conv := range req.Conversations {
+	priority, err := queue.ParsePriority(req.Priority)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format, err := ingest.ParseFormat(req.Format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	convs := make([]*domain.Conversation, len(req.Conversations))
+	for i, raw := range req.Conversations {
+		if convs[i], err = ingest.Decode(format, raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	for _, conv := range convs {
 		if conv.ID == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "conversation_id is required"})
 			return
@@ -59,27 +105,89 @@ func (h *ConversationHandler) Ingest(c *gin.Context) {
 		}
 	}
 
-	if err := h.repo.CreateBatch(c.Request.Context(), req.Conversations); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store conversations"})
-		return
+	ctx := c.Request.Context()
+	if req.BypassCache {
+		ctx = llm.WithCacheBypass(ctx)
 	}
 
-	if err := h.queue.PublishBatch(c.Request.Context(), req.Conversations); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue conversations"})
+	job, err := h.enqueue(ctx, convs, priority)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	ids := make([]string, len(req.Conversations))
-	for i, conv := range req.Conversations {
+	ids := make([]string, len(convs))
+	for i, conv := range convs {
 		ids[i] = conv.ID
 	}
 
 	c.JSON(http.StatusAccepted, IngestResponse{
-		Accepted: len(req.Conversations),
+		Accepted: len(convs),
+		JobID:    job.ID,
+		Priority: priority,
 		IDs:      ids,
 	})
 }
 
+// enqueue stores conversations, tracks them as a job and queues them for
+// evaluation. Errors are worded for the client.
+func (h *ConversationHandler) enqueue(ctx context.Context, convs []*domain.Conversation, priority queue.Priority) (*domain.Job, error) {
+	if err := h.repo.CreateBatch(ctx, convs); err != nil {
+		log.Printf("Ingest: store conversations: %v", err)
+		return nil, errors.New("failed to store conversations")
+	}
+
+	job, err := h.newJob(ctx, convs, priority)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.publish(ctx, job, convs, priority); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// newJob tracks stored conversations as a job. The job exists before
+// publishing so workers always find it.
+func (h *ConversationHandler) newJob(ctx context.Context, convs []*domain.Conversation, priority queue.Priority) (*domain.Job, error) {
+	job := &domain.Job{Conversations: make([]domain.JobConversation, len(convs))}
+	for i, conv := range convs {
+		job.Conversations[i].ConversationID = conv.ID
+	}
+	if err := h.jobRepo.Create(ctx, job); err != nil {
+		log.Printf("Ingest: create job: %v", err)
+		return nil, errors.New("failed to create job")
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("job.id", job.ID),
+		attribute.String("queue.priority", string(priority)),
+		attribute.Int("conversations.count", len(convs)),
+	)
+	return job, nil
+}
+
+// publish queues the job's conversations, deleting the job if that fails.
+func (h *ConversationHandler) publish(ctx context.Context, job *domain.Job, convs []*domain.Conversation, priority queue.Priority) error {
+	// The publish span's context travels with each message, so the worker's
+	// spans join this trace.
+	ctx, span := tracing.Tracer().Start(ctx, "queue publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("queue.priority", string(priority))),
+	)
+	defer span.End()
+	if err := h.queue.PublishBatch(ctx, convs, priority); err != nil {
+		tracing.RecordError(span, err)
+		log.Printf("Ingest: publish: %v", err)
+		// Nothing will move the job on, and the client never learns its ID.
+		if err := h.jobRepo.Delete(ctx, job.ID); err != nil {
+			log.Printf("Ingest: delete job %s: %v", job.ID, err)
+		}
+		return errors.New("failed to queue conversations")
+	}
+	return nil
+}
+
 func (h *ConversationHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
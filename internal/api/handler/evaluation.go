@@ -9,11 +9,12 @@ import (
 )
 
 type EvaluationHandler struct {
-	repo *storage.EvaluationRepo
+	repo    storage.EvaluationRepository
+	aggRepo storage.AggregatedEvaluationRepository
 }
 
-func NewEvaluationHandler(repo *storage.EvaluationRepo) *EvaluationHandler {
-	return &EvaluationHandler{repo: repo}
+func NewEvaluationHandler(repo storage.EvaluationRepository, aggRepo storage.AggregatedEvaluationRepository) *EvaluationHandler {
+	return &EvaluationHandler{repo: repo, aggRepo: aggRepo}
 }
 
 func (h *EvaluationHandler) GetByConversationID(c *gin.Context) {
@@ -35,6 +36,45 @@ func (h *EvaluationHandler) GetByConversationID(c *gin.Context) {
 	})
 }
 
+// GET /api/v1/conversations/:id/evaluation
+func (h *EvaluationHandler) GetAggregated(c *gin.Context) {
+	conversationID := c.Param("id")
+
+	agg, err := h.aggRepo.GetByConversationID(c.Request.Context(), conversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve evaluation"})
+		return
+	}
+	if agg == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "evaluation not found"})
+		return
+	}
+
+	evals, err := h.repo.GetByConversationID(c.Request.Context(), conversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve evaluations"})
+		return
+	}
+	agg.Evaluations = latestSuccessful(evals)
+
+	c.JSON(http.StatusOK, agg)
+}
+
+// latestSuccessful keeps the newest successful evaluation of each evaluator
+// type, the ones the aggregate was built from. evals must be newest first.
+func latestSuccessful(evals []*domain.Evaluation) []domain.Evaluation {
+	seen := make(map[domain.EvaluatorType]bool)
+	latest := []domain.Evaluation{}
+	for _, e := range evals {
+		if e.Status != domain.EvalStatusSuccess || seen[e.EvaluatorType] {
+			continue
+		}
+		seen[e.EvaluatorType] = true
+		latest = append(latest, *e)
+	}
+	return latest
+}
+
 func (h *EvaluationHandler) Query(c *gin.Context) {
 	var req domain.EvaluationsQueryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/storage"
+)
+
+// jobPollInterval is how often an event stream re-reads its job.
+const jobPollInterval = time.Second
+
+// JobHandler reports the progress of ingestion jobs.
+type JobHandler struct {
+	repo storage.JobRepository
+}
+
+func NewJobHandler(repo storage.JobRepository) *JobHandler {
+	return &JobHandler{repo: repo}
+}
+
+// GET /api/v1/jobs/:id
+func (h *JobHandler) GetByID(c *gin.Context) {
+	job, err := h.repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve job"})
+		return
+	}
+
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// GET /api/v1/jobs/:id/events
+//
+// Streams the job as server-sent "progress" events whenever it changes, then
+// one "complete" event once every conversation has finished evaluating,
+// retries included.
+func (h *JobHandler) Events(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	job, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve job"})
+		return
+	}
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+
+	// The stream outlives the server's write timeout.
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	var last *domain.Job
+	c.Stream(func(w io.Writer) bool {
+		if last != nil {
+			select {
+			case <-ctx.Done():
+				return false
+			case <-ticker.C:
+			}
+
+			job, err = h.repo.GetByID(ctx, id)
+			if err != nil || job == nil {
+				c.SSEvent("error", gin.H{"error": "failed to retrieve job"})
+				return false
+			}
+		}
+
+		if job.Status.Terminal() {
+			c.SSEvent("complete", job)
+			return false
+		}
+
+		if last == nil || jobChanged(last, job) {
+			c.SSEvent("progress", job)
+		}
+		last = job
+		return true
+	})
+}
+
+func jobChanged(a, b *domain.Job) bool {
+	if a.Status != b.Status || !a.UpdatedAt.Equal(b.UpdatedAt) {
+		return true
+	}
+	for status, n := range b.Counts {
+		if a.Counts[status] != n {
+			return true
+		}
+	}
+	return false
+}
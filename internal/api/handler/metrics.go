@@ -1,109 +1,312 @@
 package handler
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/meta"
+	"github.com/saisaravanan/healing-eval/internal/storage"
 )
 
-type MetricsHandler struct{}
+const (
+	defaultMetricsWindow   = 30 * 24 * time.Hour
+	defaultRecallThreshold = 0.5
+	maxBlindSpotExamples   = 3
+)
 
-func NewMetricsHandler() *MetricsHandler {
-	return &MetricsHandler{}
+type MetricsHandler struct {
+	evalRepo           storage.EvaluationRepository
+	annotationRepo     storage.AnnotationRepository
+	accuracyRepo       storage.EvaluatorAccuracyRepository
+	accuracyTracker    *meta.AccuracyTracker
+	calibrationService *meta.CalibrationService
 }
 
+func NewMetricsHandler(
+	evalRepo storage.EvaluationRepository,
+	annotationRepo storage.AnnotationRepository,
+	accuracyRepo storage.EvaluatorAccuracyRepository,
+) *MetricsHandler {
+	return &MetricsHandler{
+		evalRepo:           evalRepo,
+		annotationRepo:     annotationRepo,
+		accuracyRepo:       accuracyRepo,
+		accuracyTracker:    meta.NewAccuracyTracker(),
+		calibrationService: meta.NewCalibrationService(),
+	}
+}
+
+// GET /api/v1/metrics/evaluators
 func (h *MetricsHandler) GetEvaluators(c *gin.Context) {
+	types, period, err := parseMetricsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	set, err := h.loadLabeledSet(c.Request.Context(), types, period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load labeled evaluations"})
+		return
+	}
+
+	if len(types) == 0 {
+		types = set.EvaluatorTypes()
+	}
+
 	response := domain.EvaluatorMetricsResponse{
-		Evaluators: []domain.EvaluatorMetricsSummary{
-			{
-				EvaluatorType:  domain.EvaluatorTypeLLMJudge,
-				Precision:      0.89,
-				Recall:         0.76,
-				F1Score:        0.82,
-				SampleCount:    1250,
-				FalsePositives: 45,
-				FalseNegatives: 112,
-			},
-			{
-				EvaluatorType:  domain.EvaluatorTypeToolCall,
-				Precision:      0.94,
-				Recall:         0.88,
-				F1Score:        0.91,
-				SampleCount:    890,
-				FalsePositives: 23,
-				FalseNegatives: 48,
-			},
-			{
-				EvaluatorType:  domain.EvaluatorTypeCoherence,
-				Precision:      0.85,
-				Recall:         0.72,
-				F1Score:        0.78,
-				SampleCount:    654,
-				FalsePositives: 67,
-				FalseNegatives: 89,
-			},
-			{
-				EvaluatorType:  domain.EvaluatorTypeHeuristic,
-				Precision:      0.98,
-				Recall:         0.95,
-				F1Score:        0.96,
-				SampleCount:    2100,
-				FalsePositives: 12,
-				FalseNegatives: 35,
-			},
-		},
+		Evaluators: []domain.EvaluatorMetricsSummary{},
+	}
+
+	for _, et := range types {
+		result := h.accuracyTracker.Calculate(set.Predictions(et))
+		response.Evaluators = append(response.Evaluators, domain.EvaluatorMetricsSummary{
+			EvaluatorType:  et,
+			Precision:      result.Precision,
+			Recall:         result.Recall,
+			F1Score:        result.F1Score,
+			SampleCount:    result.TruePositives + result.FalsePositives + result.TrueNegatives + result.FalseNegatives,
+			FalsePositives: result.FalsePositives,
+			FalseNegatives: result.FalseNegatives,
+		})
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// GET /api/v1/metrics/evaluators/history
+func (h *MetricsHandler) GetEvaluatorHistory(c *gin.Context) {
+	types, period, err := parseMetricsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := h.accuracyRepo.History(c.Request.Context(), types, period.From, period.To)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load evaluator history"})
+		return
+	}
+
+	response := domain.EvaluatorAccuracyHistoryResponse{
+		Period: period,
+		Series: make(map[domain.EvaluatorType][]domain.EvaluatorAccuracy),
+	}
+	for _, row := range history {
+		response.Series[row.EvaluatorType] = append(response.Series[row.EvaluatorType], *row)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GET /api/v1/metrics/failures
+func (h *MetricsHandler) GetFailureRates(c *gin.Context) {
+	types, period, err := parseMetricsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rates, err := h.evalRepo.FailureRates(c.Request.Context(), types, period.From, period.To)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load failure rates"})
+		return
+	}
+
+	response := domain.EvaluatorFailureRatesResponse{
+		Period: period,
+		Rates:  []domain.EvaluatorFailureRate{},
+	}
+	for _, rate := range rates {
+		response.Rates = append(response.Rates, *rate)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GET /api/v1/metrics/calibration
 func (h *MetricsHandler) GetCalibration(c *gin.Context) {
-	evaluatorType := c.Query("evaluator_type")
-	if evaluatorType == "" {
-		evaluatorType = "llm_judge"
+	types, period, err := parseMetricsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Calibration compares one evaluator's scores with human scores, so
+	// several types would need several responses.
+	if len(types) > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "calibration takes a single evaluator_type"})
+		return
+	}
+	evaluatorType := domain.EvaluatorTypeLLMJudge
+	if len(types) == 1 {
+		evaluatorType = types[0]
 	}
 
-	response := domain.CalibrationMetrics{
-		EvaluatorType:       domain.EvaluatorType(evaluatorType),
-		PearsonCorrelation:  0.82,
-		SpearmanCorrelation: 0.79,
-		MeanAbsoluteError:   0.08,
-		SampleCount:         1250,
-		BreakdownByCategory: map[string]domain.CategoryMetrics{
-			"response_quality": {Correlation: 0.85, MeanAbsoluteError: 0.06},
-			"helpfulness":      {Correlation: 0.78, MeanAbsoluteError: 0.10},
-			"factuality":       {Correlation: 0.81, MeanAbsoluteError: 0.09},
-		},
-		DriftStatus: "stable",
+	set, err := h.loadLabeledSet(c.Request.Context(), []domain.EvaluatorType{evaluatorType}, period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load labeled evaluations"})
+		return
+	}
+
+	pairs, byCategory := set.ComparisonPairs(evaluatorType)
+	response := h.calibrationService.CalculateCalibration(c.Request.Context(), evaluatorType, pairs, period.From, period.To)
+
+	for category, catPairs := range byCategory {
+		if len(catPairs) < 2 {
+			continue
+		}
+		catMetrics := h.calibrationService.CalculateCalibration(c.Request.Context(), evaluatorType, catPairs, period.From, period.To)
+		if response.BreakdownByCategory == nil {
+			response.BreakdownByCategory = make(map[string]domain.CategoryMetrics)
+		}
+		response.BreakdownByCategory[category] = domain.CategoryMetrics{
+			Correlation:       catMetrics.PearsonCorrelation,
+			MeanAbsoluteError: catMetrics.MeanAbsoluteError,
+		}
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// GET /api/v1/metrics/blind-spots
 func (h *MetricsHandler) GetBlindSpots(c *gin.Context) {
+	types, period, err := parseMetricsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	threshold := defaultRecallThreshold
+	if v := c.Query("recall_threshold"); v != "" {
+		t, err := strconv.ParseFloat(v, 64)
+		if err != nil || t <= 0 || t > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "recall_threshold must be between 0 and 1"})
+			return
+		}
+		threshold = t
+	}
+
+	set, err := h.loadLabeledSet(c.Request.Context(), types, period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load labeled evaluations"})
+		return
+	}
+
+	if len(types) == 0 {
+		types = set.EvaluatorTypes()
+	}
+
 	response := domain.BlindSpotsResponse{
-		BlindSpots: []domain.BlindSpot{
-			{
-				EvaluatorType:   domain.EvaluatorTypeLLMJudge,
-				IssueType:       "subtle_factual_error",
-				Recall:          0.34,
-				SampleCount:     89,
-				SuggestedAction: "Add explicit fact-checking prompt section",
-				Examples: []domain.BlindSpotExample{
-					{ConversationID: "conv_123", Description: "Missed incorrect date format"},
-				},
-			},
-			{
-				EvaluatorType:   domain.EvaluatorTypeCoherence,
-				IssueType:       "pronoun_resolution_failure",
-				Recall:          0.42,
-				SampleCount:     67,
-				SuggestedAction: "Enhance entity tracking in prompt",
-			},
-		},
+		BlindSpots: []domain.BlindSpot{},
 	}
 
+	for _, et := range types {
+		predictions := set.Predictions(et)
+
+		for _, spot := range h.accuracyTracker.DetectBlindSpots(predictions, threshold) {
+			// Categories humans never flagged have no recall to speak of.
+			if spot.TotalActual == 0 {
+				continue
+			}
+
+			var examples []domain.BlindSpotExample
+			for _, p := range predictions {
+				if len(examples) >= maxBlindSpotExamples {
+					break
+				}
+				if p.Category == spot.Category && p.Actual && !p.Predicted {
+					examples = append(examples, domain.BlindSpotExample{
+						ConversationID: p.ConversationID,
+						Description:    fmt.Sprintf("Human-labelled %s issue not flagged by %s", spot.Category, et),
+					})
+				}
+			}
+
+			response.BlindSpots = append(response.BlindSpots, domain.BlindSpot{
+				EvaluatorType:   et,
+				IssueType:       spot.Category,
+				Recall:          spot.Recall,
+				SampleCount:     spot.TotalActual,
+				Examples:        examples,
+				SuggestedAction: spot.SuggestedAction,
+			})
+		}
+	}
+
+	sort.Slice(response.BlindSpots, func(i, j int) bool {
+		return response.BlindSpots[i].Recall < response.BlindSpots[j].Recall
+	})
+
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *MetricsHandler) loadLabeledSet(ctx context.Context, types []domain.EvaluatorType, period domain.DateRange) (*meta.LabeledSet, error) {
+	evals, err := h.evalRepo.GetLabeled(ctx, types, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+
+	anns, err := h.annotationRepo.GetByConversationIDs(ctx, meta.ConversationIDs(evals))
+	if err != nil {
+		return nil, err
+	}
+
+	return meta.NewLabeledSet(evals, anns), nil
+}
+
+// parseMetricsFilter reads the evaluator_type (comma-separated) and
+// date_from/date_to (RFC 3339 or YYYY-MM-DD) query params. The period
+// defaults to the last 30 days.
+func parseMetricsFilter(c *gin.Context) ([]domain.EvaluatorType, domain.DateRange, error) {
+	var types []domain.EvaluatorType
+	if v := c.Query("evaluator_type"); v != "" {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, domain.EvaluatorType(t))
+			}
+		}
+	}
+
+	period := domain.DateRange{To: time.Now()}
+	if v := c.Query("date_to"); v != "" {
+		t, err := parseDateParam(v)
+		if err != nil {
+			return nil, period, fmt.Errorf("invalid date_to: %s", v)
+		}
+		if len(v) == len("2006-01-02") {
+			// A bare date includes the whole day.
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		period.To = t
+	}
+
+	period.From = period.To.Add(-defaultMetricsWindow)
+	if v := c.Query("date_from"); v != "" {
+		t, err := parseDateParam(v)
+		if err != nil {
+			return nil, period, fmt.Errorf("invalid date_from: %s", v)
+		}
+		period.From = t
+	}
+
+	if period.From.After(period.To) {
+		return nil, period, fmt.Errorf("date_from must be before date_to")
+	}
+
+	return types, period, nil
+}
+
+func parseDateParam(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", v)
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/otlp"
+	"github.com/saisaravanan/healing-eval/internal/queue"
+	"github.com/saisaravanan/healing-eval/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+)
+
+// maxOTLPBodyBytes caps a decompressed trace export.
+const maxOTLPBodyBytes = 32 << 20
+
+// POST /api/v1/otlp/v1/traces
+//
+// An OTLP/HTTP trace receiver: point an exporter's
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT here. GenAI spans are converted to
+// conversations and queued like ingested ones, after the debounce window
+// when one is set; other spans are ignored. Conversations already stored
+// from earlier exports gain the new turns; those stored through the API are
+// left alone and reported as a partial success. The lane can be chosen with
+// ?priority=.
+func (h *ConversationHandler) IngestOTLP(c *gin.Context) {
+	mediaType, err := otlp.MediaType(c.ContentType())
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "content type must be application/x-protobuf or application/json"})
+		return
+	}
+
+	priority, err := queue.ParsePriority(c.Query("priority"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var body io.Reader = c.Request.Body
+	if c.GetHeader("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid gzip body"})
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, io.NopCloser(body), maxOTLPBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		return
+	}
+
+	req, err := otlp.DecodeRequest(mediaType, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid trace export"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var convs []*domain.Conversation
+	var rejected []string
+	for _, conv := range otlp.Convert(req) {
+		merged, err := h.repo.Update(ctx, conv.ID, func(existing *domain.Conversation) (*domain.Conversation, error) {
+			return otlp.Merge(existing, conv)
+		})
+		if errors.Is(err, otlp.ErrNotFromTraces) {
+			rejected = append(rejected, conv.ID)
+			continue
+		}
+		if err != nil {
+			log.Printf("OTLP: store %s: %v", conv.ID, err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "failed to store conversations"})
+			return
+		}
+		convs = append(convs, merged)
+	}
+
+	if len(convs) > 0 {
+		job, err := h.newJob(ctx, convs, priority)
+		if err == nil && h.otlpDebounce > 0 {
+			h.debounceOTLP(ctx, convs, priority)
+		} else if err == nil {
+			err = h.publish(ctx, job, convs, priority)
+		}
+		if err != nil {
+			// Exporters retry on 503.
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("OTLP: stored %d conversations as job %s", len(convs), job.ID)
+		c.Header("X-Job-ID", job.ID)
+	}
+
+	export := &coltracepb.ExportTraceServiceResponse{}
+	if len(rejected) > 0 {
+		export.PartialSuccess = &coltracepb.ExportTracePartialSuccess{
+			ErrorMessage: "spans dropped: conversations " + strings.Join(rejected, ", ") + " exist and were not ingested from traces",
+		}
+	}
+	resp, err := otlp.EncodeResponse(mediaType, export)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encode response"})
+		return
+	}
+	c.Data(http.StatusOK, mediaType, resp)
+}
+
+// otlpPublishTimeout bounds queueing one debounced conversation.
+const otlpPublishTimeout = 30 * time.Second
+
+// pendingOTLP is a conversation from a trace export waiting out its
+// debounce window.
+type pendingOTLP struct {
+	priority queue.Priority
+	link     trace.Link // to the latest export's request
+	timer    *time.Timer
+}
+
+// DebounceOTLP makes conversations from trace exports wait until window
+// has passed without a further export touching them before they are
+// queued, so a conversation whose spans arrive over several exports is
+// evaluated once. Their jobs are created at once. Zero queues each export.
+func (h *ConversationHandler) DebounceOTLP(window time.Duration) {
+	h.otlpDebounce = window
+}
+
+// FlushOTLP queues every conversation still waiting out its window. Call
+// it on shutdown.
+func (h *ConversationHandler) FlushOTLP() {
+	h.otlpMu.Lock()
+	pending := h.otlpPending
+	h.otlpPending = make(map[string]*pendingOTLP)
+	h.otlpMu.Unlock()
+
+	for id, p := range pending {
+		p.timer.Stop()
+		h.publishOTLP(id, p)
+	}
+}
+
+// debounceOTLP (re)starts the window of each conversation; the latest
+// export's priority wins.
+func (h *ConversationHandler) debounceOTLP(ctx context.Context, convs []*domain.Conversation, priority queue.Priority) {
+	link := trace.LinkFromContext(ctx)
+
+	h.otlpMu.Lock()
+	defer h.otlpMu.Unlock()
+	for _, conv := range convs {
+		id := conv.ID
+		if p, ok := h.otlpPending[id]; ok {
+			p.timer.Stop()
+		}
+		p := &pendingOTLP{priority: priority, link: link}
+		p.timer = time.AfterFunc(h.otlpDebounce, func() {
+			h.otlpMu.Lock()
+			current := h.otlpPending[id] == p
+			if current {
+				delete(h.otlpPending, id)
+			}
+			h.otlpMu.Unlock()
+			if current {
+				h.publishOTLP(id, p)
+			}
+		})
+		h.otlpPending[id] = p
+	}
+}
+
+// publishOTLP queues the stored conversation. Its jobs are failed if that
+// is not possible, as the exporter has already been answered.
+func (h *ConversationHandler) publishOTLP(id string, p *pendingOTLP) {
+	ctx, cancel := context.WithTimeout(context.Background(), otlpPublishTimeout)
+	defer cancel()
+
+	ctx, span := tracing.Tracer().Start(ctx, "queue publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithLinks(p.link),
+		trace.WithAttributes(
+			attribute.String("conversation.id", id),
+			attribute.String("queue.priority", string(p.priority)),
+		),
+	)
+	defer span.End()
+
+	conv, err := h.repo.GetByID(ctx, id)
+	if err == nil && conv == nil {
+		err = errors.New("conversation not found")
+	}
+	if err == nil {
+		err = h.queue.Publish(ctx, conv, p.priority)
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.Printf("OTLP: queue %s: %v", id, err)
+		if err := h.jobRepo.UpdateConversationStatus(ctx, id, domain.JobStatusFailed, "failed to queue conversation"); err != nil {
+			log.Printf("OTLP: update job status of %s: %v", id, err)
+		}
+	}
+}
@@ -9,12 +9,12 @@ import (
 )
 
 type ReviewHandler struct {
-	reviewRepo *storage.ReviewQueueRepo
-	evalRepo   *storage.EvaluationRepo
-	convRepo   *storage.ConversationRepo
+	reviewRepo storage.ReviewQueueRepository
+	evalRepo   storage.EvaluationRepository
+	convRepo   storage.ConversationRepository
 }
 
-func NewReviewHandler(reviewRepo *storage.ReviewQueueRepo, evalRepo *storage.EvaluationRepo, convRepo *storage.ConversationRepo) *ReviewHandler {
+func NewReviewHandler(reviewRepo storage.ReviewQueueRepository, evalRepo storage.EvaluationRepository, convRepo storage.ConversationRepository) *ReviewHandler {
 	return &ReviewHandler{
 		reviewRepo: reviewRepo,
 		evalRepo:   evalRepo,
@@ -9,28 +9,34 @@ import (
 	"github.com/saisaravanan/healing-eval/internal/domain"
 	"github.com/saisaravanan/healing-eval/internal/improvement"
 	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/prompt"
 	"github.com/saisaravanan/healing-eval/internal/storage"
+	"github.com/saisaravanan/healing-eval/internal/webhook"
 )
 
 type SuggestionHandler struct {
-	repo            *storage.SuggestionRepo
-	evalRepo        *storage.EvaluationRepo
+	repo            storage.SuggestionRepository
+	evalRepo        storage.EvaluationRepository
 	llmClient       *llm.Client
 	patternDetector *improvement.PatternDetector
 	suggester       *improvement.Suggester
+	webhooks        *webhook.Dispatcher
 }
 
 func NewSuggestionHandler(
-	repo *storage.SuggestionRepo,
-	evalRepo *storage.EvaluationRepo,
+	repo storage.SuggestionRepository,
+	evalRepo storage.EvaluationRepository,
 	llmClient *llm.Client,
+	prompts *prompt.Library,
+	webhooks *webhook.Dispatcher,
 ) *SuggestionHandler {
 	return &SuggestionHandler{
 		repo:            repo,
 		evalRepo:        evalRepo,
 		llmClient:       llmClient,
 		patternDetector: improvement.NewPatternDetector(),
-		suggester:       improvement.NewSuggester(llmClient),
+		suggester:       improvement.NewSuggester(llmClient, prompts),
+		webhooks:        webhooks,
 	}
 }
 
@@ -84,6 +90,10 @@ func (h *SuggestionHandler) Approve(c *gin.Context) {
 		return
 	}
 
+	if suggestion, err := h.repo.GetByID(c.Request.Context(), id); err == nil && suggestion != nil {
+		h.webhooks.Emit(c.Request.Context(), domain.WebhookEventSuggestionApproved, suggestion)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"status": "approved"})
 }
 
@@ -164,6 +174,7 @@ func (h *SuggestionHandler) Generate(c *gin.Context) {
 		DateFrom:        &dateFrom,
 		DateTo:          &dateTo,
 		MaxOverallScore: &req.MaxScore,
+		Statuses:        []domain.EvalStatus{domain.EvalStatusSuccess},
 		Limit:           500,
 	}
 
@@ -183,6 +194,9 @@ func (h *SuggestionHandler) Generate(c *gin.Context) {
 	// Detect patterns
 	patterns := h.patternDetector.DetectPatterns(c.Request.Context(), evalPtrs)
 	log.Printf("Detected %d failure patterns", len(patterns))
+	for _, pattern := range patterns {
+		h.webhooks.Emit(c.Request.Context(), domain.WebhookEventPatternDetected, pattern)
+	}
 
 	if len(patterns) == 0 {
 		c.JSON(http.StatusOK, gin.H{
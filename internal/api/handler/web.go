@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -19,13 +18,14 @@ import (
 
 type WebHandler struct {
 	templates  map[string]*template.Template
-	convRepo   *storage.ConversationRepo
-	evalRepo   *storage.EvaluationRepo
-	suggRepo   *storage.SuggestionRepo
-	reviewRepo *storage.ReviewQueueRepo
+	convRepo   storage.ConversationRepository
+	evalRepo   storage.EvaluationRepository
+	aggRepo    storage.AggregatedEvaluationRepository
+	suggRepo   storage.SuggestionRepository
+	reviewRepo storage.ReviewQueueRepository
 }
 
-func NewWebHandler(convRepo *storage.ConversationRepo, evalRepo *storage.EvaluationRepo, suggRepo *storage.SuggestionRepo, reviewRepo *storage.ReviewQueueRepo) *WebHandler {
+func NewWebHandler(convRepo storage.ConversationRepository, evalRepo storage.EvaluationRepository, aggRepo storage.AggregatedEvaluationRepository, suggRepo storage.SuggestionRepository, reviewRepo storage.ReviewQueueRepository) *WebHandler {
 	funcMap := template.FuncMap{
 		"scoreClass": func(score float64) string {
 			if score >= 0.8 {
@@ -189,6 +189,7 @@ func NewWebHandler(convRepo *storage.ConversationRepo, evalRepo *storage.Evaluat
 		templates:  templates,
 		convRepo:   convRepo,
 		evalRepo:   evalRepo,
+		aggRepo:    aggRepo,
 		suggRepo:   suggRepo,
 		reviewRepo: reviewRepo,
 	}
@@ -372,6 +373,7 @@ func (h *WebHandler) EvaluatorPerformance(c *gin.Context) {
 	for _, et := range evalTypes {
 		resp, err := h.evalRepo.Query(ctx, &domain.EvaluationsQueryRequest{
 			EvaluatorTypes: []domain.EvaluatorType{et},
+			Statuses:       []domain.EvalStatus{domain.EvalStatusSuccess},
 			Limit:          500,
 		})
 
@@ -445,6 +447,7 @@ func (h *WebHandler) EvaluatorAccuracy(c *gin.Context) {
 	for _, et := range evalTypes {
 		resp, err := h.evalRepo.Query(ctx, &domain.EvaluationsQueryRequest{
 			EvaluatorTypes: []domain.EvaluatorType{et},
+			Statuses:       []domain.EvalStatus{domain.EvalStatusSuccess},
 			Limit:          500,
 		})
 
@@ -509,10 +512,10 @@ func (h *WebHandler) StatConversations(c *gin.Context) {
 	convs, _ := h.convRepo.GetUnprocessed(ctx, 100)
 
 	count := len(convs)
-	resp, err := h.evalRepo.Query(ctx, &domain.EvaluationsQueryRequest{Limit: 1})
+	resp, err := h.evalRepo.Query(ctx, &domain.EvaluationsQueryRequest{Statuses: []domain.EvalStatus{domain.EvalStatusSuccess}, Limit: 1})
 	if err == nil && resp != nil {
 		distinctConvs := make(map[string]bool)
-		allResp, _ := h.evalRepo.Query(ctx, &domain.EvaluationsQueryRequest{Limit: 10000})
+		allResp, _ := h.evalRepo.Query(ctx, &domain.EvaluationsQueryRequest{Statuses: []domain.EvalStatus{domain.EvalStatusSuccess}, Limit: 10000})
 		if allResp != nil {
 			for _, e := range allResp.Evaluations {
 				distinctConvs[e.ConversationID] = true
@@ -526,7 +529,7 @@ func (h *WebHandler) StatConversations(c *gin.Context) {
 
 func (h *WebHandler) StatEvaluations(c *gin.Context) {
 	ctx := c.Request.Context()
-	resp, err := h.evalRepo.Query(ctx, &domain.EvaluationsQueryRequest{Limit: 1})
+	resp, err := h.evalRepo.Query(ctx, &domain.EvaluationsQueryRequest{Statuses: []domain.EvalStatus{domain.EvalStatusSuccess}, Limit: 1})
 	if err != nil || resp == nil {
 		c.String(http.StatusOK, "0")
 		return
@@ -546,7 +549,10 @@ func (h *WebHandler) StatSuggestions(c *gin.Context) {
 
 func (h *WebHandler) StatAvgScore(c *gin.Context) {
 	ctx := c.Request.Context()
-	resp, err := h.evalRepo.Query(ctx, &domain.EvaluationsQueryRequest{Limit: 500})
+	resp, err := h.evalRepo.Query(ctx, &domain.EvaluationsQueryRequest{
+		Statuses: []domain.EvalStatus{domain.EvalStatusSuccess},
+		Limit:    500,
+	})
 	if err != nil || resp == nil || len(resp.Evaluations) == 0 {
 		c.String(http.StatusOK, "-")
 		return
@@ -570,69 +576,26 @@ func (h *WebHandler) ConversationsList(c *gin.Context) {
 	}
 	perPage := 10
 
-	resp, err := h.evalRepo.Query(ctx, &domain.EvaluationsQueryRequest{
-		Limit:     1000,
-		SortBy:    "created_at",
-		SortOrder: "desc",
-	})
-
-	convMap := make(map[string]*ConversationSummary)
-	convHasIssue := make(map[string]bool)
-
-	if err == nil && resp != nil {
-		for _, eval := range resp.Evaluations {
-			if _, exists := convMap[eval.ConversationID]; !exists {
-				convMap[eval.ConversationID] = &ConversationSummary{
-					ID:        eval.ConversationID,
-					CreatedAt: eval.CreatedAt,
-				}
-			}
-			summary := convMap[eval.ConversationID]
-			summary.EvalCount++
-			summary.TotalScore += eval.Scores.Overall
-			summary.IssueCount += len(eval.Issues)
-
-			if issueFilter != "" {
-				for _, issue := range eval.Issues {
-					if issue.Type == issueFilter {
-						convHasIssue[eval.ConversationID] = true
-					}
-				}
-			}
-		}
-	}
-
-	var conversations []ConversationSummary
-	for _, s := range convMap {
-		if issueFilter != "" && !convHasIssue[s.ID] {
-			continue
-		}
-		s.AvgScore = s.TotalScore / float64(s.EvalCount)
-		conversations = append(conversations, *s)
+	aggs, totalConvs, err := h.aggRepo.List(ctx, issueFilter, perPage, (page-1)*perPage)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load conversations: %v", err)
+		return
 	}
 
-	sort.Slice(conversations, func(i, j int) bool {
-		return conversations[i].CreatedAt.After(conversations[j].CreatedAt)
-	})
-
-	totalConvs := len(conversations)
 	totalPages := (totalConvs + perPage - 1) / perPage
 	if totalPages == 0 {
 		totalPages = 1
 	}
 
-	start := (page - 1) * perPage
-	end := start + perPage
-	if start > totalConvs {
-		start = totalConvs
-	}
-	if end > totalConvs {
-		end = totalConvs
-	}
-
 	paginatedConvs := []ConversationSummary{}
-	if start < totalConvs {
-		paginatedConvs = conversations[start:end]
+	for _, agg := range aggs {
+		paginatedConvs = append(paginatedConvs, ConversationSummary{
+			ID:         agg.ConversationID,
+			EvalCount:  agg.SuccessfulCount,
+			AvgScore:   agg.Scores.Overall,
+			IssueCount: len(agg.Issues),
+			CreatedAt:  agg.CreatedAt,
+		})
 	}
 
 	data := struct {
@@ -656,7 +619,6 @@ type ConversationSummary struct {
 	ID         string
 	EvalCount  int
 	AvgScore   float64
-	TotalScore float64
 	IssueCount int
 	CreatedAt  time.Time
 }
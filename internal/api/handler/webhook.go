@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/storage"
+	"github.com/saisaravanan/healing-eval/internal/webhook"
+)
+
+// WebhookHandler manages webhook subscriptions and exposes their delivery
+// log.
+type WebhookHandler struct {
+	repo         storage.WebhookRepository
+	allowPrivate bool
+}
+
+// NewWebhookHandler builds the handler; allowPrivate permits URLs naming
+// loopback, private or link-local hosts (WEBHOOK_ALLOW_PRIVATE).
+func NewWebhookHandler(repo storage.WebhookRepository, allowPrivate bool) *WebhookHandler {
+	return &WebhookHandler{repo: repo, allowPrivate: allowPrivate}
+}
+
+// POST /api/v1/webhooks
+//
+// The signing secret is only returned here; one is generated when the
+// request leaves it empty.
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req domain.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := webhook.ValidateURL(req.URL, h.allowPrivate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(req.Events) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "events is required", "valid_events": domain.WebhookEvents})
+		return
+	}
+	for _, e := range req.Events {
+		if !e.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown event: " + string(e), "valid_events": domain.WebhookEvents})
+			return
+		}
+	}
+
+	var err error
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = webhook.NewSecret(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate secret"})
+			return
+		}
+	}
+
+	w := &domain.Webhook{
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      req.Events,
+		Description: req.Description,
+		Active:      true,
+	}
+	if err := h.repo.Create(c.Request.Context(), w); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, w)
+}
+
+// GET /api/v1/webhooks
+func (h *WebhookHandler) List(c *gin.Context) {
+	webhooks, err := h.repo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list webhooks"})
+		return
+	}
+
+	if webhooks == nil {
+		webhooks = []*domain.Webhook{}
+	}
+	for _, w := range webhooks {
+		w.Secret = ""
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhooks": webhooks,
+		"count":    len(webhooks),
+	})
+}
+
+// GET /api/v1/webhooks/:id
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	w, err := h.repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve webhook"})
+		return
+	}
+
+	if w == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+
+	w.Secret = ""
+	c.JSON(http.StatusOK, w)
+}
+
+// PATCH /api/v1/webhooks/:id
+//
+// Pauses or resumes deliveries: {"active": false}.
+func (h *WebhookHandler) Update(c *gin.Context) {
+	var req struct {
+		Active *bool `json:"active"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Active == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "active is required"})
+		return
+	}
+
+	if err := h.repo.SetActive(c.Request.Context(), c.Param("id"), *req.Active); err != nil {
+		if err.Error() == "webhook not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "active": *req.Active})
+}
+
+// DELETE /api/v1/webhooks/:id
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	if err := h.repo.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		if err.Error() == "webhook not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deleted": c.Param("id")})
+}
+
+// GET /api/v1/webhooks/:id/deliveries
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	limit := 50
+	offset := 0
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(c.Query("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	ctx := c.Request.Context()
+	w, err := h.repo.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve webhook"})
+		return
+	}
+	if w == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+
+	deliveries, err := h.repo.ListDeliveries(ctx, w.ID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list deliveries"})
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []*domain.WebhookDelivery{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+		"limit":      limit,
+		"offset":     offset,
+	})
+}
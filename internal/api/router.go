@@ -1,53 +1,84 @@
 package api
 
 import (
+	"crypto/subtle"
 	"log"
+	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/saisaravanan/healing-eval/internal/api/handler"
 	"github.com/saisaravanan/healing-eval/internal/config"
 	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/metrics"
+	"github.com/saisaravanan/healing-eval/internal/prompt"
 	"github.com/saisaravanan/healing-eval/internal/queue"
 	"github.com/saisaravanan/healing-eval/internal/storage"
+	"github.com/saisaravanan/healing-eval/internal/tracing"
+	"github.com/saisaravanan/healing-eval/internal/webhook"
 )
 
 type Router struct {
-	engine *gin.Engine
+	engine        *gin.Engine
+	conversations *handler.ConversationHandler
 }
 
-func NewRouter(db *storage.PostgresDB, q *queue.RedisQueue) *Router {
+func NewRouter(repos *storage.Repositories, q queue.Queue, webhooks *webhook.Dispatcher, cache llm.Cache) *Router {
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
 	engine.Use(gin.Recovery())
 	engine.Use(gin.Logger())
-
-	convRepo := storage.NewConversationRepo(db)
-	evalRepo := storage.NewEvaluationRepo(db)
-	suggRepo := storage.NewSuggestionRepo(db)
-	reviewQueueRepo := storage.NewReviewQueueRepo(db)
+	engine.Use(metrics.Middleware())
+	engine.Use(tracing.Middleware())
+
+	convRepo := repos.Conversations
+	evalRepo := repos.Evaluations
+	aggRepo := repos.Aggregated
+	suggRepo := repos.Suggestions
+	reviewQueueRepo := repos.Reviews
+	annotationRepo := repos.Annotations
+	accuracyRepo := repos.Accuracy
+	jobRepo := repos.Jobs
+	webhookRepo := repos.Webhooks
 
 	// Create LLM client for suggestion generation
 	cfg, err := config.Load()
 	var llmClient *llm.Client
+	prompts := prompt.Default()
 	if err != nil {
 		log.Printf("Warning: Failed to load config for LLM client: %v", err)
 	} else {
 		llmClient, err = llm.NewClient(&cfg.LLM)
 		if err != nil {
 			log.Printf("Warning: Failed to create LLM client: %v", err)
+		} else {
+			llmClient.UseCache(cache)
+		}
+		if lib, err := prompt.NewLibrary(cfg.LLM.PromptDir); err != nil {
+			log.Printf("Warning: Failed to load prompt overrides, using embedded templates: %v", err)
+		} else {
+			prompts = lib
 		}
 	}
 
-	convHandler := handler.NewConversationHandler(convRepo, q)
-	evalHandler := handler.NewEvaluationHandler(evalRepo)
-	suggHandler := handler.NewSuggestionHandler(suggRepo, evalRepo, llmClient)
+	convHandler := handler.NewConversationHandler(convRepo, jobRepo, q)
+	if cfg != nil {
+		convHandler.DebounceOTLP(cfg.Server.OTLPDebounce)
+	}
+	evalHandler := handler.NewEvaluationHandler(evalRepo, aggRepo)
+	suggHandler := handler.NewSuggestionHandler(suggRepo, evalRepo, llmClient, prompts, webhooks)
 	reviewHandler := handler.NewReviewHandler(reviewQueueRepo, evalRepo, convRepo)
-	metricsHandler := handler.NewMetricsHandler()
-	webHandler := handler.NewWebHandler(convRepo, evalRepo, suggRepo, reviewQueueRepo)
+	annotationHandler := handler.NewAnnotationHandler(annotationRepo, convRepo)
+	metricsHandler := handler.NewMetricsHandler(evalRepo, annotationRepo, accuracyRepo)
+	jobHandler := handler.NewJobHandler(jobRepo)
+	webhookHandler := handler.NewWebhookHandler(webhookRepo, cfg != nil && cfg.Webhook.AllowPrivate)
+	adminHandler := handler.NewAdminHandler(q)
+	webHandler := handler.NewWebHandler(convRepo, evalRepo, aggRepo, suggRepo, reviewQueueRepo)
 
 	engine.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
+	engine.GET(metrics.Path, gin.WrapH(metrics.Handler()))
 
 	engine.GET("/", webHandler.Dashboard)
 	engine.GET("/conversations", webHandler.Conversations)
@@ -77,7 +108,35 @@ func NewRouter(db *storage.PostgresDB, q *queue.RedisQueue) *Router {
 		{
 			conversations.POST("", convHandler.Ingest)
 			conversations.GET("/:id", convHandler.GetByID)
+			conversations.GET("/:id/evaluation", evalHandler.GetAggregated)
 			conversations.POST("/:id/feedback", convHandler.UpdateFeedback)
+			conversations.POST("/:id/annotations", annotationHandler.Create)
+			conversations.GET("/:id/annotations", annotationHandler.GetByConversationID)
+		}
+
+		v1.POST("/otlp/v1/traces", convHandler.IngestOTLP)
+
+		jobs := v1.Group("/jobs")
+		{
+			jobs.GET("/:id", jobHandler.GetByID)
+			jobs.GET("/:id/events", jobHandler.Events)
+		}
+
+		hooks := v1.Group("/webhooks")
+		{
+			hooks.GET("", webhookHandler.List)
+			hooks.POST("", webhookHandler.Create)
+			hooks.GET("/:id", webhookHandler.GetByID)
+			hooks.PATCH("/:id", webhookHandler.Update)
+			hooks.DELETE("/:id", webhookHandler.Delete)
+			hooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+		}
+
+		annotators := v1.Group("/annotators")
+		{
+			annotators.GET("", annotationHandler.ListAnnotators)
+			annotators.POST("", annotationHandler.CreateAnnotator)
+			annotators.GET("/:id", annotationHandler.GetAnnotator)
 		}
 
 		evaluations := v1.Group("/evaluations")
@@ -107,14 +166,52 @@ func NewRouter(db *storage.PostgresDB, q *queue.RedisQueue) *Router {
 		metrics := v1.Group("/metrics")
 		{
 			metrics.GET("/evaluators", metricsHandler.GetEvaluators)
+			metrics.GET("/evaluators/history", metricsHandler.GetEvaluatorHistory)
+			metrics.GET("/failures", metricsHandler.GetFailureRates)
 			metrics.GET("/calibration", metricsHandler.GetCalibration)
 			metrics.GET("/blind-spots", metricsHandler.GetBlindSpots)
 		}
+
+		adminToken := ""
+		if cfg != nil {
+			adminToken = cfg.Server.AdminToken
+		}
+		admin := v1.Group("/admin", requireToken(adminToken))
+		{
+			admin.GET("/dead-letters", adminHandler.ListDeadLetters)
+			admin.DELETE("/dead-letters", adminHandler.PurgeDeadLetters)
+			admin.GET("/dead-letters/:id", adminHandler.GetDeadLetter)
+			admin.POST("/dead-letters/:id/requeue", adminHandler.RequeueDeadLetter)
+			admin.DELETE("/dead-letters/:id", adminHandler.DeleteDeadLetter)
+		}
 	}
 
-	return &Router{engine: engine}
+	return &Router{engine: engine, conversations: convHandler}
+}
+
+// requireToken rejects requests without "Authorization: Bearer <token>".
+// With no token configured every request is rejected.
+func requireToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin API disabled: set ADMIN_TOKEN"})
+			return
+		}
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or missing admin token"})
+			return
+		}
+		c.Next()
+	}
 }
 
 func (r *Router) Engine() *gin.Engine {
 	return r.engine
 }
+
+// Close queues conversations still held back by the OTLP debounce. Call it
+// once the server has stopped taking requests.
+func (r *Router) Close() {
+	r.conversations.FlushOTLP()
+}
@@ -18,6 +18,8 @@ type Config struct {
 	Redis    RedisConfig
 	LLM      LLMConfig
 	Worker   WorkerConfig
+	Webhook  WebhookConfig
+	Tracing  TracingConfig
 }
 
 // ServerConfig holds HTTP server configuration.
@@ -26,6 +28,14 @@ type ServerConfig struct {
 	Port         int
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+
+	// AdminToken must be sent as a bearer token to call /api/v1/admin;
+	// empty disables the admin API.
+	AdminToken string
+
+	// OTLPDebounce is how long a conversation ingested from traces waits
+	// for further exports before it is queued; 0 queues every export.
+	OTLPDebounce time.Duration
 }
 
 // DatabaseConfig holds PostgreSQL configuration.
@@ -59,8 +69,43 @@ type LLMConfig struct {
 	OpenRouterAPIKey    string
 	OpenRouterModel     string
 	OpenRouterReasoning bool
-	DefaultProvider     string // "openai", "anthropic", "ollama", or "openrouter"
+	DefaultProvider     string // "openai", "anthropic", "ollama", "openrouter", or "fake"
+	FallbackProviders   string // comma-separated provider[:model] chain tried after the default
 	Timeout             time.Duration
+	PromptDir           string // overrides for the embedded prompt templates
+
+	// Circuit breakers stop calling a provider after BreakerFailureThreshold
+	// consecutive failures (0 disables) and probe it again after
+	// BreakerOpenDuration.
+	BreakerFailureThreshold int
+	BreakerOpenDuration     time.Duration
+
+	// Transient provider failures (rate limits, timeouts, 5xx) are retried
+	// up to MaxRetries times with jittered exponential backoff starting at
+	// RetryBaseDelay and capped at RetryMaxDelay.
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// Response cache: "memory" (an LRU of CacheSize entries), "redis", or
+	// empty for none. Entries expire after CacheTTL.
+	Cache     string
+	CacheSize int
+	CacheTTL  time.Duration
+
+	// Per-provider requests and tokens per minute and in-flight caps, as
+	// "provider:rpm=N,tpm=N,concurrency=N;...".
+	RateLimits string
+
+	// Fake provider, used when DefaultProvider is "fake".
+	FakeMode           string // "replay" or "record"
+	FakeFixtureDir     string
+	FakeRecordProvider string // provider recorded from in record mode
+	FakeStrict         bool   // fail replay misses instead of returning a default response
+	FakeLatency        time.Duration
+	FakeRateLimitRate  float64
+	FakeMalformedRate  float64
+	FakeSeed           int
 }
 
 // WorkerConfig holds worker configuration.
@@ -70,6 +115,44 @@ type WorkerConfig struct {
 	StreamName    string
 	ConsumerGroup string
 	ConsumerName  string
+
+	// Pending messages idle for ClaimMinIdle are reclaimed every
+	// ClaimInterval; after MaxDeliveries they go to the dead-letter stream.
+	ClaimMinIdle  time.Duration
+	ClaimInterval time.Duration
+	MaxDeliveries int
+
+	// PriorityWeights sets how reads are shared between the high, normal and
+	// bulk lanes, e.g. "high=8,normal=4,bulk=1"; empty uses the defaults.
+	PriorityWeights string
+
+	MetricsAddr string // listen address for the worker's metrics endpoint; empty disables
+
+	AccuracyRollupEnabled  bool
+	AccuracyRollupInterval time.Duration
+
+	PipelineFile string // evaluator pipeline YAML/JSON; empty uses the built-in defaults
+
+	RetryMaxAttempts int // retries per failed evaluator; 0 disables
+	RetryBaseDelay   time.Duration
+	RetryMaxDelay    time.Duration
+}
+
+// WebhookConfig holds webhook delivery configuration.
+type WebhookConfig struct {
+	Timeout        time.Duration // per delivery attempt
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	Concurrency    int  // deliveries in flight per process
+	QueueSize      int  // deliveries waiting for a worker; more are dropped
+	AllowPrivate   bool // allow loopback, private and link-local targets
+}
+
+// TracingConfig holds OpenTelemetry configuration. The exporter itself
+// reads the standard OTEL_EXPORTER_OTLP_* variables.
+type TracingConfig struct {
+	Endpoint string // OTLP endpoint; empty disables span export
 }
 
 // Load loads configuration from environment variables.
@@ -83,6 +166,8 @@ func Load() (*Config, error) {
 			Port:         getEnvAsInt("SERVER_PORT", getEnvAsInt("PORT", 8080)),
 			ReadTimeout:  getEnvAsDuration("SERVER_READ_TIMEOUT", 30*time.Second),
 			WriteTimeout: getEnvAsDuration("SERVER_WRITE_TIMEOUT", 30*time.Second),
+			AdminToken:   getEnv("ADMIN_TOKEN", ""),
+			OTLPDebounce: getEnvAsDuration("OTLP_DEBOUNCE", 10*time.Second),
 		},
 		Database: DatabaseConfig{
 			URL:             getEnv("DATABASE_URL", ""),
@@ -105,7 +190,28 @@ func Load() (*Config, error) {
 			OpenRouterModel:     getEnv("OPENROUTER_MODEL", "nvidia/nemotron-3-nano-30b-a3b:free"),
 			OpenRouterReasoning: getEnvAsBool("OPENROUTER_ENABLE_REASONING", false),
 			DefaultProvider:     getEnv("LLM_DEFAULT_PROVIDER", "ollama"),
+			FallbackProviders:   getEnv("LLM_FALLBACK_PROVIDERS", ""),
 			Timeout:             getEnvAsDuration("LLM_TIMEOUT", 120*time.Second),
+			PromptDir:           getEnv("PROMPT_TEMPLATE_DIR", ""),
+
+			BreakerFailureThreshold: getEnvAsInt("LLM_BREAKER_FAILURE_THRESHOLD", 5),
+			BreakerOpenDuration:     getEnvAsDuration("LLM_BREAKER_OPEN_DURATION", 30*time.Second),
+			RateLimits:              getEnv("LLM_RATE_LIMITS", ""),
+			MaxRetries:              getEnvAsInt("LLM_MAX_RETRIES", 3),
+			RetryBaseDelay:          getEnvAsDuration("LLM_RETRY_BASE_DELAY", time.Second),
+			RetryMaxDelay:           getEnvAsDuration("LLM_RETRY_MAX_DELAY", 30*time.Second),
+			Cache:                   getEnv("LLM_CACHE", ""),
+			CacheSize:               getEnvAsInt("LLM_CACHE_SIZE", 1000),
+			CacheTTL:                getEnvAsDuration("LLM_CACHE_TTL", 24*time.Hour),
+
+			FakeMode:           getEnv("LLM_FAKE_MODE", "replay"),
+			FakeFixtureDir:     getEnv("LLM_FAKE_FIXTURE_DIR", ""),
+			FakeRecordProvider: getEnv("LLM_FAKE_RECORD_PROVIDER", ""),
+			FakeStrict:         getEnvAsBool("LLM_FAKE_STRICT", false),
+			FakeLatency:        getEnvAsDuration("LLM_FAKE_LATENCY", 0),
+			FakeRateLimitRate:  getEnvAsFloat("LLM_FAKE_RATE_LIMIT_RATE", 0),
+			FakeMalformedRate:  getEnvAsFloat("LLM_FAKE_MALFORMED_RATE", 0),
+			FakeSeed:           getEnvAsInt("LLM_FAKE_SEED", 1),
 		},
 		Worker: WorkerConfig{
 			Concurrency:   getEnvAsInt("WORKER_CONCURRENCY", 10),
@@ -113,6 +219,35 @@ func Load() (*Config, error) {
 			StreamName:    getEnv("WORKER_STREAM_NAME", "conversations"),
 			ConsumerGroup: getEnv("WORKER_CONSUMER_GROUP", "eval-workers"),
 			ConsumerName:  getEnv("WORKER_CONSUMER_NAME", "worker-1"),
+
+			ClaimMinIdle:  getEnvAsDuration("WORKER_CLAIM_MIN_IDLE", 5*time.Minute),
+			ClaimInterval: getEnvAsDuration("WORKER_CLAIM_INTERVAL", 30*time.Second),
+			MaxDeliveries: getEnvAsInt("WORKER_MAX_DELIVERIES", 5),
+
+			PriorityWeights: getEnv("WORKER_PRIORITY_WEIGHTS", ""),
+
+			MetricsAddr: getEnv("WORKER_METRICS_ADDR", ":9091"),
+
+			AccuracyRollupEnabled:  getEnvAsBool("ACCURACY_ROLLUP_ENABLED", true),
+			AccuracyRollupInterval: getEnvAsDuration("ACCURACY_ROLLUP_INTERVAL", time.Hour),
+
+			PipelineFile: getEnv("EVALUATOR_PIPELINE_FILE", ""),
+
+			RetryMaxAttempts: getEnvAsInt("EVAL_RETRY_MAX_ATTEMPTS", 3),
+			RetryBaseDelay:   getEnvAsDuration("EVAL_RETRY_BASE_DELAY", 30*time.Second),
+			RetryMaxDelay:    getEnvAsDuration("EVAL_RETRY_MAX_DELAY", 10*time.Minute),
+		},
+		Webhook: WebhookConfig{
+			Timeout:        getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:    getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 5),
+			RetryBaseDelay: getEnvAsDuration("WEBHOOK_RETRY_BASE_DELAY", 5*time.Second),
+			RetryMaxDelay:  getEnvAsDuration("WEBHOOK_RETRY_MAX_DELAY", 5*time.Minute),
+			Concurrency:    getEnvAsInt("WEBHOOK_CONCURRENCY", 10),
+			QueueSize:      getEnvAsInt("WEBHOOK_QUEUE_SIZE", 1000),
+			AllowPrivate:   getEnvAsBool("WEBHOOK_ALLOW_PRIVATE", false),
+		},
+		Tracing: TracingConfig{
+			Endpoint: getEnv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "")),
 		},
 	}
 
@@ -217,6 +352,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -31,15 +31,21 @@ type Evaluation struct {
 	Status           EvalStatus      `json:"status"`
 	Scores           Scores          `json:"scores"`
 	ModelName        string          `json:"model_name,omitempty"`
+	Provider         string          `json:"provider,omitempty"`
 	PromptTokens     int             `json:"prompt_tokens"`
 	CompletionTokens int             `json:"completion_tokens"`
 	TotalTokens      int             `json:"total_tokens"`
 	EstimatedCostUSD float64         `json:"estimated_cost_usd"`
+	Cached           bool            `json:"cached,omitempty"` // LLM response served from cache, at no cost
 	ErrorMessage     string          `json:"error_message,omitempty"`
 	Issues           []Issue         `json:"issues,omitempty"`
 	Confidence       float64         `json:"confidence"`
 	RawOutput        json.RawMessage `json:"raw_output,omitempty"`
 	LatencyMs        int             `json:"latency_ms"`
+	PromptTemplate   string          `json:"prompt_template,omitempty"`
+	PromptHash       string          `json:"prompt_hash,omitempty"`
+	RetryCount       int             `json:"retry_count"`
+	RunID            string          `json:"-"` // queue message that produced it; empty for retries
 	CreatedAt        time.Time       `json:"created_at"`
 }
 
@@ -53,6 +59,9 @@ type Scores struct {
 	ParameterAccuracy float64 `json:"parameter_accuracy,omitempty"`
 	Coherence         float64 `json:"coherence,omitempty"`
 	Consistency       float64 `json:"consistency,omitempty"`
+
+	// Dimensions holds per-criterion scores from rubric evaluators.
+	Dimensions map[string]float64 `json:"dimensions,omitempty"`
 }
 
 type Issue struct {
@@ -82,12 +91,16 @@ type AggregatedEvaluation struct {
 	Issues           []Issue              `json:"issues_detected"`
 	Evaluations      []Evaluation         `json:"evaluations"`
 	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
 }
 
 type EvaluatorFailure struct {
 	EvaluatorType EvaluatorType `json:"evaluator_type"`
+	Status        EvalStatus    `json:"status"`
+	Provider      string        `json:"provider,omitempty"`
 	ErrorMessage  string        `json:"error_message"`
 	Retryable     bool          `json:"retryable"`
+	LatencyMs     int           `json:"latency_ms"`
 }
 
 type TokenUsage struct {
@@ -96,6 +109,7 @@ type TokenUsage struct {
 	TotalTokens      int     `json:"total_tokens"`
 	EstimatedCost    float64 `json:"estimated_cost_usd"`
 	ModelName        string  `json:"model_name"`
+	Cached           bool    `json:"cached,omitempty"`
 }
 
 type AggregatedTokenUsage struct {
@@ -123,6 +137,7 @@ type EvaluationsQueryRequest struct {
 	MaxOverallScore *float64        `json:"max_overall_score,omitempty"`
 	HasIssues       *bool           `json:"has_issues,omitempty"`
 	IssueTypes      []string        `json:"issue_types,omitempty"`
+	Statuses        []EvalStatus    `json:"statuses,omitempty"`
 	Limit           int             `json:"limit,omitempty"`
 	Offset          int             `json:"offset,omitempty"`
 	SortBy          string          `json:"sort_by,omitempty"`
@@ -0,0 +1,91 @@
+package domain
+
+import "time"
+
+// JobStatus is the state of an ingestion job or of one conversation in it.
+type JobStatus string
+
+const (
+	JobStatusQueued     JobStatus = "queued"
+	JobStatusEvaluating JobStatus = "evaluating"
+	JobStatusDone       JobStatus = "done"
+	JobStatusFailed     JobStatus = "failed"
+	JobStatusRetrying   JobStatus = "retrying" // failed evaluators have retries scheduled
+	JobStatusPartial    JobStatus = "partial"  // some evaluators failed, or some conversations did
+)
+
+// Terminal reports whether evaluation has finished. A conversation whose
+// failed evaluators are being retried is retrying, not partial, until the
+// retries succeed or run out.
+func (s JobStatus) Terminal() bool {
+	return s == JobStatusDone || s == JobStatusFailed || s == JobStatusPartial
+}
+
+// JobStatusFor maps an aggregated evaluation's status to the conversation's
+// job status.
+func JobStatusFor(status EvaluationStatus) JobStatus {
+	switch status {
+	case AggregatedStatusSuccess:
+		return JobStatusDone
+	case AggregatedStatusPartial:
+		return JobStatusPartial
+	default:
+		return JobStatusFailed
+	}
+}
+
+// Job tracks the conversations accepted by one ingest call through
+// evaluation.
+type Job struct {
+	ID            string            `json:"id"`
+	Status        JobStatus         `json:"status"`
+	Total         int               `json:"total"`
+	Counts        map[JobStatus]int `json:"counts"`
+	Conversations []JobConversation `json:"conversations"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+type JobConversation struct {
+	ConversationID string    `json:"conversation_id"`
+	Status         JobStatus `json:"status"`
+	Error          string    `json:"error,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Summarize derives Total, Counts, Status and UpdatedAt from the
+// conversations. The job is queued until one starts, evaluating while any
+// is queued or evaluating, retrying while any is retrying, then done or
+// failed if they all agree and partial otherwise.
+func (j *Job) Summarize() {
+	j.Total = len(j.Conversations)
+	j.Counts = map[JobStatus]int{
+		JobStatusQueued:     0,
+		JobStatusEvaluating: 0,
+		JobStatusDone:       0,
+		JobStatusFailed:     0,
+		JobStatusRetrying:   0,
+		JobStatusPartial:    0,
+	}
+	for _, c := range j.Conversations {
+		j.Counts[c.Status]++
+		if c.UpdatedAt.After(j.UpdatedAt) {
+			j.UpdatedAt = c.UpdatedAt
+		}
+	}
+
+	switch {
+	case j.Counts[JobStatusQueued] == j.Total:
+		j.Status = JobStatusQueued
+	case j.Counts[JobStatusQueued]+j.Counts[JobStatusEvaluating] > 0:
+		j.Status = JobStatusEvaluating
+	case j.Counts[JobStatusRetrying] > 0:
+		j.Status = JobStatusRetrying
+	case j.Counts[JobStatusDone] == j.Total:
+		j.Status = JobStatusDone
+	case j.Counts[JobStatusFailed] == j.Total:
+		j.Status = JobStatusFailed
+	default:
+		j.Status = JobStatusPartial
+	}
+}
@@ -14,6 +14,27 @@ type EvaluatorAccuracy struct {
 	CreatedAt        time.Time     `json:"created_at"`
 }
 
+type EvaluatorAccuracyHistoryResponse struct {
+	Period DateRange                             `json:"period"`
+	Series map[EvaluatorType][]EvaluatorAccuracy `json:"series"`
+}
+
+// EvaluatorFailureRate counts evaluator runs for one evaluator and provider,
+// broken down by failure status.
+type EvaluatorFailureRate struct {
+	EvaluatorType EvaluatorType      `json:"evaluator_type"`
+	Provider      string             `json:"provider,omitempty"`
+	Total         int                `json:"total"`
+	Failed        int                `json:"failed"`
+	FailureRate   float64            `json:"failure_rate"`
+	ByStatus      map[EvalStatus]int `json:"by_status"`
+}
+
+type EvaluatorFailureRatesResponse struct {
+	Period DateRange              `json:"period"`
+	Rates  []EvaluatorFailureRate `json:"rates"`
+}
+
 type CalibrationMetrics struct {
 	EvaluatorType       EvaluatorType        `json:"evaluator_type"`
 	Period              DateRange            `json:"period"`
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type WebhookEvent string
+
+const (
+	WebhookEventEvaluationCompleted WebhookEvent = "evaluation.completed"
+	WebhookEventReviewRouted        WebhookEvent = "review.routed"
+	WebhookEventPatternDetected     WebhookEvent = "pattern.detected"
+	WebhookEventSuggestionApproved  WebhookEvent = "suggestion.approved"
+)
+
+// WebhookEvents lists every event a webhook can subscribe to.
+var WebhookEvents = []WebhookEvent{
+	WebhookEventEvaluationCompleted,
+	WebhookEventReviewRouted,
+	WebhookEventPatternDetected,
+	WebhookEventSuggestionApproved,
+}
+
+func (e WebhookEvent) Valid() bool {
+	for _, known := range WebhookEvents {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook is a subscription: events are POSTed to URL, signed with Secret.
+type Webhook struct {
+	ID          string         `json:"id"`
+	URL         string         `json:"url"`
+	Secret      string         `json:"secret,omitempty"` // only returned on creation
+	Events      []WebhookEvent `json:"events"`
+	Description string         `json:"description,omitempty"`
+	Active      bool           `json:"active"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// Subscribes reports whether the webhook receives event.
+func (w *Webhook) Subscribes(event WebhookEvent) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateWebhookRequest struct {
+	URL         string         `json:"url"`
+	Events      []WebhookEvent `json:"events"`
+	Secret      string         `json:"secret,omitempty"` // generated when empty
+	Description string         `json:"description,omitempty"`
+}
+
+// WebhookPayload is the body POSTed for an event.
+type WebhookPayload struct {
+	ID        string          `json:"id"` // same across retries, for deduplication
+	Event     WebhookEvent    `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookDelivery logs one delivery attempt.
+type WebhookDelivery struct {
+	ID         string          `json:"id"`
+	WebhookID  string          `json:"webhook_id"`
+	EventID    string          `json:"event_id"`
+	Event      WebhookEvent    `json:"event"`
+	Attempt    int             `json:"attempt"`
+	Success    bool            `json:"success"`
+	StatusCode int             `json:"status_code,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	DurationMs int64           `json:"duration_ms"`
+	Payload    json.RawMessage `json:"payload,omitempty"` // first attempt only
+	CreatedAt  time.Time       `json:"created_at"`
+}
@@ -10,9 +10,16 @@ import (
 	"github.com/google/uuid"
 	"github.com/saisaravanan/healing-eval/internal/domain"
 	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/prompt"
+)
+
+const (
+	coherenceTemplate        = "coherence.v1"
+	coherenceSummaryTemplate = "coherence_summary.v1"
 )
 
 type CoherenceEvaluator struct {
+	llmOptions
 	client     *llm.Client
 	weight     float64
 	windowSize int
@@ -58,20 +65,22 @@ func (e *CoherenceEvaluator) Evaluate(ctx context.Context, conv *domain.Conversa
 		}, nil
 	}
 
-	prompt := e.buildPrompt(conv)
+	rendered, err := e.buildPrompt(conv)
+	if err != nil {
+		return nil, fmt.Errorf("build prompt: %w", err)
+	}
 
-	resp, err := e.client.Complete(ctx, &llm.CompletionRequest{
+	resp, err := e.completeJSON(ctx, e.client, &llm.CompletionRequest{
 		Messages: []llm.Message{
 			{Role: "system", Content: "You are an expert at evaluating conversation coherence and consistency. Always respond with valid JSON."},
-			{Role: "user", Content: prompt},
+			{Role: "user", Content: rendered.Text},
 		},
 		MaxTokens:   1024,
 		Temperature: 0.1,
 		JSONMode:    true,
-	})
-
+	}, coherenceSchema)
 	if err != nil {
-		return nil, fmt.Errorf("llm completion: %w", err)
+		return nil, err
 	}
 
 	result, err := e.parseResponse(resp.Content)
@@ -80,7 +89,7 @@ func (e *CoherenceEvaluator) Evaluate(ctx context.Context, conv *domain.Conversa
 	}
 
 	// Calculate cost
-	cost := CalculateCost(resp.ModelName, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	cost := responseCost(resp)
 
 	return &domain.Evaluation{
 		ID:               uuid.New().String(),
@@ -88,68 +97,41 @@ func (e *CoherenceEvaluator) Evaluate(ctx context.Context, conv *domain.Conversa
 		EvaluatorType:    domain.EvaluatorTypeCoherence,
 		Status:           domain.EvalStatusSuccess,
 		ModelName:        resp.ModelName,
+		Provider:         resp.Provider,
 		PromptTokens:     resp.Usage.PromptTokens,
 		CompletionTokens: resp.Usage.CompletionTokens,
 		TotalTokens:      resp.Usage.TotalTokens,
 		EstimatedCostUSD: cost,
+		Cached:           resp.Cached,
 		Scores: domain.Scores{
 			Overall:     result.Overall,
 			Coherence:   result.Coherence,
 			Consistency: result.Consistency,
 		},
-		Issues:     result.Issues,
-		Confidence: result.Confidence,
-		RawOutput:  json.RawMessage(resp.Content),
-		LatencyMs:  int(time.Since(start).Milliseconds()),
-		CreatedAt:  time.Now(),
+		Issues:         result.Issues,
+		Confidence:     result.Confidence,
+		RawOutput:      json.RawMessage(resp.Content),
+		LatencyMs:      int(time.Since(start).Milliseconds()),
+		PromptTemplate: rendered.Name,
+		PromptHash:     rendered.Hash,
+		CreatedAt:      time.Now(),
 	}, nil
 }
 
-func (e *CoherenceEvaluator) buildPrompt(conv *domain.Conversation) string {
-	var sb strings.Builder
-
-	sb.WriteString("Evaluate coherence and consistency in this multi-turn conversation:\n\n")
-
+func (e *CoherenceEvaluator) buildPrompt(conv *domain.Conversation) (*prompt.Rendered, error) {
 	// Sanitize conversation to prevent prompt injection and handle large messages
 	sanitizer := NewMessageSanitizer()
 	turns := sanitizer.PrepareConversationForEval(conv.Turns)
 
+	var data conversationPromptData
+
 	if len(turns) > e.windowSize*2 {
-		sb.WriteString("[Earlier conversation summarized]\n")
-		sb.WriteString(e.summarizeEarlierTurns(turns[:len(turns)-e.windowSize]))
-		sb.WriteString("\n[Recent turns in full]\n\n")
+		data.Summary = e.summarizeEarlierTurns(turns[:len(turns)-e.windowSize])
 		turns = turns[len(turns)-e.windowSize:]
 	}
+	data.Turns = turns
 
-	for _, turn := range turns {
-		role := strings.ToUpper(turn.Role)
-		sb.WriteString(fmt.Sprintf("[%s] (Turn %d): %s\n\n", role, turn.TurnID, turn.Content))
-	}
-
-	sb.WriteString(`
-Evaluate:
-1. Coherence (0-1): Does the assistant maintain context across turns?
-2. Consistency (0-1): Are there any contradictions in assistant responses?
-3. Reference handling: Does the assistant properly resolve pronouns and references?
-
-Look for:
-- Context loss (forgetting earlier information)
-- Contradictions between responses
-- Improper handling of references to earlier turns
-
-Respond with JSON:
-{
-  "coherence": <float>,
-  "consistency": <float>,
-  "overall": <float>,
-  "confidence": <float>,
-  "context_losses": [{"turn_id": <int>, "description": "..."}],
-  "contradictions": [{"turn_ids": [<int>, <int>], "description": "..."}],
-  "issues": [{"type": "...", "severity": "error|warning|info", "description": "...", "turn_id": <int or null>}],
-  "reasoning": "..."
-}`)
-
-	return sb.String()
+	return e.render(coherenceTemplate, data)
 }
 
 func (e *CoherenceEvaluator) summarizeEarlierTurns(turns []domain.Turn) string {
@@ -164,36 +146,29 @@ func (e *CoherenceEvaluator) summarizeEarlierTurns(turns []domain.Turn) string {
 }
 
 func (e *CoherenceEvaluator) summarizeWithLLM(turns []domain.Turn) (string, error) {
-	var sb strings.Builder
-	sb.WriteString("Summarize the following conversation turns, focusing on:\n")
-	sb.WriteString("1. Key topics discussed\n")
-	sb.WriteString("2. Important entities (names, places, dates, etc.)\n")
-	sb.WriteString("3. User requests and assistant commitments\n")
-	sb.WriteString("4. Any context that would be needed to understand later turns\n\n")
+	data := struct {
+		Turns   []domain.Turn
+		Omitted int
+	}{Turns: turns}
 
 	// Include all turns but keep it concise
-	for i, turn := range turns {
-		if i >= 20 {
-			sb.WriteString(fmt.Sprintf("... and %d more turns\n", len(turns)-i))
-			break
-		}
-		role := strings.ToUpper(turn.Role)
-		content := turn.Content
-		if len(content) > 200 {
-			content = content[:200] + "..."
-		}
-		sb.WriteString(fmt.Sprintf("[%s] (Turn %d): %s\n", role, turn.TurnID, content))
+	if len(turns) > 20 {
+		data.Turns = turns[:20]
+		data.Omitted = len(turns) - 20
 	}
 
-	sb.WriteString("\nProvide a concise summary (3-5 sentences):")
+	rendered, err := e.render(coherenceSummaryTemplate, data)
+	if err != nil {
+		return "", err
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	resp, err := e.client.Complete(ctx, &llm.CompletionRequest{
+	resp, err := e.complete(ctx, e.client, &llm.CompletionRequest{
 		Messages: []llm.Message{
 			{Role: "system", Content: "You are a helpful assistant that creates concise, informative summaries of conversations."},
-			{Role: "user", Content: sb.String()},
+			{Role: "user", Content: rendered.Text},
 		},
 		MaxTokens:   300,
 		Temperature: 0.3,
@@ -232,6 +207,24 @@ func (e *CoherenceEvaluator) summarizeSimple(turns []domain.Turn) string {
 	return sb.String()
 }
 
+// coherenceSchema is the shape of coherenceResponse.
+var coherenceSchema = objectSchema(map[string]*llm.Schema{
+	"coherence":   scoreSchema(),
+	"consistency": scoreSchema(),
+	"overall":     scoreSchema(),
+	"confidence":  scoreSchema(),
+	"context_losses": arraySchema(objectSchema(map[string]*llm.Schema{
+		"turn_id":     integerSchema,
+		"description": stringSchema,
+	}, "description")),
+	"contradictions": arraySchema(objectSchema(map[string]*llm.Schema{
+		"turn_ids":    arraySchema(integerSchema),
+		"description": stringSchema,
+	}, "description")),
+	"issues":    issuesSchema,
+	"reasoning": stringSchema,
+}, "coherence", "consistency")
+
 type coherenceResponse struct {
 	Coherence      float64        `json:"coherence"`
 	Consistency    float64        `json:"consistency"`
@@ -2,8 +2,11 @@ package evaluator
 
 import (
 	"context"
+	"time"
 
 	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/prompt"
 )
 
 type Evaluator interface {
@@ -13,8 +16,58 @@ type Evaluator interface {
 	Weight() float64
 }
 
+// EvaluatorConfig tunes a single evaluator in the pipeline. Zero values fall
+// back to the evaluator's built-in defaults.
 type EvaluatorConfig struct {
-	Enabled bool
-	Weight  float64
+	Type               domain.EvaluatorType `yaml:"type"`
+	Enabled            bool                 `yaml:"enabled"`
+	Weight             float64              `yaml:"weight"`
+	WindowSize         int                  `yaml:"window_size"`
+	Timeout            time.Duration        `yaml:"timeout"`
+	Model              string               `yaml:"model"`
+	Provider           string               `yaml:"provider"`
+	Fallback           []string             `yaml:"fallback"` // provider[:model] chain; [] disables the global one
+	LatencyThresholdMs int                  `yaml:"latency_threshold_ms"`
+	Rubric             string               `yaml:"rubric"` // rubric file defining a custom evaluator
+
+	rubric *Rubric
+}
+
+// llmOptions routes an LLM-backed evaluator's calls to a specific provider
+// and model, and the providers to fall back to, instead of the client
+// defaults, and selects the prompt library its templates are rendered from.
+type llmOptions struct {
+	model    string
+	provider string
+	fallback []llm.Route // nil uses the client's chain
+	prompts  *prompt.Library
+}
+
+// conversationPromptData is the input to the built-in evaluator templates.
+// Summary condenses the turns that fell outside the evaluator's window.
+type conversationPromptData struct {
+	Summary string
+	Turns   []domain.Turn
 }
 
+// Provider returns the LLM provider the evaluator calls first, if pinned.
+func (o llmOptions) Provider() string {
+	return o.provider
+}
+
+func (o llmOptions) render(name string, data any) (*prompt.Rendered, error) {
+	prompts := o.prompts
+	if prompts == nil {
+		prompts = prompt.Default()
+	}
+	return prompts.Render(name, data)
+}
+
+func (o llmOptions) complete(ctx context.Context, client *llm.Client, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	provider := o.provider
+	if provider == "" {
+		provider = client.DefaultProvider()
+	}
+	routes := client.Chain(llm.Route{Provider: provider, Model: o.model}, o.fallback)
+	return client.CompleteWithFallback(ctx, routes, req)
+}
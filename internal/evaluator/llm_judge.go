@@ -10,9 +10,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/saisaravanan/healing-eval/internal/domain"
 	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/prompt"
 )
 
+const llmJudgeTemplate = "llm_judge.v1"
+
 type LLMJudgeEvaluator struct {
+	llmOptions
 	client     *llm.Client
 	weight     float64
 	windowSize int
@@ -41,20 +45,22 @@ func (e *LLMJudgeEvaluator) Weight() float64 {
 func (e *LLMJudgeEvaluator) Evaluate(ctx context.Context, conv *domain.Conversation) (*domain.Evaluation, error) {
 	start := time.Now()
 
-	prompt := e.buildPrompt(conv)
+	rendered, err := e.buildPrompt(conv)
+	if err != nil {
+		return nil, fmt.Errorf("build prompt: %w", err)
+	}
 
-	resp, err := e.client.Complete(ctx, &llm.CompletionRequest{
+	resp, err := e.completeJSON(ctx, e.client, &llm.CompletionRequest{
 		Messages: []llm.Message{
 			{Role: "system", Content: "You are an expert AI response evaluator. Always respond with valid JSON."},
-			{Role: "user", Content: prompt},
+			{Role: "user", Content: rendered.Text},
 		},
 		MaxTokens:   1024,
 		Temperature: 0.1,
 		JSONMode:    true,
-	})
-
+	}, llmJudgeSchema)
 	if err != nil {
-		return nil, fmt.Errorf("llm completion: %w", err)
+		return nil, err
 	}
 
 	result, err := e.parseResponse(resp.Content)
@@ -63,7 +69,7 @@ func (e *LLMJudgeEvaluator) Evaluate(ctx context.Context, conv *domain.Conversat
 	}
 
 	// Calculate cost
-	cost := CalculateCost(resp.ModelName, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	cost := responseCost(resp)
 
 	return &domain.Evaluation{
 		ID:               uuid.New().String(),
@@ -71,75 +77,43 @@ func (e *LLMJudgeEvaluator) Evaluate(ctx context.Context, conv *domain.Conversat
 		EvaluatorType:    domain.EvaluatorTypeLLMJudge,
 		Status:           domain.EvalStatusSuccess,
 		ModelName:        resp.ModelName,
+		Provider:         resp.Provider,
 		PromptTokens:     resp.Usage.PromptTokens,
 		CompletionTokens: resp.Usage.CompletionTokens,
 		TotalTokens:      resp.Usage.TotalTokens,
 		EstimatedCostUSD: cost,
+		Cached:           resp.Cached,
 		Scores: domain.Scores{
 			Overall:         result.Overall,
 			ResponseQuality: result.ResponseQuality,
 			Helpfulness:     result.Helpfulness,
 			Factuality:      result.Factuality,
 		},
-		Issues:     result.Issues,
-		Confidence: result.Confidence,
-		RawOutput:  json.RawMessage(resp.Content),
-		LatencyMs:  int(time.Since(start).Milliseconds()),
-		CreatedAt:  time.Now(),
+		Issues:         result.Issues,
+		Confidence:     result.Confidence,
+		RawOutput:      json.RawMessage(resp.Content),
+		LatencyMs:      int(time.Since(start).Milliseconds()),
+		PromptTemplate: rendered.Name,
+		PromptHash:     rendered.Hash,
+		CreatedAt:      time.Now(),
 	}, nil
 }
 
-func (e *LLMJudgeEvaluator) buildPrompt(conv *domain.Conversation) string {
-	var sb strings.Builder
-
-	sb.WriteString("Evaluate this AI assistant conversation:\n\n")
-
+func (e *LLMJudgeEvaluator) buildPrompt(conv *domain.Conversation) (*prompt.Rendered, error) {
 	// Sanitize conversation to prevent prompt injection and handle large messages
 	sanitizer := NewMessageSanitizer()
 	turns := sanitizer.PrepareConversationForEval(conv.Turns)
 
+	var data conversationPromptData
+
 	// Apply windowing for long conversations
 	if len(turns) > e.windowSize*2 {
-		sb.WriteString("[Earlier conversation summarized]\n")
-		sb.WriteString(e.summarizeEarlierTurns(turns[:len(turns)-e.windowSize]))
-		sb.WriteString("\n[Recent turns in detail]\n\n")
+		data.Summary = e.summarizeEarlierTurns(turns[:len(turns)-e.windowSize])
 		turns = turns[len(turns)-e.windowSize:]
 	}
+	data.Turns = turns
 
-	for _, turn := range turns {
-		role := strings.ToUpper(turn.Role)
-		sb.WriteString(fmt.Sprintf("[%s] (Turn %d): %s\n", role, turn.TurnID, turn.Content))
-
-		if len(turn.ToolCalls) > 0 {
-			sb.WriteString("Tool Calls:\n")
-			for _, tc := range turn.ToolCalls {
-				sb.WriteString(fmt.Sprintf("- %s: %s\n", tc.ToolName, string(tc.Parameters)))
-				if tc.Result != nil {
-					sb.WriteString(fmt.Sprintf("  Result: %s\n", tc.Result.Status))
-				}
-			}
-		}
-		sb.WriteString("\n")
-	}
-
-	sb.WriteString(`
-Evaluate the assistant's performance on:
-1. Response Quality (0-1): Is the response well-structured and appropriate?
-2. Helpfulness (0-1): Does it effectively address the user's needs?
-3. Factuality (0-1): Are claims accurate based on context?
-
-Respond with JSON:
-{
-  "response_quality": <float>,
-  "helpfulness": <float>,
-  "factuality": <float>,
-  "overall": <float>,
-  "confidence": <float>,
-  "issues": [{"type": "...", "severity": "error|warning|info", "description": "...", "turn_id": <int or null>}],
-  "reasoning": "..."
-}`)
-
-	return sb.String()
+	return e.render(llmJudgeTemplate, data)
 }
 
 func (e *LLMJudgeEvaluator) summarizeEarlierTurns(turns []domain.Turn) string {
@@ -173,6 +147,17 @@ func (e *LLMJudgeEvaluator) summarizeEarlierTurns(turns []domain.Turn) string {
 	return sb.String()
 }
 
+// llmJudgeSchema is the shape of llmJudgeResponse.
+var llmJudgeSchema = objectSchema(map[string]*llm.Schema{
+	"response_quality": scoreSchema(),
+	"helpfulness":      scoreSchema(),
+	"factuality":       scoreSchema(),
+	"overall":          scoreSchema(),
+	"confidence":       scoreSchema(),
+	"issues":           issuesSchema,
+	"reasoning":        stringSchema,
+}, "response_quality", "helpfulness", "factuality")
+
 type llmJudgeResponse struct {
 	ResponseQuality float64        `json:"response_quality"`
 	Helpfulness     float64        `json:"helpfulness"`
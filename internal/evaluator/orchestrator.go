@@ -2,49 +2,71 @@ package evaluator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const defaultEvaluatorTimeout = 30 * time.Second
+
 type Orchestrator struct {
 	evaluators []Evaluator
+	timeouts   map[domain.EvaluatorType]time.Duration
 }
 
 func NewOrchestrator(evaluators ...Evaluator) *Orchestrator {
-	return &Orchestrator{evaluators: evaluators}
+	return &Orchestrator{
+		evaluators: evaluators,
+		timeouts:   make(map[domain.EvaluatorType]time.Duration),
+	}
 }
 
 func (o *Orchestrator) AddEvaluator(e Evaluator) {
 	o.evaluators = append(o.evaluators, e)
 }
 
+// SetTimeout overrides the per-run timeout for one evaluator type.
+func (o *Orchestrator) SetTimeout(evalType domain.EvaluatorType, timeout time.Duration) {
+	o.timeouts[evalType] = timeout
+}
+
+func (o *Orchestrator) timeoutFor(evalType domain.EvaluatorType) time.Duration {
+	if t, ok := o.timeouts[evalType]; ok && t > 0 {
+		return t
+	}
+	return defaultEvaluatorTimeout
+}
+
 type evaluationResult struct {
 	evaluation *domain.Evaluation
-	err        error
-	evalType   domain.EvaluatorType
+	failure    *domain.EvaluatorFailure
 }
 
 func (o *Orchestrator) Evaluate(ctx context.Context, conv *domain.Conversation) (*domain.AggregatedEvaluation, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "evaluate",
+		trace.WithAttributes(attribute.String("conversation.id", conv.ID)))
+	defer span.End()
+
 	results := make(chan evaluationResult, len(o.evaluators))
 	var wg sync.WaitGroup
 
-	expectedCount := len(o.evaluators)
-
 	// Run all evaluators in parallel with per-evaluator timeout
 	for _, eval := range o.evaluators {
 		wg.Add(1)
 		go func(e Evaluator) {
 			defer wg.Done()
-			result, err := o.evaluateWithTimeout(ctx, e, conv, 30*time.Second)
+			result, failure := o.runEvaluator(ctx, e, conv)
 			results <- evaluationResult{
 				evaluation: result,
-				err:        err,
-				evalType:   e.Type(),
+				failure:    failure,
 			}
 		}(eval)
 	}
@@ -57,36 +79,101 @@ func (o *Orchestrator) Evaluate(ctx context.Context, conv *domain.Conversation)
 	// Collect results and track failures
 	var successful []*domain.Evaluation
 	var failures []domain.EvaluatorFailure
-	tokenUsage := &domain.AggregatedTokenUsage{
-		ByEvaluator:      make(map[domain.EvaluatorType]domain.TokenUsage),
-		MaxBudgetPerEval: 50000, // Demo: very large budget
-	}
 
 	for r := range results {
-		if r.err != nil {
-			// RECORD FAILURE with details
-			failures = append(failures, domain.EvaluatorFailure{
-				EvaluatorType: r.evalType,
-				ErrorMessage:  r.err.Error(),
-				Retryable:     isRetryable(r.err),
-			})
-			log.Printf("Evaluator %s failed: %v", r.evalType, r.err)
+		if r.failure != nil {
+			failures = append(failures, *r.failure)
 			continue
 		}
 
 		if r.evaluation != nil {
 			successful = append(successful, r.evaluation)
+		}
+	}
 
-			// Aggregate token usage
-			tokenUsage.TotalTokens += r.evaluation.TotalTokens
-			tokenUsage.TotalCost += r.evaluation.EstimatedCostUSD
-			tokenUsage.ByEvaluator[r.evalType] = domain.TokenUsage{
-				PromptTokens:     r.evaluation.PromptTokens,
-				CompletionTokens: r.evaluation.CompletionTokens,
-				TotalTokens:      r.evaluation.TotalTokens,
-				EstimatedCost:    r.evaluation.EstimatedCostUSD,
-				ModelName:        r.evaluation.ModelName,
-			}
+	result := o.Aggregate(conv, successful, failures)
+	span.SetAttributes(
+		attribute.String("evaluation.status", string(result.Status)),
+		attribute.Int("evaluation.failed_evaluators", len(failures)),
+	)
+	return result, nil
+}
+
+// runEvaluator runs one evaluator, stamping the provider on success and
+// returning a classified failure otherwise. A failure is attributed to the
+// provider that failed, which may be a fallback rather than the
+// evaluator's primary.
+func (o *Orchestrator) runEvaluator(ctx context.Context, e Evaluator, conv *domain.Conversation) (*domain.Evaluation, *domain.EvaluatorFailure) {
+	start := time.Now()
+	provider := providerOf(e)
+
+	ctx, span := tracing.Tracer().Start(ctx, "evaluator "+string(e.Type()),
+		trace.WithAttributes(attribute.String("evaluator.type", string(e.Type()))))
+	defer span.End()
+
+	eval, err := o.evaluateWithTimeout(ctx, e, conv, o.timeoutFor(e.Type()))
+	if err != nil {
+		// RECORD FAILURE with details
+		log.Printf("Evaluator %s failed: %v", e.Type(), err)
+		status := ClassifyError(err)
+		tracing.RecordError(span, err)
+		span.SetAttributes(attribute.String("evaluator.status", string(status)))
+		if failed := llm.FailedProvider(err); failed != "" {
+			provider = failed
+		}
+		return nil, &domain.EvaluatorFailure{
+			EvaluatorType: e.Type(),
+			Status:        status,
+			Provider:      provider,
+			ErrorMessage:  err.Error(),
+			Retryable:     IsRetryable(err),
+			LatencyMs:     int(time.Since(start).Milliseconds()),
+		}
+	}
+
+	if eval != nil && eval.Provider == "" {
+		eval.Provider = provider
+	}
+	if eval != nil {
+		span.SetAttributes(
+			attribute.String("evaluator.provider", eval.Provider),
+			attribute.String("gen_ai.response.model", eval.ModelName),
+			attribute.Int("gen_ai.usage.input_tokens", eval.PromptTokens),
+			attribute.Int("gen_ai.usage.output_tokens", eval.CompletionTokens),
+			attribute.Float64("evaluator.cost_usd", eval.EstimatedCostUSD),
+		)
+	}
+
+	return eval, nil
+}
+
+func providerOf(e Evaluator) string {
+	if p, ok := e.(interface{ Provider() string }); ok {
+		return p.Provider()
+	}
+	return ""
+}
+
+// Aggregate combines per-evaluator results into the conversation's overall
+// evaluation. It is used both after a full run and when a retried evaluator
+// succeeds later.
+func (o *Orchestrator) Aggregate(conv *domain.Conversation, successful []*domain.Evaluation, failures []domain.EvaluatorFailure) *domain.AggregatedEvaluation {
+	expectedCount := len(o.evaluators)
+
+	tokenUsage := &domain.AggregatedTokenUsage{
+		ByEvaluator:      make(map[domain.EvaluatorType]domain.TokenUsage),
+		MaxBudgetPerEval: 50000, // Demo: very large budget
+	}
+	for _, eval := range successful {
+		tokenUsage.TotalTokens += eval.TotalTokens
+		tokenUsage.TotalCost += eval.EstimatedCostUSD
+		tokenUsage.ByEvaluator[eval.EvaluatorType] = domain.TokenUsage{
+			PromptTokens:     eval.PromptTokens,
+			CompletionTokens: eval.CompletionTokens,
+			TotalTokens:      eval.TotalTokens,
+			EstimatedCost:    eval.EstimatedCostUSD,
+			ModelName:        eval.ModelName,
+			Cached:           eval.Cached,
 		}
 	}
 
@@ -102,7 +189,7 @@ func (o *Orchestrator) Evaluate(ctx context.Context, conv *domain.Conversation)
 		log.Printf("Budget check: %v (continuing with result)", err)
 	}
 
-	aggregated := &domain.AggregatedEvaluation{
+	return &domain.AggregatedEvaluation{
 		ConversationID:   conv.ID,
 		Status:           status,
 		Scores:           scores,
@@ -115,8 +202,29 @@ func (o *Orchestrator) Evaluate(ctx context.Context, conv *domain.Conversation)
 		ToolEvaluation:   o.extractToolEvaluation(successful),
 		CreatedAt:        time.Now(),
 	}
+}
+
+// EvaluateOne runs a single evaluator from the pipeline, e.g. to retry it.
+func (o *Orchestrator) EvaluateOne(ctx context.Context, conv *domain.Conversation, evalType domain.EvaluatorType) (*domain.Evaluation, *domain.EvaluatorFailure) {
+	for _, e := range o.evaluators {
+		if e.Type() == evalType {
+			return o.runEvaluator(ctx, e, conv)
+		}
+	}
+	return nil, &domain.EvaluatorFailure{
+		EvaluatorType: evalType,
+		Status:        domain.EvalStatusFailed,
+		ErrorMessage:  fmt.Sprintf("evaluator %s is not in the pipeline", evalType),
+	}
+}
 
-	return aggregated, nil
+// EvaluatorTypes returns the types of the evaluators in the pipeline.
+func (o *Orchestrator) EvaluatorTypes() []domain.EvaluatorType {
+	types := make([]domain.EvaluatorType, len(o.evaluators))
+	for i, e := range o.evaluators {
+		types[i] = e.Type()
+	}
+	return types
 }
 
 func (o *Orchestrator) evaluateWithTimeout(ctx context.Context, e Evaluator, conv *domain.Conversation, timeout time.Duration) (*domain.Evaluation, error) {
@@ -140,7 +248,7 @@ func (o *Orchestrator) evaluateWithTimeout(ctx context.Context, e Evaluator, con
 	case result := <-resultCh:
 		return result.eval, result.err
 	case <-ctx.Done():
-		return nil, fmt.Errorf("evaluation timeout after %v", timeout)
+		return nil, fmt.Errorf("evaluation timeout after %v: %w", timeout, llm.ErrTimeout)
 	}
 }
 
@@ -253,25 +361,26 @@ func (o *Orchestrator) toSlice(evals []*domain.Evaluation) []domain.Evaluation {
 	return result
 }
 
-func isRetryable(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	errStr := strings.ToLower(err.Error())
-	retryablePatterns := []string{
-		"timeout",
-		"rate limit",
-		"429",
-		"503",
-		"connection",
-		"temporary",
-		"deadline exceeded",
-		"context deadline",
+// ClassifyError maps an evaluator error to the status stored on its
+// evaluation row.
+func ClassifyError(err error) domain.EvalStatus {
+	switch {
+	case errors.Is(err, llm.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return domain.EvalStatusTimeout
+	case errors.Is(err, llm.ErrRateLimited):
+		return domain.EvalStatusRateLimited
+	case errors.Is(err, llm.ErrContextOverflow):
+		return domain.EvalStatusContextOverflow
+	default:
+		return domain.EvalStatusFailed
 	}
+}
 
-	for _, pattern := range retryablePatterns {
-		if strings.Contains(errStr, pattern) {
+// IsRetryable reports whether an evaluator error is transient (timeouts,
+// rate limits, unavailable or circuit-broken providers) and worth retrying.
+func IsRetryable(err error) bool {
+	for _, target := range []error{llm.ErrTimeout, context.DeadlineExceeded, llm.ErrRateLimited, llm.ErrUnavailable, llm.ErrCircuitOpen} {
+		if errors.Is(err, target) {
 			return true
 		}
 	}
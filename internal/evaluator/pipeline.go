@@ -0,0 +1,247 @@
+package evaluator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/prompt"
+	"gopkg.in/yaml.v3"
+)
+
+// PipelineConfig describes which evaluators run and how each is tuned.
+// It is loaded from a YAML or JSON file (JSON is valid YAML).
+type PipelineConfig struct {
+	Evaluators []EvaluatorConfig `yaml:"evaluators"`
+}
+
+// UnmarshalYAML defaults Enabled to true so entries only need to list the
+// fields they change.
+func (c *EvaluatorConfig) UnmarshalYAML(value *yaml.Node) error {
+	type raw EvaluatorConfig
+	r := raw{Enabled: true}
+	if err := value.Decode(&r); err != nil {
+		return err
+	}
+	*c = EvaluatorConfig(r)
+	return nil
+}
+
+// DefaultPipelineConfig reproduces the built-in pipeline used when no
+// pipeline file is configured.
+func DefaultPipelineConfig() *PipelineConfig {
+	return &PipelineConfig{
+		Evaluators: []EvaluatorConfig{
+			{Type: domain.EvaluatorTypeHeuristic, Enabled: true, LatencyThresholdMs: 1000},
+			{Type: domain.EvaluatorTypeLLMJudge, Enabled: true},
+			{Type: domain.EvaluatorTypeToolCall, Enabled: true},
+			{Type: domain.EvaluatorTypeCoherence, Enabled: true},
+		},
+	}
+}
+
+// LoadPipelineConfig reads and validates a pipeline file. An empty path
+// returns the default pipeline.
+func LoadPipelineConfig(path string) (*PipelineConfig, error) {
+	if path == "" {
+		return DefaultPipelineConfig(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read pipeline file: %w", err)
+	}
+
+	var cfg PipelineConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse pipeline file %s: %w", path, err)
+	}
+
+	// Rubric paths are relative to the pipeline file. A rubric's name is its
+	// evaluator type, so the entry's type may be omitted.
+	for i := range cfg.Evaluators {
+		ec := &cfg.Evaluators[i]
+		if ec.Rubric == "" {
+			continue
+		}
+
+		rubricPath := ec.Rubric
+		if !filepath.IsAbs(rubricPath) {
+			rubricPath = filepath.Join(filepath.Dir(path), rubricPath)
+		}
+
+		rubric, err := LoadRubric(rubricPath)
+		if err != nil {
+			return nil, fmt.Errorf("evaluators[%d]: %w", i, err)
+		}
+
+		if ec.Type == "" {
+			ec.Type = domain.EvaluatorType(rubric.Name)
+		} else if ec.Type != domain.EvaluatorType(rubric.Name) {
+			return nil, fmt.Errorf("evaluators[%d]: type %q does not match rubric name %q", i, ec.Type, rubric.Name)
+		}
+		ec.rubric = rubric
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pipeline file %s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+func (c *PipelineConfig) Validate() error {
+	seen := make(map[domain.EvaluatorType]bool)
+	enabled := 0
+
+	for i, ec := range c.Evaluators {
+		switch {
+		case ec.Type == "":
+			return fmt.Errorf("evaluators[%d]: type or rubric is required", i)
+		case builtinEvaluatorTypes[ec.Type]:
+			if ec.Rubric != "" {
+				return fmt.Errorf("evaluators[%d]: built-in type %q cannot use a rubric", i, ec.Type)
+			}
+		case ec.rubric == nil:
+			return fmt.Errorf("evaluators[%d]: unknown type %q (set rubric to define a custom evaluator)", i, ec.Type)
+		}
+
+		if seen[ec.Type] {
+			return fmt.Errorf("evaluators[%d]: duplicate type %q", i, ec.Type)
+		}
+		seen[ec.Type] = true
+
+		if ec.Weight < 0 {
+			return fmt.Errorf("%s: weight must be >= 0", ec.Type)
+		}
+		if ec.WindowSize < 0 {
+			return fmt.Errorf("%s: window_size must be >= 0", ec.Type)
+		}
+		if ec.Timeout < 0 {
+			return fmt.Errorf("%s: timeout must be >= 0", ec.Type)
+		}
+		if ec.LatencyThresholdMs < 0 {
+			return fmt.Errorf("%s: latency_threshold_ms must be >= 0", ec.Type)
+		}
+		if ec.Type == domain.EvaluatorTypeHeuristic && (ec.Model != "" || ec.Provider != "" || ec.Fallback != nil) {
+			return fmt.Errorf("%s: model, provider and fallback do not apply", ec.Type)
+		}
+		for _, entry := range ec.Fallback {
+			if _, err := llm.ParseRoute(entry); err != nil {
+				return fmt.Errorf("%s: fallback: %w", ec.Type, err)
+			}
+		}
+
+		if ec.Enabled {
+			enabled++
+		}
+	}
+
+	if enabled == 0 {
+		return fmt.Errorf("at least one evaluator must be enabled")
+	}
+
+	return nil
+}
+
+// BuildOrchestrator constructs the enabled evaluators and applies their
+// overrides. It fails if an LLM evaluator references a provider the client
+// was not configured with. A nil prompts library uses the embedded templates.
+func BuildOrchestrator(cfg *PipelineConfig, client *llm.Client, prompts *prompt.Library) (*Orchestrator, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	orchestrator := NewOrchestrator()
+
+	for _, ec := range cfg.Evaluators {
+		if !ec.Enabled {
+			continue
+		}
+
+		if ec.Type != domain.EvaluatorTypeHeuristic {
+			if client == nil {
+				return nil, fmt.Errorf("%s: requires an LLM client", ec.Type)
+			}
+			if ec.Provider != "" && !client.HasProvider(ec.Provider) {
+				return nil, fmt.Errorf("%s: provider %q is not configured", ec.Type, ec.Provider)
+			}
+		}
+
+		// Pin the provider so failures record which one was tried first;
+		// evaluations record the provider that answered.
+		opts := llmOptions{model: ec.Model, provider: ec.Provider, prompts: prompts}
+		if opts.provider == "" && client != nil {
+			opts.provider = client.DefaultProvider()
+		}
+		if ec.Fallback != nil {
+			opts.fallback = []llm.Route{}
+			for _, entry := range ec.Fallback {
+				route, _ := llm.ParseRoute(entry)
+				if !client.HasProvider(route.Provider) {
+					return nil, fmt.Errorf("%s: fallback provider %q is not configured", ec.Type, route.Provider)
+				}
+				opts.fallback = append(opts.fallback, route)
+			}
+		}
+
+		var e Evaluator
+		switch ec.Type {
+		case domain.EvaluatorTypeHeuristic:
+			h := NewHeuristicEvaluator(ec.LatencyThresholdMs)
+			if ec.Weight > 0 {
+				h.weight = ec.Weight
+			}
+			e = h
+		case domain.EvaluatorTypeLLMJudge:
+			j := NewLLMJudgeEvaluator(client)
+			j.llmOptions = opts
+			if ec.Weight > 0 {
+				j.weight = ec.Weight
+			}
+			if ec.WindowSize > 0 {
+				j.windowSize = ec.WindowSize
+			}
+			e = j
+		case domain.EvaluatorTypeToolCall:
+			t := NewToolCallEvaluator(client)
+			t.llmOptions = opts
+			if ec.Weight > 0 {
+				t.weight = ec.Weight
+			}
+			if ec.WindowSize > 0 {
+				t.windowSize = ec.WindowSize
+			}
+			e = t
+		case domain.EvaluatorTypeCoherence:
+			ch := NewCoherenceEvaluator(client)
+			ch.llmOptions = opts
+			if ec.Weight > 0 {
+				ch.weight = ec.Weight
+			}
+			if ec.WindowSize > 0 {
+				ch.windowSize = ec.WindowSize
+			}
+			e = ch
+		default:
+			r := NewRubricEvaluator(client, ec.rubric)
+			r.llmOptions = opts
+			if ec.Weight > 0 {
+				r.weight = ec.Weight
+			}
+			if ec.WindowSize > 0 {
+				r.windowSize = ec.WindowSize
+			}
+			e = r
+		}
+
+		orchestrator.AddEvaluator(e)
+		if ec.Timeout > 0 {
+			orchestrator.SetTimeout(ec.Type, ec.Timeout)
+		}
+	}
+
+	return orchestrator, nil
+}
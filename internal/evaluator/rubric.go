@@ -0,0 +1,377 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/prompt"
+	"gopkg.in/yaml.v3"
+)
+
+const rubricTemplate = "rubric.v1"
+
+const (
+	defaultRubricWeight     = 0.2
+	defaultRubricWindowSize = 15
+	defaultRubricMaxTokens  = 1024
+)
+
+// Rubric names become evaluator types, so they must fit the
+// evaluations.evaluator_type VARCHAR(32) column.
+var rubricNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// A custom template is named "<rubric>/<file>", which is stored in the
+// evaluations.prompt_template VARCHAR(64) column.
+const maxTemplateNameLen = 64
+
+var builtinEvaluatorTypes = map[domain.EvaluatorType]bool{
+	domain.EvaluatorTypeHeuristic: true,
+	domain.EvaluatorTypeLLMJudge:  true,
+	domain.EvaluatorTypeToolCall:  true,
+	domain.EvaluatorTypeCoherence: true,
+}
+
+// Rubric defines a custom LLM evaluator: what to score, which issue types to
+// report and, optionally, the prompt template to render.
+type Rubric struct {
+	Name         string            `yaml:"name"`
+	Description  string            `yaml:"description"`
+	Instructions string            `yaml:"instructions"`
+	Criteria     []RubricCriterion `yaml:"criteria"`
+	IssueTypes   []string          `yaml:"issue_types"`
+	Template     string            `yaml:"template"` // path to a text/template file, relative to the rubric file
+	WindowSize   int               `yaml:"window_size"`
+	MaxTokens    int               `yaml:"max_tokens"`
+
+	tmpl *prompt.Template // nil renders the library's rubric template
+}
+
+// RubricCriterion is one score dimension. Weight is relative to the other
+// criteria and defaults to 1.
+type RubricCriterion struct {
+	Name        string  `yaml:"name"`
+	Description string  `yaml:"description"`
+	Weight      float64 `yaml:"weight"`
+}
+
+// LoadRubric reads, validates and compiles a rubric file (YAML or JSON).
+func LoadRubric(path string) (*Rubric, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rubric: %w", err)
+	}
+
+	var r Rubric
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parse rubric %s: %w", path, err)
+	}
+
+	if err := r.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rubric %s: %w", path, err)
+	}
+
+	if r.Template != "" {
+		tmplPath := r.Template
+		if !filepath.IsAbs(tmplPath) {
+			tmplPath = filepath.Join(filepath.Dir(path), tmplPath)
+		}
+
+		text, err := os.ReadFile(tmplPath)
+		if err != nil {
+			return nil, fmt.Errorf("read rubric template: %w", err)
+		}
+
+		name := r.Name + "/" + strings.TrimSuffix(filepath.Base(tmplPath), filepath.Ext(tmplPath))
+		if len(name) > maxTemplateNameLen {
+			return nil, fmt.Errorf("invalid rubric %s: template name %q is longer than %d characters", path, name, maxTemplateNameLen)
+		}
+		if r.tmpl, err = prompt.Parse(name, string(text)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &r, nil
+}
+
+func (r *Rubric) Validate() error {
+	if !rubricNamePattern.MatchString(r.Name) {
+		return fmt.Errorf("name %q must be lowercase letters, digits and underscores (max 32)", r.Name)
+	}
+	if builtinEvaluatorTypes[domain.EvaluatorType(r.Name)] {
+		return fmt.Errorf("name %q is reserved for a built-in evaluator", r.Name)
+	}
+	if len(r.Criteria) == 0 {
+		return fmt.Errorf("at least one criterion is required")
+	}
+
+	seen := make(map[string]bool)
+	for i := range r.Criteria {
+		c := &r.Criteria[i]
+		if c.Name == "" {
+			return fmt.Errorf("criteria[%d]: name is required", i)
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("criteria[%d]: duplicate name %q", i, c.Name)
+		}
+		seen[c.Name] = true
+		if c.Weight < 0 {
+			return fmt.Errorf("criteria[%d]: weight must be >= 0", i)
+		}
+		if c.Weight == 0 {
+			c.Weight = 1
+		}
+	}
+
+	if r.WindowSize < 0 {
+		return fmt.Errorf("window_size must be >= 0")
+	}
+	if r.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens must be >= 0")
+	}
+
+	return nil
+}
+
+// Schema returns the JSON shape the judge must respond with.
+func (r *Rubric) Schema() string {
+	var sb strings.Builder
+	sb.WriteString("{\n  \"scores\": {\n")
+	for i, c := range r.Criteria {
+		sb.WriteString(fmt.Sprintf("    %q: <float between 0 and 1>", c.Name))
+		if i < len(r.Criteria)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+
+	issueType := "<issue type>"
+	if len(r.IssueTypes) > 0 {
+		issueType = "<" + strings.Join(r.IssueTypes, "|") + ">"
+	}
+
+	sb.WriteString(`  },
+  "confidence": <float between 0 and 1>,
+  "issues": [
+    {
+      "type": "` + issueType + `",
+      "severity": "<error|warning|info>",
+      "description": "<specific description>",
+      "turn_id": <optional turn number>
+    }
+  ],
+  "reasoning": "<brief explanation of scores>"
+}`)
+
+	return sb.String()
+}
+
+// responseSchema is the JSON Schema form of Schema, used to validate the
+// judge's response.
+func (r *Rubric) responseSchema() *llm.Schema {
+	scores := make(map[string]*llm.Schema, len(r.Criteria))
+	names := make([]string, len(r.Criteria))
+	for i, c := range r.Criteria {
+		scores[c.Name] = scoreSchema()
+		names[i] = c.Name
+	}
+
+	return objectSchema(map[string]*llm.Schema{
+		"scores":     objectSchema(scores, names...),
+		"confidence": scoreSchema(),
+		"issues":     issuesSchema,
+		"reasoning":  stringSchema,
+	}, "scores")
+}
+
+type rubricPromptData struct {
+	Rubric  *Rubric
+	Summary string
+	Turns   []domain.Turn
+	Schema  string
+}
+
+// RubricEvaluator scores conversations against a Rubric using an LLM judge.
+// Its evaluator type is the rubric name.
+type RubricEvaluator struct {
+	llmOptions
+	client     *llm.Client
+	rubric     *Rubric
+	schema     *llm.Schema
+	weight     float64
+	windowSize int
+}
+
+func NewRubricEvaluator(client *llm.Client, rubric *Rubric) *RubricEvaluator {
+	windowSize := rubric.WindowSize
+	if windowSize == 0 {
+		windowSize = defaultRubricWindowSize
+	}
+	return &RubricEvaluator{
+		client:     client,
+		rubric:     rubric,
+		schema:     rubric.responseSchema(),
+		weight:     defaultRubricWeight,
+		windowSize: windowSize,
+	}
+}
+
+func (e *RubricEvaluator) Name() string {
+	return e.rubric.Name
+}
+
+func (e *RubricEvaluator) Type() domain.EvaluatorType {
+	return domain.EvaluatorType(e.rubric.Name)
+}
+
+func (e *RubricEvaluator) Weight() float64 {
+	return e.weight
+}
+
+func (e *RubricEvaluator) Evaluate(ctx context.Context, conv *domain.Conversation) (*domain.Evaluation, error) {
+	start := time.Now()
+
+	rendered, err := e.buildPrompt(conv)
+	if err != nil {
+		return nil, fmt.Errorf("build prompt: %w", err)
+	}
+
+	maxTokens := e.rubric.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = defaultRubricMaxTokens
+	}
+
+	resp, err := e.completeJSON(ctx, e.client, &llm.CompletionRequest{
+		Messages: []llm.Message{
+			{Role: "system", Content: "You are an expert AI response evaluator. Always respond with valid JSON."},
+			{Role: "user", Content: rendered.Text},
+		},
+		MaxTokens:   maxTokens,
+		Temperature: 0.1,
+		JSONMode:    true,
+	}, e.schema)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := e.parseResponse(resp.Content)
+	if err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+
+	cost := responseCost(resp)
+
+	return &domain.Evaluation{
+		ID:               uuid.New().String(),
+		ConversationID:   conv.ID,
+		EvaluatorType:    e.Type(),
+		Status:           domain.EvalStatusSuccess,
+		ModelName:        resp.ModelName,
+		Provider:         resp.Provider,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		TotalTokens:      resp.Usage.TotalTokens,
+		EstimatedCostUSD: cost,
+		Cached:           resp.Cached,
+		Scores: domain.Scores{
+			Overall:    result.Overall,
+			Dimensions: result.Scores,
+		},
+		Issues:         result.Issues,
+		Confidence:     result.Confidence,
+		RawOutput:      json.RawMessage(resp.Content),
+		LatencyMs:      int(time.Since(start).Milliseconds()),
+		PromptTemplate: rendered.Name,
+		PromptHash:     rendered.Hash,
+		CreatedAt:      time.Now(),
+	}, nil
+}
+
+func (e *RubricEvaluator) buildPrompt(conv *domain.Conversation) (*prompt.Rendered, error) {
+	sanitizer := NewMessageSanitizer()
+	turns := sanitizer.PrepareConversationForEval(conv.Turns)
+
+	data := rubricPromptData{
+		Rubric: e.rubric,
+		Schema: e.rubric.Schema(),
+	}
+
+	if len(turns) > e.windowSize*2 {
+		data.Summary = e.summarizeEarlierTurns(turns[:len(turns)-e.windowSize])
+		turns = turns[len(turns)-e.windowSize:]
+	}
+	data.Turns = turns
+
+	if e.rubric.tmpl != nil {
+		return e.rubric.tmpl.Render(data)
+	}
+	return e.render(rubricTemplate, data)
+}
+
+func (e *RubricEvaluator) summarizeEarlierTurns(turns []domain.Turn) string {
+	userMsgCount := 0
+	assistantMsgCount := 0
+	toolCallCount := 0
+
+	for _, turn := range turns {
+		switch turn.Role {
+		case "user":
+			userMsgCount++
+		case "assistant":
+			assistantMsgCount++
+			toolCallCount += len(turn.ToolCalls)
+		}
+	}
+
+	return fmt.Sprintf("[%d user messages, %d assistant responses, %d tool calls in earlier conversation]",
+		userMsgCount, assistantMsgCount, toolCallCount)
+}
+
+type rubricResponse struct {
+	Scores     map[string]float64 `json:"scores"`
+	Overall    float64            `json:"-"`
+	Confidence float64            `json:"confidence"`
+	Issues     []domain.Issue     `json:"issues"`
+	Reasoning  string             `json:"reasoning"`
+}
+
+// parseResponse requires a score for every criterion and computes the
+// overall score as the criteria's weighted mean.
+func (e *RubricEvaluator) parseResponse(content string) (*rubricResponse, error) {
+	var result rubricResponse
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	scores := make(map[string]float64, len(e.rubric.Criteria))
+	var weighted, totalWeight float64
+
+	for _, c := range e.rubric.Criteria {
+		score, ok := result.Scores[c.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing score for criterion %q", c.Name)
+		}
+		score = math.Max(0, math.Min(1, score))
+		scores[c.Name] = score
+		weighted += score * c.Weight
+		totalWeight += c.Weight
+	}
+
+	result.Scores = scores
+	result.Overall = weighted / totalWeight
+
+	if result.Confidence == 0 {
+		result.Confidence = 0.8
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,103 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/saisaravanan/healing-eval/internal/llm"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const repairTemplate = "repair.v1"
+
+type repairPromptData struct {
+	Problems []string
+	Schema   string
+}
+
+func objectSchema(properties map[string]*llm.Schema, required ...string) *llm.Schema {
+	return &llm.Schema{Type: "object", Properties: properties, Required: required}
+}
+
+func arraySchema(items *llm.Schema) *llm.Schema {
+	return &llm.Schema{Type: "array", Items: items}
+}
+
+// scoreSchema is a score between 0 and 1.
+func scoreSchema() *llm.Schema {
+	zero, one := 0.0, 1.0
+	return &llm.Schema{Type: "number", Minimum: &zero, Maximum: &one}
+}
+
+var (
+	stringSchema  = &llm.Schema{Type: "string"}
+	integerSchema = &llm.Schema{Type: "integer"}
+
+	// issuesSchema is the shape of a judge's issues list.
+	issuesSchema = arraySchema(objectSchema(map[string]*llm.Schema{
+		"type":        stringSchema,
+		"severity":    stringSchema,
+		"description": stringSchema,
+		"turn_id":     integerSchema,
+	}, "type", "description"))
+)
+
+// completeJSON asks for a response matching schema and returns it with
+// Content replaced by the normalized JSON. JSON wrapped in prose or code
+// fences is extracted, and numbers slightly out of range are clamped. If
+// the response still does not fit the schema, the model is asked once to
+// correct it, given the problems found; the returned usage covers both
+// calls.
+func (o llmOptions) completeJSON(ctx context.Context, client *llm.Client, req *llm.CompletionRequest, schema *llm.Schema) (*llm.CompletionResponse, error) {
+	req.Schema = schema
+	resp, err := o.complete(ctx, client, req)
+	if err != nil {
+		return nil, fmt.Errorf("llm completion: %w", err)
+	}
+
+	content, problems := llm.NormalizeJSON(resp.Content, schema)
+	if len(problems) == 0 {
+		resp.Content = content
+		return resp, nil
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent("response repair", trace.WithAttributes(
+		attribute.StringSlice("evaluator.response_problems", problems)))
+
+	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal schema: %w", err)
+	}
+	rendered, err := o.render(repairTemplate, repairPromptData{Problems: problems, Schema: string(schemaJSON)})
+	if err != nil {
+		return nil, fmt.Errorf("build repair prompt: %w", err)
+	}
+
+	repair := *req
+	repair.Messages = append(append([]llm.Message(nil), req.Messages...),
+		llm.Message{Role: "assistant", Content: resp.Content},
+		llm.Message{Role: "user", Content: rendered.Text},
+	)
+	repaired, err := o.complete(ctx, client, &repair)
+	if err != nil {
+		return nil, fmt.Errorf("llm completion (repair): %w", err)
+	}
+
+	repaired.Usage.PromptTokens += resp.Usage.PromptTokens
+	repaired.Usage.CompletionTokens += resp.Usage.CompletionTokens
+	repaired.Usage.TotalTokens += resp.Usage.TotalTokens
+	repaired.Latency += resp.Latency
+	repaired.Cached = repaired.Cached && resp.Cached
+
+	content, problems = llm.NormalizeJSON(repaired.Content, schema)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("parse response: invalid after repair: %s", strings.Join(problems, "; "))
+	}
+	span.SetAttributes(attribute.Bool("evaluator.response_repaired", true))
+	repaired.Content = content
+	return repaired, nil
+}
@@ -10,9 +10,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/saisaravanan/healing-eval/internal/domain"
 	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/prompt"
 )
 
+const toolCallTemplate = "tool_call.v1"
+
 type ToolCallEvaluator struct {
+	llmOptions
 	client     *llm.Client
 	weight     float64
 	windowSize int
@@ -59,20 +63,22 @@ func (e *ToolCallEvaluator) Evaluate(ctx context.Context, conv *domain.Conversat
 		}, nil
 	}
 
-	prompt := e.buildPrompt(conv)
+	rendered, err := e.buildPrompt(conv)
+	if err != nil {
+		return nil, fmt.Errorf("build prompt: %w", err)
+	}
 
-	resp, err := e.client.Complete(ctx, &llm.CompletionRequest{
+	resp, err := e.completeJSON(ctx, e.client, &llm.CompletionRequest{
 		Messages: []llm.Message{
 			{Role: "system", Content: "You are an expert at evaluating AI tool usage. Always respond with valid JSON."},
-			{Role: "user", Content: prompt},
+			{Role: "user", Content: rendered.Text},
 		},
 		MaxTokens:   1024,
 		Temperature: 0.1,
 		JSONMode:    true,
-	})
-
+	}, toolCallSchema)
 	if err != nil {
-		return nil, fmt.Errorf("llm completion: %w", err)
+		return nil, err
 	}
 
 	result, err := e.parseResponse(resp.Content)
@@ -81,7 +87,7 @@ func (e *ToolCallEvaluator) Evaluate(ctx context.Context, conv *domain.Conversat
 	}
 
 	// Calculate cost
-	cost := CalculateCost(resp.ModelName, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	cost := responseCost(resp)
 
 	return &domain.Evaluation{
 		ID:               uuid.New().String(),
@@ -89,83 +95,43 @@ func (e *ToolCallEvaluator) Evaluate(ctx context.Context, conv *domain.Conversat
 		EvaluatorType:    domain.EvaluatorTypeToolCall,
 		Status:           domain.EvalStatusSuccess,
 		ModelName:        resp.ModelName,
+		Provider:         resp.Provider,
 		PromptTokens:     resp.Usage.PromptTokens,
 		CompletionTokens: resp.Usage.CompletionTokens,
 		TotalTokens:      resp.Usage.TotalTokens,
 		EstimatedCostUSD: cost,
+		Cached:           resp.Cached,
 		Scores: domain.Scores{
 			Overall:           result.Overall,
 			ToolAccuracy:      result.Overall,
 			SelectionAccuracy: result.SelectionAccuracy,
 			ParameterAccuracy: result.ParameterAccuracy,
 		},
-		Issues:     result.Issues,
-		Confidence: result.Confidence,
-		RawOutput:  json.RawMessage(resp.Content),
-		LatencyMs:  int(time.Since(start).Milliseconds()),
-		CreatedAt:  time.Now(),
+		Issues:         result.Issues,
+		Confidence:     result.Confidence,
+		RawOutput:      json.RawMessage(resp.Content),
+		LatencyMs:      int(time.Since(start).Milliseconds()),
+		PromptTemplate: rendered.Name,
+		PromptHash:     rendered.Hash,
+		CreatedAt:      time.Now(),
 	}, nil
 }
 
-func (e *ToolCallEvaluator) buildPrompt(conv *domain.Conversation) string {
-	var sb strings.Builder
-
-	sb.WriteString("Evaluate the tool calls in this conversation:\n\n")
-
+func (e *ToolCallEvaluator) buildPrompt(conv *domain.Conversation) (*prompt.Rendered, error) {
 	// Sanitize conversation to prevent prompt injection and handle large messages
 	sanitizer := NewMessageSanitizer()
 	turns := sanitizer.PrepareConversationForEval(conv.Turns)
 
+	var data conversationPromptData
+
 	// Apply windowing for long conversations with tool calls
 	if len(turns) > e.windowSize*2 {
-		sb.WriteString("[Earlier tool calls summarized]\n")
-		sb.WriteString(e.summarizeEarlierToolCalls(turns[:len(turns)-e.windowSize]))
-		sb.WriteString("\n[Recent turns with details]\n\n")
+		data.Summary = e.summarizeEarlierToolCalls(turns[:len(turns)-e.windowSize])
 		turns = turns[len(turns)-e.windowSize:]
 	}
+	data.Turns = turns
 
-	for _, turn := range turns {
-		if turn.Role == "user" {
-			sb.WriteString(fmt.Sprintf("[USER] (Turn %d): %s\n\n", turn.TurnID, turn.Content))
-		}
-
-		if turn.Role == "assistant" && len(turn.ToolCalls) > 0 {
-			sb.WriteString(fmt.Sprintf("[ASSISTANT] (Turn %d):\n", turn.TurnID))
-			sb.WriteString(fmt.Sprintf("Response: %s\n", turn.Content))
-			sb.WriteString("Tool Calls:\n")
-
-			for _, tc := range turn.ToolCalls {
-				sb.WriteString(fmt.Sprintf("- Tool: %s\n", tc.ToolName))
-				sb.WriteString(fmt.Sprintf("  Parameters: %s\n", string(tc.Parameters)))
-				if tc.Result != nil {
-					sb.WriteString(fmt.Sprintf("  Result Status: %s\n", tc.Result.Status))
-					if tc.Result.Error != "" {
-						sb.WriteString(fmt.Sprintf("  Error: %s\n", tc.Result.Error))
-					}
-				}
-			}
-			sb.WriteString("\n")
-		}
-	}
-
-	sb.WriteString(`
-Evaluate the tool usage:
-1. Selection Accuracy (0-1): Was the correct tool chosen for the task?
-2. Parameter Accuracy (0-1): Were parameters extracted correctly from context?
-3. Check for hallucinated parameters (made-up values not in context)
-
-Respond with JSON:
-{
-  "selection_accuracy": <float>,
-  "parameter_accuracy": <float>,
-  "overall": <float>,
-  "confidence": <float>,
-  "hallucinated_params": ["param1", "param2"],
-  "issues": [{"type": "...", "severity": "error|warning|info", "description": "...", "turn_id": <int or null>}],
-  "reasoning": "..."
-}`)
-
-	return sb.String()
+	return e.render(toolCallTemplate, data)
 }
 
 func (e *ToolCallEvaluator) summarizeEarlierToolCalls(turns []domain.Turn) string {
@@ -206,6 +172,17 @@ func (e *ToolCallEvaluator) summarizeEarlierToolCalls(turns []domain.Turn) strin
 	return sb.String()
 }
 
+// toolCallSchema is the shape of toolCallResponse.
+var toolCallSchema = objectSchema(map[string]*llm.Schema{
+	"selection_accuracy":  scoreSchema(),
+	"parameter_accuracy":  scoreSchema(),
+	"overall":             scoreSchema(),
+	"confidence":          scoreSchema(),
+	"hallucinated_params": arraySchema(stringSchema),
+	"issues":              issuesSchema,
+	"reasoning":           stringSchema,
+}, "selection_accuracy", "parameter_accuracy")
+
 type toolCallResponse struct {
 	SelectionAccuracy  float64        `json:"selection_accuracy"`
 	ParameterAccuracy  float64        `json:"parameter_accuracy"`
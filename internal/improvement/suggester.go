@@ -4,20 +4,26 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/saisaravanan/healing-eval/internal/domain"
 	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/prompt"
 )
 
+const suggestionTemplate = "suggestion.v1"
+
 type Suggester struct {
 	llmClient *llm.Client
+	prompts   *prompt.Library
 }
 
-func NewSuggester(client *llm.Client) *Suggester {
-	return &Suggester{llmClient: client}
+func NewSuggester(client *llm.Client, prompts *prompt.Library) *Suggester {
+	if prompts == nil {
+		prompts = prompt.Default()
+	}
+	return &Suggester{llmClient: client, prompts: prompts}
 }
 
 func (s *Suggester) GenerateSuggestions(ctx context.Context, patterns []*domain.FailurePattern) ([]*domain.Suggestion, error) {
@@ -35,12 +41,15 @@ func (s *Suggester) GenerateSuggestions(ctx context.Context, patterns []*domain.
 }
 
 func (s *Suggester) generateForPattern(ctx context.Context, pattern *domain.FailurePattern) (*domain.Suggestion, error) {
-	prompt := s.buildPrompt(pattern)
+	rendered, err := s.buildPrompt(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("build prompt: %w", err)
+	}
 
 	resp, err := s.llmClient.Complete(ctx, &llm.CompletionRequest{
 		Messages: []llm.Message{
 			{Role: "system", Content: "You are an expert at improving AI agent prompts and tools. Generate actionable improvement suggestions."},
-			{Role: "user", Content: prompt},
+			{Role: "user", Content: rendered.Text},
 		},
 		MaxTokens:   512,
 		Temperature: 0.3,
@@ -71,32 +80,16 @@ func (s *Suggester) generateForPattern(ctx context.Context, pattern *domain.Fail
 	}, nil
 }
 
-func (s *Suggester) buildPrompt(pattern *domain.FailurePattern) string {
-	var sb strings.Builder
-
-	sb.WriteString("Analyze this failure pattern and suggest an improvement:\n\n")
-	sb.WriteString(fmt.Sprintf("Pattern Type: %s\n", pattern.Type))
-	sb.WriteString(fmt.Sprintf("Description: %s\n", pattern.Description))
-	sb.WriteString(fmt.Sprintf("Occurrence Count: %d\n", pattern.Count))
-	sb.WriteString("\nExamples:\n")
-	for i, ex := range pattern.Examples {
-		if i >= 3 {
-			break
-		}
-		sb.WriteString(fmt.Sprintf("- %s\n", ex))
+func (s *Suggester) buildPrompt(pattern *domain.FailurePattern) (*prompt.Rendered, error) {
+	examples := pattern.Examples
+	if len(examples) > 3 {
+		examples = examples[:3]
 	}
 
-	sb.WriteString(`
-Generate an improvement suggestion. Respond with JSON:
-{
-  "suggestion_type": "prompt" or "tool",
-  "target": "<what to modify - prompt name or tool name>",
-  "suggestion": "<specific, actionable suggestion>",
-  "rationale": "<why this will help>",
-  "confidence": <float 0-1>
-}`)
-
-	return sb.String()
+	return s.prompts.Render(suggestionTemplate, struct {
+		Pattern  *domain.FailurePattern
+		Examples []string
+	}{pattern, examples})
 }
 
 type suggestionResponse struct {
@@ -119,4 +112,3 @@ func (s *Suggester) parseResponse(content string) (*suggestionResponse, error) {
 
 	return &result, nil
 }
-
@@ -0,0 +1,133 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+// anthropicMessage is a Messages API message. Content is a string or a list
+// of content blocks.
+type anthropicMessage struct {
+	Role      string          `json:"role"`
+	Content   json.RawMessage `json:"content"`
+	Timestamp *time.Time      `json:"timestamp"`
+}
+
+type anthropicBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+
+	// tool_use
+	ID    string          `json:"id"`
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input"`
+
+	// tool_result
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// decodeAnthropic converts Messages API messages. tool_use blocks become
+// tool calls on the assistant turn and tool_result blocks, which arrive in
+// the following user message, are paired with them by tool_use_id. A user
+// message holding only tool results adds no turn. Thinking, image and
+// document blocks are dropped.
+func decodeAnthropic(data json.RawMessage) ([]domain.Turn, error) {
+	var msgs []anthropicMessage
+	if err := json.Unmarshal(data, &msgs); err != nil {
+		return nil, fmt.Errorf("invalid anthropic messages: %w", err)
+	}
+
+	b := newTurnBuilder()
+	for i, m := range msgs {
+		if m.Role != "user" && m.Role != "assistant" {
+			return nil, fmt.Errorf("messages[%d]: unknown role %q", i, m.Role)
+		}
+
+		blocks, err := anthropicBlocks(m.Content)
+		if err != nil {
+			return nil, fmt.Errorf("messages[%d]: %w", i, err)
+		}
+
+		var text []string
+		var uses, results []anthropicBlock
+		for _, block := range blocks {
+			switch block.Type {
+			case "text":
+				text = append(text, block.Text)
+			case "tool_use":
+				uses = append(uses, block)
+			case "tool_result":
+				results = append(results, block)
+			}
+		}
+
+		for _, r := range results {
+			output, err := anthropicResultText(r.Content)
+			if err != nil {
+				return nil, fmt.Errorf("messages[%d]: %w", i, err)
+			}
+			result := &domain.ToolResult{Status: "success", Data: resultData(output)}
+			if r.IsError {
+				result.Status = "error"
+				result.Error = output
+			}
+			if err := b.setResult(r.ToolUseID, result); err != nil {
+				return nil, fmt.Errorf("messages[%d]: %w", i, err)
+			}
+		}
+
+		if len(text) == 0 && len(uses) == 0 && len(results) > 0 {
+			continue
+		}
+
+		b.add(m.Role, strings.Join(text, "\n"), m.Timestamp)
+		for _, u := range uses {
+			if u.ID == "" {
+				return nil, fmt.Errorf("messages[%d]: tool_use without id", i)
+			}
+			if err := b.addCall(u.ID, u.Name, jsonValue(u.Input)); err != nil {
+				return nil, fmt.Errorf("messages[%d]: %w", i, err)
+			}
+		}
+	}
+	return b.turns, nil
+}
+
+func anthropicBlocks(raw json.RawMessage) ([]anthropicBlock, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []anthropicBlock{{Type: "text", Text: s}}, nil
+	}
+
+	var blocks []anthropicBlock
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return nil, fmt.Errorf("content must be a string or an array of content blocks")
+	}
+	return blocks, nil
+}
+
+// anthropicResultText returns a tool_result's content, a string or a list
+// of text blocks.
+func anthropicResultText(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+
+	blocks, err := anthropicBlocks(raw)
+	if err != nil {
+		return "", fmt.Errorf("tool_result %w", err)
+	}
+	var text []string
+	for _, block := range blocks {
+		if block.Type == "text" {
+			text = append(text, block.Text)
+		}
+	}
+	return strings.Join(text, "\n"), nil
+}
@@ -0,0 +1,193 @@
+// Package ingest decodes conversations submitted in provider chat formats,
+// OpenAI Chat Completions messages and Anthropic Messages content blocks,
+// into the domain schema.
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+// Format names the encoding of ingested conversations.
+type Format string
+
+const (
+	FormatNative    Format = "native"
+	FormatOpenAI    Format = "openai"
+	FormatAnthropic Format = "anthropic"
+)
+
+// Formats lists the accepted formats.
+var Formats = []Format{FormatNative, FormatOpenAI, FormatAnthropic}
+
+// ParseFormat maps a request's format field to a Format. An empty value
+// means the native schema.
+func ParseFormat(s string) (Format, error) {
+	if s == "" {
+		return FormatNative, nil
+	}
+	for _, f := range Formats {
+		if Format(s) == f {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q (want native, openai or anthropic)", s)
+}
+
+// envelope wraps a provider message array with the fields the native
+// schema carries alongside turns.
+type envelope struct {
+	ID           string           `json:"conversation_id"`
+	AgentVersion string           `json:"agent_version"`
+	Messages     json.RawMessage  `json:"messages"`
+	Feedback     *domain.Feedback `json:"feedback,omitempty"`
+	Metadata     json.RawMessage  `json:"metadata,omitempty"`
+}
+
+// Decode reads one conversation in format f. Provider formats are objects
+// with conversation_id, agent_version, optional feedback and metadata, and
+// the provider's messages array. Turn ids are numbered from 1 and tool
+// results are attached to the calls they answer.
+func Decode(f Format, data json.RawMessage) (*domain.Conversation, error) {
+	if f == FormatNative {
+		var conv domain.Conversation
+		if err := json.Unmarshal(data, &conv); err != nil {
+			return nil, fmt.Errorf("invalid conversation: %w", err)
+		}
+		return &conv, nil
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("invalid conversation: %w", err)
+	}
+	if len(env.Messages) == 0 {
+		return nil, fmt.Errorf("conversation %s: messages is required", env.ID)
+	}
+
+	var (
+		turns []domain.Turn
+		err   error
+	)
+	switch f {
+	case FormatOpenAI:
+		turns, err = decodeOpenAI(env.Messages)
+	case FormatAnthropic:
+		turns, err = decodeAnthropic(env.Messages)
+	default:
+		return nil, fmt.Errorf("unknown format %q", f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("conversation %s: %w", env.ID, err)
+	}
+
+	return &domain.Conversation{
+		ID:           env.ID,
+		AgentVersion: env.AgentVersion,
+		Turns:        turns,
+		Feedback:     env.Feedback,
+		Metadata:     env.Metadata,
+	}, nil
+}
+
+// turnBuilder collects turns and pairs tool results with their calls by id.
+type turnBuilder struct {
+	turns []domain.Turn
+	calls map[string]callRef
+	now   time.Time
+}
+
+// callRef locates a tool call by turn and call index.
+type callRef struct{ turn, call int }
+
+func newTurnBuilder() *turnBuilder {
+	return &turnBuilder{calls: make(map[string]callRef), now: time.Now().UTC()}
+}
+
+// add appends a turn. Provider formats carry no timestamps, so a message's
+// optional timestamp field is used, falling back to the ingest time.
+func (b *turnBuilder) add(role, content string, ts *time.Time) {
+	turn := domain.Turn{
+		TurnID:    len(b.turns) + 1,
+		Role:      role,
+		Content:   content,
+		Timestamp: b.now,
+	}
+	if ts != nil {
+		turn.Timestamp = *ts
+	}
+	b.turns = append(b.turns, turn)
+}
+
+// addCall adds a tool call to the last turn.
+func (b *turnBuilder) addCall(id, name string, params json.RawMessage) error {
+	last := len(b.turns) - 1
+	if id != "" {
+		if _, ok := b.calls[id]; ok {
+			return fmt.Errorf("duplicate tool call id %s", id)
+		}
+		b.calls[id] = callRef{turn: last, call: len(b.turns[last].ToolCalls)}
+	}
+	b.turns[last].ToolCalls = append(b.turns[last].ToolCalls, domain.ToolCall{
+		ToolName:   name,
+		Parameters: params,
+	})
+	return nil
+}
+
+// setResult attaches a tool result to the call with the given id.
+func (b *turnBuilder) setResult(id string, result *domain.ToolResult) error {
+	ref, ok := b.calls[id]
+	if !ok {
+		return fmt.Errorf("tool result for unknown tool call id %q", id)
+	}
+	tc := &b.turns[ref.turn].ToolCalls[ref.call]
+	if tc.Result != nil {
+		return fmt.Errorf("duplicate tool result for tool call id %s", id)
+	}
+	tc.Result = result
+	return nil
+}
+
+// lastOpenCall finds the latest call of the named tool without a result.
+func (b *turnBuilder) lastOpenCall(name string) (callRef, bool) {
+	for i := len(b.turns) - 1; i >= 0; i-- {
+		for j := len(b.turns[i].ToolCalls) - 1; j >= 0; j-- {
+			tc := b.turns[i].ToolCalls[j]
+			if tc.Result == nil && tc.ToolName == name {
+				return callRef{turn: i, call: j}, true
+			}
+		}
+	}
+	return callRef{}, false
+}
+
+// jsonValue returns raw as JSON, unwrapping strings that hold JSON as
+// OpenAI tool arguments do. Missing values become an empty object.
+func jsonValue(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 || string(raw) == "null" {
+		return json.RawMessage("{}")
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		if json.Valid([]byte(s)) {
+			return json.RawMessage(s)
+		}
+	}
+	return raw
+}
+
+// resultData stores tool output as JSON, quoting plain text.
+func resultData(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	data, _ := json.Marshal(s)
+	return data
+}
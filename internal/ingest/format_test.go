@@ -0,0 +1,190 @@
+package ingest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"": FormatNative, "native": FormatNative, "openai": FormatOpenAI, "anthropic": FormatAnthropic} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("gemini"); err == nil {
+		t.Error("ParseFormat(gemini) succeeded, want error")
+	}
+}
+
+func decode(t *testing.T, f Format, data string) *domain.Conversation {
+	t.Helper()
+	conv, err := Decode(f, json.RawMessage(data))
+	if err != nil {
+		t.Fatalf("Decode(%s): %v", f, err)
+	}
+	return conv
+}
+
+func checkTurns(t *testing.T, turns []domain.Turn, want ...string) {
+	t.Helper()
+	var got []string
+	for i, turn := range turns {
+		if turn.TurnID != i+1 {
+			t.Errorf("turn %d has TurnID %d", i, turn.TurnID)
+		}
+		got = append(got, turn.Role+":"+turn.Content)
+	}
+	if strings.Join(got, " | ") != strings.Join(want, " | ") {
+		t.Errorf("turns = %q, want %q", got, want)
+	}
+}
+
+func TestDecodeOpenAI(t *testing.T) {
+	conv := decode(t, FormatOpenAI, `{
+		"conversation_id": "oa-1",
+		"agent_version": "v2",
+		"metadata": {"channel": "web"},
+		"messages": [
+			{"role": "system", "content": "You are a travel agent."},
+			{"role": "user", "content": [{"type": "text", "text": "Find flights"}, {"type": "image_url", "image_url": {"url": "x"}}, {"type": "text", "text": "to Paris"}], "timestamp": "2026-01-02T03:04:05Z"},
+			{"role": "assistant", "content": null, "tool_calls": [
+				{"id": "call_1", "type": "function", "function": {"name": "search_flights", "arguments": "{\"to\":\"PAR\"}"}},
+				{"id": "call_2", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Paris\"}"}}
+			]},
+			{"role": "tool", "tool_call_id": "call_2", "content": "sunny"},
+			{"role": "tool", "tool_call_id": "call_1", "content": "{\"error\": \"upstream timeout\"}"},
+			{"role": "assistant", "content": "The search failed, but it is sunny."}
+		]
+	}`)
+
+	if conv.ID != "oa-1" || conv.AgentVersion != "v2" || string(conv.Metadata) != `{"channel": "web"}` {
+		t.Errorf("envelope = %q %q %s", conv.ID, conv.AgentVersion, conv.Metadata)
+	}
+	checkTurns(t, conv.Turns, "user:Find flights\nto Paris", "assistant:", "assistant:The search failed, but it is sunny.")
+	if want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC); !conv.Turns[0].Timestamp.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", conv.Turns[0].Timestamp, want)
+	}
+	if conv.Turns[1].Timestamp.IsZero() {
+		t.Error("turn without a timestamp has none, want the ingest time")
+	}
+
+	calls := conv.Turns[1].ToolCalls
+	if len(calls) != 2 {
+		t.Fatalf("tool calls = %+v, want two", calls)
+	}
+	search, weather := calls[0], calls[1]
+	if search.ToolName != "search_flights" || string(search.Parameters) != `{"to":"PAR"}` {
+		t.Errorf("search call = %s %s", search.ToolName, search.Parameters)
+	}
+	if r := search.Result; r == nil || r.Status != "error" || r.Error != "upstream timeout" {
+		t.Errorf("search result = %+v, want an upstream timeout error", r)
+	}
+	if r := weather.Result; r == nil || r.Status != "success" || string(r.Data) != `"sunny"` {
+		t.Errorf("weather result = %+v, want quoted sunny", r)
+	}
+}
+
+func TestDecodeOpenAILegacyFunctionCall(t *testing.T) {
+	conv := decode(t, FormatOpenAI, `{
+		"conversation_id": "oa-2",
+		"messages": [
+			{"role": "user", "content": "weather?"},
+			{"role": "assistant", "function_call": {"name": "get_weather", "arguments": "{}"}},
+			{"role": "function", "name": "get_weather", "content": "{\"temp\": 21}"}
+		]
+	}`)
+	call := conv.Turns[1].ToolCalls[0]
+	if call.ToolName != "get_weather" || call.Result == nil || string(call.Result.Data) != `{"temp": 21}` {
+		t.Errorf("call = %+v, want get_weather answered by the function message", call)
+	}
+}
+
+func TestDecodeOpenAIErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown call":   `[{"role":"tool","tool_call_id":"nope","content":"x"}]`,
+		"duplicate id":   `[{"role":"assistant","tool_calls":[{"id":"a","function":{"name":"f"}},{"id":"a","function":{"name":"g"}}]}]`,
+		"missing id":     `[{"role":"assistant","tool_calls":[{"function":{"name":"f"}}]}]`,
+		"twice answered": `[{"role":"assistant","tool_calls":[{"id":"a","function":{"name":"f"}}]},{"role":"tool","tool_call_id":"a","content":"1"},{"role":"tool","tool_call_id":"a","content":"2"}]`,
+		"unknown role":   `[{"role":"robot","content":"beep"}]`,
+		"bad content":    `[{"role":"user","content":42}]`,
+		"orphan result":  `[{"role":"function","name":"f","content":"x"}]`,
+	}
+	for name, messages := range tests {
+		if _, err := Decode(FormatOpenAI, json.RawMessage(`{"conversation_id":"e","messages":`+messages+`}`)); err == nil {
+			t.Errorf("%s: Decode succeeded, want error", name)
+		}
+	}
+	if _, err := Decode(FormatOpenAI, json.RawMessage(`{"conversation_id":"e"}`)); err == nil {
+		t.Error("Decode without messages succeeded, want error")
+	}
+}
+
+func TestDecodeAnthropic(t *testing.T) {
+	conv := decode(t, FormatAnthropic, `{
+		"conversation_id": "an-1",
+		"agent_version": "v3",
+		"feedback": {"user_rating": 4},
+		"messages": [
+			{"role": "user", "content": "Book a table for two"},
+			{"role": "assistant", "content": [
+				{"type": "thinking", "thinking": "need availability"},
+				{"type": "text", "text": "Checking availability."},
+				{"type": "tool_use", "id": "toolu_1", "name": "check_availability", "input": {"party": 2}}
+			]},
+			{"role": "user", "content": [
+				{"type": "tool_result", "tool_use_id": "toolu_1", "content": [{"type": "text", "text": "no tables"}], "is_error": true}
+			]},
+			{"role": "assistant", "content": [
+				{"type": "tool_use", "id": "toolu_2", "name": "waitlist", "input": {}}
+			]},
+			{"role": "user", "content": [
+				{"type": "tool_result", "tool_use_id": "toolu_2", "content": "{\"position\": 3}"},
+				{"type": "text", "text": "Thanks"}
+			]}
+		]
+	}`)
+
+	if conv.ID != "an-1" || conv.AgentVersion != "v3" || conv.Feedback == nil || *conv.Feedback.UserRating != 4 {
+		t.Errorf("envelope = %q %q %+v", conv.ID, conv.AgentVersion, conv.Feedback)
+	}
+	// The user message holding only a tool result adds no turn; the one
+	// with text does.
+	checkTurns(t, conv.Turns, "user:Book a table for two", "assistant:Checking availability.", "assistant:", "user:Thanks")
+
+	check := conv.Turns[1].ToolCalls[0]
+	if check.ToolName != "check_availability" || string(check.Parameters) != `{"party": 2}` {
+		t.Errorf("call = %s %s", check.ToolName, check.Parameters)
+	}
+	if r := check.Result; r == nil || r.Status != "error" || r.Error != "no tables" {
+		t.Errorf("result = %+v, want the is_error result", r)
+	}
+	waitlist := conv.Turns[2].ToolCalls[0]
+	if r := waitlist.Result; r == nil || r.Status != "success" || string(r.Data) != `{"position": 3}` {
+		t.Errorf("result = %+v, want the JSON result", r)
+	}
+}
+
+func TestDecodeAnthropicErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown call": `[{"role":"user","content":[{"type":"tool_result","tool_use_id":"nope","content":"x"}]}]`,
+		"missing id":   `[{"role":"assistant","content":[{"type":"tool_use","name":"f","input":{}}]}]`,
+		"system role":  `[{"role":"system","content":"be nice"}]`,
+		"bad content":  `[{"role":"user","content":{"type":"text"}}]`,
+	}
+	for name, messages := range tests {
+		if _, err := Decode(FormatAnthropic, json.RawMessage(`{"conversation_id":"e","messages":`+messages+`}`)); err == nil {
+			t.Errorf("%s: Decode succeeded, want error", name)
+		}
+	}
+}
+
+func TestDecodeNative(t *testing.T) {
+	conv := decode(t, FormatNative, `{"conversation_id":"n-1","agent_version":"v1","turns":[{"turn_id":7,"role":"user","content":"hi"}]}`)
+	if conv.ID != "n-1" || len(conv.Turns) != 1 || conv.Turns[0].TurnID != 7 {
+		t.Errorf("conversation = %+v, want the native turns unchanged", conv)
+	}
+}
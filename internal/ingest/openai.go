@@ -0,0 +1,138 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+// openAIMessage is a Chat Completions message.
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    json.RawMessage  `json:"content"`
+	Name       string           `json:"name"`
+	ToolCalls  []openAIToolCall `json:"tool_calls"`
+	ToolCallID string           `json:"tool_call_id"`
+	// FunctionCall is the pre-tools form of a single call.
+	FunctionCall *openAIFunction `json:"function_call"`
+	Timestamp    *time.Time      `json:"timestamp"`
+}
+
+type openAIToolCall struct {
+	ID       string         `json:"id"`
+	Type     string         `json:"type"`
+	Function openAIFunction `json:"function"`
+}
+
+type openAIFunction struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// decodeOpenAI converts Chat Completions messages. System and developer
+// messages are dropped. tool messages are paired with the assistant
+// tool_calls entry named by tool_call_id, and legacy function messages with
+// the latest unanswered call of that function.
+func decodeOpenAI(data json.RawMessage) ([]domain.Turn, error) {
+	var msgs []openAIMessage
+	if err := json.Unmarshal(data, &msgs); err != nil {
+		return nil, fmt.Errorf("invalid openai messages: %w", err)
+	}
+
+	b := newTurnBuilder()
+	for i, m := range msgs {
+		content, err := openAIContent(m.Content)
+		if err != nil {
+			return nil, fmt.Errorf("messages[%d]: %w", i, err)
+		}
+
+		switch m.Role {
+		case "system", "developer":
+		case "user":
+			b.add("user", content, m.Timestamp)
+		case "assistant":
+			b.add("assistant", content, m.Timestamp)
+			for _, tc := range m.ToolCalls {
+				if tc.ID == "" {
+					return nil, fmt.Errorf("messages[%d]: tool call without id", i)
+				}
+				if err := b.addCall(tc.ID, tc.Function.Name, jsonValue(tc.Function.Arguments)); err != nil {
+					return nil, fmt.Errorf("messages[%d]: %w", i, err)
+				}
+			}
+			if fc := m.FunctionCall; fc != nil {
+				b.addCall("", fc.Name, jsonValue(fc.Arguments))
+			}
+		case "tool":
+			if err := b.setResult(m.ToolCallID, openAIResult(content)); err != nil {
+				return nil, fmt.Errorf("messages[%d]: %w", i, err)
+			}
+		case "function":
+			ref, ok := b.lastOpenCall(m.Name)
+			if !ok {
+				return nil, fmt.Errorf("messages[%d]: function result for %s without a call", i, m.Name)
+			}
+			b.turns[ref.turn].ToolCalls[ref.call].Result = openAIResult(content)
+		default:
+			return nil, fmt.Errorf("messages[%d]: unknown role %q", i, m.Role)
+		}
+	}
+	return b.turns, nil
+}
+
+// openAIContent returns content given as a string or as content parts,
+// keeping the text parts.
+func openAIContent(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+
+	var parts []struct {
+		Type    string `json:"type"`
+		Text    string `json:"text"`
+		Refusal string `json:"refusal"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", fmt.Errorf("content must be a string or an array of parts")
+	}
+	var text []string
+	for _, p := range parts {
+		switch p.Type {
+		case "text":
+			text = append(text, p.Text)
+		case "refusal":
+			text = append(text, p.Refusal)
+		}
+	}
+	return strings.Join(text, "\n"), nil
+}
+
+// openAIResult builds a tool result from a tool message. The format has no
+// error flag, so a JSON object with a non-empty "error" field counts as a
+// failure.
+func openAIResult(content string) *domain.ToolResult {
+	result := &domain.ToolResult{Status: "success", Data: resultData(content)}
+
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal([]byte(content), &body) == nil && len(body.Error) > 0 && string(body.Error) != "null" {
+		var msg string
+		if json.Unmarshal(body.Error, &msg) != nil {
+			msg = string(body.Error)
+		}
+		if msg != "" && msg != "false" {
+			result.Status = "error"
+			result.Error = msg
+		}
+	}
+	return result
+}
@@ -66,6 +66,17 @@ func (p *AnthropicProvider) Complete(ctx context.Context, req *CompletionRequest
 		apiReq.System = systemPrompt
 	}
 
+	// Structured output: the model must call a tool whose input is the
+	// response.
+	if req.Schema != nil {
+		apiReq.Tools = []anthropicTool{{
+			Name:        anthropicResponseTool,
+			Description: "Respond with the requested JSON.",
+			InputSchema: req.Schema,
+		}}
+		apiReq.ToolChoice = &anthropicToolChoice{Type: "tool", Name: anthropicResponseTool}
+	}
+
 	body, err := json.Marshal(apiReq)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
@@ -82,7 +93,7 @@ func (p *AnthropicProvider) Complete(ctx context.Context, req *CompletionRequest
 
 	resp, err := p.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
+		return nil, fmt.Errorf("do request: %w", transportError(err))
 	}
 	defer resp.Body.Close()
 
@@ -92,7 +103,7 @@ func (p *AnthropicProvider) Complete(ctx context.Context, req *CompletionRequest
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api error %d: %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(p.Name(), resp.StatusCode, string(respBody), parseRetryAfter(resp.Header))
 	}
 
 	var apiResp anthropicResponse
@@ -102,8 +113,11 @@ func (p *AnthropicProvider) Complete(ctx context.Context, req *CompletionRequest
 
 	var content string
 	for _, block := range apiResp.Content {
-		if block.Type == "text" {
+		switch {
+		case block.Type == "text":
 			content += block.Text
+		case block.Type == "tool_use" && block.Name == anthropicResponseTool:
+			content = string(block.Input)
 		}
 	}
 
@@ -120,12 +134,27 @@ func (p *AnthropicProvider) Complete(ctx context.Context, req *CompletionRequest
 	}, nil
 }
 
+const anthropicResponseTool = "respond"
+
 type anthropicRequest struct {
-	Model       string             `json:"model"`
-	MaxTokens   int                `json:"max_tokens"`
-	System      string             `json:"system,omitempty"`
-	Messages    []anthropicMessage `json:"messages"`
-	Temperature float64            `json:"temperature,omitempty"`
+	Model       string               `json:"model"`
+	MaxTokens   int                  `json:"max_tokens"`
+	System      string               `json:"system,omitempty"`
+	Messages    []anthropicMessage   `json:"messages"`
+	Temperature float64              `json:"temperature,omitempty"`
+	Tools       []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice  *anthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	InputSchema *Schema `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
 }
 
 type anthropicMessage struct {
@@ -143,8 +172,10 @@ type anthropicResponse struct {
 }
 
 type contentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type  string          `json:"type"`
+	Text  string          `json:"text"`
+	Name  string          `json:"name"`  // tool_use
+	Input json.RawMessage `json:"input"` // tool_use
 }
 
 type anthropicUsage struct {
@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling a provider whose breaker is
+// open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Breaker states.
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half_open"
+)
+
+// breaker stops calls to a provider after consecutive transient failures:
+// rate limits, timeouts and unavailability. Other errors, such as a
+// rejected request, show the provider is answering and count as successes.
+// Once openFor has passed a single probe call is let through: success
+// closes the breaker, failure opens it again.
+type breaker struct {
+	name      string
+	threshold int
+	openFor   time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(name string, threshold int, openFor time.Duration) *breaker {
+	return &breaker{name: name, threshold: threshold, openFor: openFor, state: breakerClosed}
+}
+
+// allow reports whether a call may go ahead. In half-open state only the
+// probe is allowed.
+func (b *breaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.openFor {
+			return false
+		}
+		b.state = breakerHalfOpen
+		log.Printf("LLM provider %s: circuit half-open, probing", b.name)
+		fallthrough
+	case breakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+	}
+	return true
+}
+
+// record counts the outcome of an allowed call.
+func (b *breaker) record(err error) {
+	if b.threshold <= 0 {
+		return
+	}
+	if err != nil && !isTransient(err) {
+		err = nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.probing = false
+		if err == nil {
+			b.state = breakerClosed
+			b.failures = 0
+			log.Printf("LLM provider %s: circuit closed", b.name)
+		} else {
+			b.trip(err)
+		}
+		return
+	}
+
+	if err == nil {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == breakerClosed && b.failures >= b.threshold {
+		b.trip(err)
+	}
+}
+
+// release gives up a half-open probe whose outcome says nothing about the
+// provider, such as one the caller cancelled.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == breakerHalfOpen {
+		b.probing = false
+	}
+}
+
+func (b *breaker) trip(err error) {
+	b.state = breakerOpen
+	b.openedAt = time.Now()
+	log.Printf("LLM provider %s: circuit open for %v after %d consecutive failures: %v",
+		b.name, b.openFor, b.failures, err)
+}
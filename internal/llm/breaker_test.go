@@ -0,0 +1,256 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubProvider answers with fn, counting calls.
+type stubProvider struct {
+	name string
+	fn   func(call int) (*CompletionResponse, error)
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (p *stubProvider) Name() string { return p.name }
+
+func (p *stubProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	p.mu.Lock()
+	p.calls++
+	call := p.calls
+	p.mu.Unlock()
+	return p.fn(call)
+}
+
+func (p *stubProvider) Calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+func failing(name string, err error) *stubProvider {
+	return &stubProvider{name: name, fn: func(int) (*CompletionResponse, error) { return nil, err }}
+}
+
+func answering(name, content string) *stubProvider {
+	return &stubProvider{name: name, fn: func(int) (*CompletionResponse, error) {
+		return &CompletionResponse{Content: content}, nil
+	}}
+}
+
+// newTestClient builds a client over providers without limits or retries,
+// the first provider being the default.
+func newTestClient(threshold int, openFor time.Duration, providers ...Provider) *Client {
+	c := &Client{
+		providers: make(map[string]Provider),
+		breakers:  make(map[string]*breaker),
+		limiters:  make(map[string]*limiter),
+		timeout:   time.Second,
+	}
+	for i, p := range providers {
+		c.providers[p.Name()] = p
+		c.breakers[p.Name()] = newBreaker(p.Name(), threshold, openFor)
+		if i == 0 {
+			c.defaultProvider = p.Name()
+		} else {
+			c.fallback = append(c.fallback, Route{Provider: p.Name()})
+		}
+	}
+	return c
+}
+
+var errBadRequest = newAPIError("stub", 400, "invalid parameter", 0)
+
+func TestBreakerTripsOnTransientErrors(t *testing.T) {
+	b := newBreaker("p", 3, time.Hour)
+	for i := 0; i < 2; i++ {
+		if !b.allow() {
+			t.Fatalf("call %d refused before the threshold", i+1)
+		}
+		b.record(ErrRateLimited)
+	}
+	if !b.allow() {
+		t.Fatal("call 3 refused before the threshold")
+	}
+	b.record(fmt.Errorf("%w: connection refused", ErrUnavailable))
+
+	if b.allow() {
+		t.Error("breaker allowed a call after 3 consecutive transient failures")
+	}
+}
+
+func TestBreakerIgnoresNonTransientErrors(t *testing.T) {
+	b := newBreaker("p", 2, time.Hour)
+	for _, err := range []error{errBadRequest, ErrContextOverflow, errors.New("decode response"), errBadRequest} {
+		b.allow()
+		b.record(err)
+	}
+	if !b.allow() {
+		t.Fatal("breaker opened on errors that show the provider is answering")
+	}
+
+	// A rejected request also resets the count of transient failures.
+	b.record(ErrTimeout)
+	b.allow()
+	b.record(errBadRequest)
+	b.allow()
+	b.record(ErrTimeout)
+	if !b.allow() {
+		t.Error("breaker opened on failures that were not consecutive")
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b := newBreaker("p", 1, 10*time.Millisecond)
+	b.allow()
+	b.record(ErrTimeout)
+	if b.allow() {
+		t.Fatal("breaker allowed a call while open")
+	}
+
+	time.Sleep(15 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("breaker refused the probe after openFor")
+	}
+	if b.allow() {
+		t.Fatal("breaker allowed a second call while probing")
+	}
+
+	// A failed probe opens it again.
+	b.record(ErrRateLimited)
+	if b.allow() {
+		t.Fatal("breaker allowed a call after a failed probe")
+	}
+
+	time.Sleep(15 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("breaker refused the second probe")
+	}
+	// A released probe lets another through.
+	b.release()
+	if !b.allow() {
+		t.Fatal("breaker refused a probe after the last was released")
+	}
+
+	// A non-transient failure counts as the provider answering.
+	b.record(errBadRequest)
+	for i := 0; i < 3; i++ {
+		if !b.allow() {
+			t.Fatal("breaker refused a call after a successful probe")
+		}
+		b.record(nil)
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := newBreaker("p", 0, time.Hour)
+	for i := 0; i < 10; i++ {
+		if !b.allow() {
+			t.Fatal("disabled breaker refused a call")
+		}
+		b.record(ErrUnavailable)
+	}
+}
+
+func TestFallbackSkipsOpenCircuit(t *testing.T) {
+	primary := failing("primary", newAPIError("primary", 503, "overloaded", 0))
+	secondary := answering("secondary", "ok")
+	c := newTestClient(2, time.Hour, primary, secondary)
+
+	for i := 0; i < 4; i++ {
+		resp, err := c.Complete(context.Background(), &CompletionRequest{})
+		if err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+		if resp.Provider != "secondary" {
+			t.Errorf("call %d served by %q, want secondary", i+1, resp.Provider)
+		}
+	}
+	if n := primary.Calls(); n != 2 {
+		t.Errorf("primary called %d times, want 2 before its circuit opened", n)
+	}
+
+	_, err := c.CompleteWithProvider(context.Background(), "primary", &CompletionRequest{})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("CompleteWithProvider error = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestFallbackRejectedRequestKeepsCircuitClosed(t *testing.T) {
+	primary := failing("primary", errBadRequest)
+	c := newTestClient(1, time.Hour, primary, answering("secondary", "ok"))
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.Complete(context.Background(), &CompletionRequest{}); err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+	}
+	if n := primary.Calls(); n != 3 {
+		t.Errorf("primary called %d times, want 3", n)
+	}
+}
+
+func TestFailedProvider(t *testing.T) {
+	timeout := newAPIError("b", 504, "upstream timeout", 0)
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"not from a provider", errors.New("boom"), ""},
+		{"one provider", &ProviderError{Provider: "a", Err: ErrRateLimited}, "a"},
+		{"wrapped", fmt.Errorf("llm completion: %w", &ProviderError{Provider: "a", Err: ErrTimeout}), "a"},
+		{
+			"last called",
+			fmt.Errorf("all providers failed: %w", errors.Join(
+				&ProviderError{Provider: "a", Err: ErrRateLimited},
+				&ProviderError{Provider: "b", Err: timeout},
+			)),
+			"b",
+		},
+		{
+			"skipped by breaker",
+			fmt.Errorf("all providers failed: %w", errors.Join(
+				&ProviderError{Provider: "a", Err: ErrRateLimited},
+				&ProviderError{Provider: "b", Err: ErrCircuitOpen},
+			)),
+			"a",
+		},
+		{
+			"all skipped",
+			fmt.Errorf("all providers failed: %w", errors.Join(
+				&ProviderError{Provider: "a", Err: ErrCircuitOpen},
+				&ProviderError{Provider: "b", Err: ErrCircuitOpen},
+			)),
+			"b",
+		},
+	}
+	for _, tt := range tests {
+		if got := FailedProvider(tt.err); got != tt.want {
+			t.Errorf("%s: FailedProvider = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCompleteWithFallbackNamesFailedProvider(t *testing.T) {
+	c := newTestClient(0, 0,
+		failing("a", ErrRateLimited),
+		failing("b", newAPIError("b", 500, "internal error", 0)),
+	)
+	_, err := c.Complete(context.Background(), &CompletionRequest{})
+	if err == nil {
+		t.Fatal("Complete succeeded, want every provider to fail")
+	}
+	if got := FailedProvider(err); got != "b" {
+		t.Errorf("FailedProvider = %q, want b", got)
+	}
+	if !errors.Is(err, ErrRateLimited) || !errors.Is(err, ErrUnavailable) {
+		t.Errorf("error %v does not wrap both failures", err)
+	}
+}
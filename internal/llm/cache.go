@@ -0,0 +1,211 @@
+package llm
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saisaravanan/healing-eval/internal/config"
+)
+
+// Cache stores completion responses by request. Entries expire after the
+// cache's TTL.
+type Cache interface {
+	Get(ctx context.Context, key string) (*CompletionResponse, bool, error)
+	Set(ctx context.Context, key string, resp *CompletionResponse) error
+	Close() error
+}
+
+// NewCache builds the cache selected by LLM_CACHE: "memory", "redis", or
+// "" for none, in which case it returns nil. The caller closes it.
+func NewCache(cfg *config.LLMConfig, redisCfg *config.RedisConfig) (Cache, error) {
+	switch cfg.Cache {
+	case "":
+		return nil, nil
+	case "memory":
+		return NewLRUCache(cfg.CacheSize, cfg.CacheTTL), nil
+	case "redis":
+		return NewRedisCache(redisCfg, cfg.CacheTTL)
+	default:
+		return nil, fmt.Errorf("unknown LLM_CACHE %q (want memory or redis)", cfg.Cache)
+	}
+}
+
+// cacheKeyFields extends RequestHash with the output format asked for, so a
+// response cached for a free-text request is not served to a JSON-mode or
+// schema request with the same messages, or the reverse.
+type cacheKeyFields struct {
+	Request  string  `json:"request"`
+	JSONMode bool    `json:"json_mode"`
+	Schema   *Schema `json:"schema,omitempty"`
+}
+
+// cacheKey identifies a request to one provider by its RequestHash and
+// output format.
+func cacheKey(provider string, req *CompletionRequest) (string, error) {
+	hash, err := RequestHash(req)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(cacheKeyFields{Request: hash, JSONMode: req.JSONMode, Schema: req.Schema})
+	if err != nil {
+		return "", fmt.Errorf("cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return provider + ":" + hex.EncodeToString(sum[:16]), nil
+}
+
+type cacheBypassKey struct{}
+
+// WithCacheBypass returns a context whose completions skip cache lookups,
+// for when responses are deliberately regenerated. Fresh responses are
+// still stored.
+func WithCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+// CacheBypassed reports whether ctx was made by WithCacheBypass.
+func CacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+// LRUCache is an in-process Cache holding up to size entries, evicting the
+// least recently used.
+type LRUCache struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	order   *list.List // of *lruEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	resp      CompletionResponse
+	expiresAt time.Time
+}
+
+func NewLRUCache(size int, ttl time.Duration) *LRUCache {
+	if size <= 0 {
+		size = 1000
+	}
+	return &LRUCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *LRUCache) Get(ctx context.Context, key string) (*CompletionResponse, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*lruEntry)
+	if c.ttl > 0 && time.Now().After(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(el)
+	resp := entry.resp
+	return &resp, true, nil
+}
+
+func (c *LRUCache) Set(ctx context.Context, key string, resp *CompletionResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &lruEntry{key: key, resp: *resp, expiresAt: time.Now().Add(c.ttl)}
+	if el, ok := c.entries[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+func (c *LRUCache) Close() error {
+	return nil
+}
+
+// RedisCache is a Cache shared by every process using the same Redis.
+type RedisCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+const redisCachePrefix = "llm:cache:"
+
+// NewRedisCache connects to Redis, failing if it does not answer a ping.
+func NewRedisCache(cfg *config.RedisConfig, ttl time.Duration) (*RedisCache, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("redis config validation failed: %w", err)
+	}
+
+	opt := &redis.Options{Addr: cfg.Addr(), Password: cfg.Password, DB: cfg.DB}
+	if cfg.URL != "" {
+		var err error
+		if opt, err = redis.ParseURL(cfg.URL); err != nil {
+			return nil, fmt.Errorf("parse REDIS_URL: %w", err)
+		}
+	}
+	client := redis.NewClient(opt)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("ping redis: %w", err)
+	}
+	return &RedisCache{client: client, ttl: ttl}, nil
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) (*CompletionResponse, bool, error) {
+	data, err := c.client.Get(ctx, redisCachePrefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("get: %w", err)
+	}
+
+	var resp CompletionResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, false, fmt.Errorf("unmarshal: %w", err)
+	}
+	return &resp, true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, resp *CompletionResponse) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	if err := c.client.Set(ctx, redisCachePrefix+key, data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("set: %w", err)
+	}
+	return nil
+}
+
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCacheKeyIncludesOutputFormat(t *testing.T) {
+	p := answering("p", `{"score": 0.5, "reasoning": "r"}`)
+	c := newTestClient(0, 0, p)
+	c.UseCache(NewLRUCache(10, time.Minute))
+
+	messages := []Message{{Role: "user", Content: "rate this"}}
+	requests := []CompletionRequest{
+		{Messages: messages},
+		{Messages: messages, JSONMode: true},
+		{Messages: messages, Schema: judgeSchema()},
+	}
+	for i, req := range requests {
+		resp, err := c.CompleteWithProvider(context.Background(), "p", &req)
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		if resp.Cached {
+			t.Errorf("request %d was served from another format's cache entry", i)
+		}
+	}
+	if n := p.Calls(); n != len(requests) {
+		t.Fatalf("provider called %d times, want %d", n, len(requests))
+	}
+
+	// Each format's own entry is still found.
+	for i, req := range requests {
+		resp, err := c.CompleteWithProvider(context.Background(), "p", &req)
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		if !resp.Cached {
+			t.Errorf("repeated request %d missed the cache", i)
+		}
+	}
+	if n := p.Calls(); n != len(requests) {
+		t.Errorf("provider called %d times, want %d", n, len(requests))
+	}
+}
@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Providers wrap their failures around these errors so callers can tell
+// them apart with errors.Is.
+var (
+	// ErrRateLimited means the provider rejected the call for exceeding a
+	// rate or quota limit.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrContextOverflow means the prompt does not fit the model's context
+	// window. Retrying the same request cannot succeed.
+	ErrContextOverflow = errors.New("context length exceeded")
+	// ErrTimeout means the call or the provider's upstream timed out.
+	ErrTimeout = errors.New("timeout")
+	// ErrUnavailable means the provider could not be reached or failed
+	// with a server error.
+	ErrUnavailable = errors.New("provider unavailable")
+)
+
+// APIError is an error response from a provider's API.
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration // from the Retry-After header, 0 if absent
+
+	kind error // one of the sentinel errors, or nil
+}
+
+func newAPIError(provider string, status int, message string, retryAfter time.Duration) *APIError {
+	return &APIError{
+		Provider:   provider,
+		StatusCode: status,
+		Message:    message,
+		RetryAfter: retryAfter,
+		kind:       classifyStatus(status, message),
+	}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s api error %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
+
+// classifyStatus maps an error response to a sentinel error. Providers
+// report context overflow as a plain bad request, so it is recognised by
+// the message.
+func classifyStatus(status int, message string) error {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status == http.StatusRequestTimeout, status == http.StatusGatewayTimeout:
+		return ErrTimeout
+	case status >= 500:
+		return ErrUnavailable
+	case (status == http.StatusBadRequest || status == http.StatusRequestEntityTooLarge) && isContextOverflow(message):
+		return ErrContextOverflow
+	default:
+		return nil
+	}
+}
+
+func isContextOverflow(message string) bool {
+	message = strings.ToLower(message)
+	for _, s := range []string{"context_length_exceeded", "context length", "maximum context", "prompt is too long", "too many tokens"} {
+		if strings.Contains(message, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// transportError classifies a call that got no response at all.
+// Cancellation by the caller is returned unchanged.
+func transportError(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return err
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	default:
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an
+// HTTP date. It returns 0 if the header is absent or invalid.
+func parseRetryAfter(h http.Header) time.Duration {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+type retryAfterKey struct{}
+
+// withRetryAfter returns a context under which retryAfterTransport stores
+// the Retry-After of an error response, for SDK clients whose errors do
+// not carry response headers.
+func withRetryAfter(ctx context.Context) (context.Context, *time.Duration) {
+	d := new(time.Duration)
+	return context.WithValue(ctx, retryAfterKey{}, d), d
+}
+
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode >= 400 {
+		if d, ok := req.Context().Value(retryAfterKey{}).(*time.Duration); ok {
+			*d = parseRetryAfter(resp.Header)
+		}
+	}
+	return resp, err
+}
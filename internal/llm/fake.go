@@ -0,0 +1,286 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	FakeModeReplay = "replay"
+	FakeModeRecord = "record"
+
+	fakeModelName = "fake"
+)
+
+var errFakeRateLimited = fmt.Errorf("fake provider: 429 %w", ErrRateLimited)
+
+// fakeDefaultContent is returned in replay mode when no fixture matches. It
+// carries the fields every built-in evaluator and the suggester read, so the
+// pipeline runs end-to-end without fixtures. Rubric evaluators need recorded
+// fixtures since their score keys depend on the rubric.
+const fakeDefaultContent = `{
+  "response_quality": 0.8,
+  "helpfulness": 0.8,
+  "factuality": 0.8,
+  "selection_accuracy": 0.8,
+  "parameter_accuracy": 0.8,
+  "coherence": 0.8,
+  "consistency": 0.8,
+  "overall": 0.8,
+  "confidence": 0.8,
+  "hallucinated_params": [],
+  "context_losses": [],
+  "contradictions": [],
+  "issues": [],
+  "reasoning": "fake provider default response",
+  "suggestion_type": "prompt",
+  "target": "system_prompt",
+  "suggestion": "fake provider default suggestion",
+  "rationale": "fake provider default response"
+}`
+
+// FakeOptions configures a FakeProvider.
+type FakeOptions struct {
+	Mode          string   // FakeModeReplay (default) or FakeModeRecord
+	FixtureDir    string   // one <request hash>.json file per recorded response
+	Upstream      Provider // real provider called in record mode
+	Strict        bool     // fail replay misses instead of returning the default response
+	Latency       time.Duration
+	RateLimitRate float64 // fraction of calls failing with a rate-limit error
+	MalformedRate float64 // fraction of responses returned as truncated JSON
+	Seed          int64   // seeds fault injection so runs are reproducible
+}
+
+// FakeProvider replays recorded responses keyed by a hash of the request,
+// or records them from an upstream provider. It can inject latency,
+// rate-limit errors and malformed JSON to exercise failure handling.
+type FakeProvider struct {
+	opts FakeOptions
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+type fakeFixture struct {
+	Request  *CompletionRequest `json:"request"`
+	Response fakeResponse       `json:"response"`
+}
+
+type fakeResponse struct {
+	Content      string `json:"content"`
+	FinishReason string `json:"finish_reason"`
+	ModelName    string `json:"model_name"`
+	Usage        Usage  `json:"usage"`
+}
+
+func NewFakeProvider(opts FakeOptions) (*FakeProvider, error) {
+	if opts.Mode == "" {
+		opts.Mode = FakeModeReplay
+	}
+
+	switch opts.Mode {
+	case FakeModeReplay:
+	case FakeModeRecord:
+		if opts.Upstream == nil {
+			return nil, fmt.Errorf("fake provider: record mode requires an upstream provider")
+		}
+		if opts.FixtureDir == "" {
+			return nil, fmt.Errorf("fake provider: record mode requires a fixture dir")
+		}
+		if err := os.MkdirAll(opts.FixtureDir, 0o755); err != nil {
+			return nil, fmt.Errorf("fake provider: create fixture dir: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("fake provider: unknown mode %q", opts.Mode)
+	}
+
+	return &FakeProvider{
+		opts: opts,
+		rng:  rand.New(rand.NewSource(opts.Seed)),
+	}, nil
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	start := time.Now()
+
+	if p.opts.Latency > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(p.opts.Latency):
+		}
+	}
+
+	if p.roll(p.opts.RateLimitRate) {
+		return nil, errFakeRateLimited
+	}
+
+	key, err := RequestHash(req)
+	if err != nil {
+		return nil, fmt.Errorf("fake provider: %w", err)
+	}
+
+	var resp *CompletionResponse
+	if p.opts.Mode == FakeModeRecord {
+		resp, err = p.record(ctx, key, req)
+	} else {
+		resp, err = p.replay(key, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if p.roll(p.opts.MalformedRate) && len(resp.Content) > 1 {
+		malformed := *resp
+		malformed.Content = resp.Content[:len(resp.Content)/2]
+		resp = &malformed
+	}
+
+	resp.Latency = time.Since(start)
+	return resp, nil
+}
+
+// roll reports whether an injected fault with the given rate fires.
+func (p *FakeProvider) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.rng.Float64() < rate
+}
+
+func (p *FakeProvider) replay(key string, req *CompletionRequest) (*CompletionResponse, error) {
+	if p.opts.FixtureDir != "" {
+		data, err := os.ReadFile(p.fixturePath(key))
+		if err == nil {
+			var fixture fakeFixture
+			if err := json.Unmarshal(data, &fixture); err != nil {
+				return nil, fmt.Errorf("fake provider: parse fixture %s: %w", key, err)
+			}
+			return &CompletionResponse{
+				Content:      fixture.Response.Content,
+				FinishReason: fixture.Response.FinishReason,
+				ModelName:    fixture.Response.ModelName,
+				Usage:        fixture.Response.Usage,
+			}, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("fake provider: read fixture %s: %w", key, err)
+		}
+	}
+
+	if p.opts.Strict {
+		return nil, fmt.Errorf("fake provider: no fixture for request %s", key)
+	}
+
+	model := req.Model
+	if model == "" {
+		model = fakeModelName
+	}
+
+	promptTokens := 0
+	for _, m := range req.Messages {
+		promptTokens += len(m.Content) / 4
+	}
+	completionTokens := len(fakeDefaultContent) / 4
+
+	return &CompletionResponse{
+		Content:      fakeDefaultContent,
+		FinishReason: "stop",
+		ModelName:    model,
+		Usage: Usage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			TotalTokens:      promptTokens + completionTokens,
+		},
+	}, nil
+}
+
+func (p *FakeProvider) record(ctx context.Context, key string, req *CompletionRequest) (*CompletionResponse, error) {
+	resp, err := p.opts.Upstream.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(fakeFixture{
+		Request: req,
+		Response: fakeResponse{
+			Content:      resp.Content,
+			FinishReason: resp.FinishReason,
+			ModelName:    resp.ModelName,
+			Usage:        resp.Usage,
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("fake provider: marshal fixture: %w", err)
+	}
+
+	// Write then rename so concurrent workers never replay a partial file.
+	tmp, err := os.CreateTemp(p.opts.FixtureDir, key+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("fake provider: write fixture: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("fake provider: write fixture: %w", err)
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), p.fixturePath(key)); err != nil {
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("fake provider: write fixture: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (p *FakeProvider) fixturePath(key string) string {
+	return filepath.Join(p.opts.FixtureDir, key+".json")
+}
+
+// requestHashVersion is part of every request hash. Bump it when the hashed
+// fields change so fixtures and cache entries keyed the old way stop
+// matching rather than being replayed for different requests.
+const requestHashVersion = 1
+
+// requestHashKey lists what identifies a request. Fields that only shape how
+// the response is requested, like Schema, are left out so adding them does
+// not invalidate recorded fixtures; the response cache adds them to its key.
+type requestHashKey struct {
+	Version     int       `json:"version"`
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	Temperature float64   `json:"temperature"`
+	MaxTokens   int       `json:"max_tokens"`
+}
+
+// RequestHash identifies a completion request by its model, messages and
+// sampling parameters: the first 16 hex chars of their SHA-256.
+func RequestHash(req *CompletionRequest) (string, error) {
+	data, err := json.Marshal(requestHashKey{
+		Version:     requestHashVersion,
+		Model:       req.Model,
+		Messages:    req.Messages,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("hash request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16], nil
+}
@@ -0,0 +1,151 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Route names a provider and, optionally, the model to request from it.
+type Route struct {
+	Provider string `yaml:"provider"`
+	Model    string `yaml:"model"`
+}
+
+func (r Route) String() string {
+	if r.Model == "" {
+		return r.Provider
+	}
+	return r.Provider + ":" + r.Model
+}
+
+// ParseRoute parses a provider[:model] entry, e.g.
+// "anthropic:claude-3-haiku-20240307" or "ollama". Everything after the
+// first colon is the model, so model names may contain colons.
+func ParseRoute(s string) (Route, error) {
+	provider, model, _ := strings.Cut(strings.TrimSpace(s), ":")
+	if provider == "" || strings.Contains(provider, ",") {
+		return Route{}, fmt.Errorf("invalid route %q: want provider[:model]", s)
+	}
+	return Route{Provider: provider, Model: model}, nil
+}
+
+// ParseRoutes parses a comma-separated list of provider[:model] entries.
+func ParseRoutes(s string) ([]Route, error) {
+	var routes []Route
+	for _, entry := range strings.Split(s, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		r, err := ParseRoute(entry)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, r)
+	}
+	return routes, nil
+}
+
+// Chain returns the routes to try for a caller pinned to primary: primary
+// first, then fallback, or the client's configured fallback chain when
+// fallback is nil. Repeated providers are dropped.
+func (c *Client) Chain(primary Route, fallback []Route) []Route {
+	if fallback == nil {
+		fallback = c.fallback
+	}
+
+	routes := []Route{primary}
+	seen := map[string]bool{primary.Provider: true}
+	for _, r := range fallback {
+		if seen[r.Provider] {
+			continue
+		}
+		seen[r.Provider] = true
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+// CompleteWithFallback tries routes in order until one succeeds, skipping
+// providers whose circuit breaker is open. Each attempt gets the client
+// timeout. A route without a model uses the request's model on the first
+// route and the provider's default model on the rest, since model names
+// rarely carry over between providers. The response names the provider
+// that served it.
+func (c *Client) CompleteWithFallback(ctx context.Context, routes []Route, req *CompletionRequest) (*CompletionResponse, error) {
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("no providers to try")
+	}
+
+	var errs []error
+	for i, route := range routes {
+		attempt := *req
+		if route.Model != "" {
+			attempt.Model = route.Model
+		} else if i > 0 {
+			attempt.Model = ""
+		}
+
+		resp, err := c.CompleteWithProvider(ctx, route.Provider, &attempt)
+		if err == nil {
+			return resp, nil
+		}
+		errs = append(errs, &ProviderError{Provider: route.Provider, Err: err})
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if len(errs) == 1 {
+		return nil, errs[0]
+	}
+	return nil, fmt.Errorf("all providers failed: %w", errors.Join(errs...))
+}
+
+// ProviderError is the failure of one provider in a fallback chain.
+type ProviderError struct {
+	Provider string
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	return e.Provider + ": " + e.Err.Error()
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// FailedProvider returns the provider whose failure err reports: the last
+// one called when every provider in the chain failed. Providers skipped by
+// an open circuit breaker are only named when none was called, and ""
+// when err comes from no provider.
+func FailedProvider(err error) string {
+	var failed, skipped string
+	walkProviderErrors(err, func(e *ProviderError) {
+		if errors.Is(e.Err, ErrCircuitOpen) {
+			skipped = e.Provider
+		} else {
+			failed = e.Provider
+		}
+	})
+	if failed != "" {
+		return failed
+	}
+	return skipped
+}
+
+func walkProviderErrors(err error, fn func(*ProviderError)) {
+	switch e := err.(type) {
+	case *ProviderError:
+		fn(e)
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			walkProviderErrors(inner, fn)
+		}
+	case interface{ Unwrap() error }:
+		walkProviderErrors(e.Unwrap(), fn)
+	}
+}
@@ -0,0 +1,206 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrThrottled is returned when a call would have to wait for its
+// provider's limits past the caller's deadline. It wraps ErrRateLimited.
+var ErrThrottled = fmt.Errorf("client-side %w: wait exceeds deadline", ErrRateLimited)
+
+// Limits caps the load sent to one provider. Zero fields are unlimited.
+type Limits struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+	MaxInFlight       int
+}
+
+// ParseLimits parses per-provider limits, e.g.
+// "ollama:concurrency=2;openrouter:rpm=20,tpm=100000". Keys are rpm, tpm
+// and concurrency.
+func ParseLimits(s string) (map[string]Limits, error) {
+	limits := make(map[string]Limits)
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		provider, spec, ok := strings.Cut(entry, ":")
+		provider = strings.TrimSpace(provider)
+		if !ok || provider == "" {
+			return nil, fmt.Errorf("invalid limits %q: want provider:key=value,...", entry)
+		}
+
+		var l Limits
+		for _, kv := range strings.Split(spec, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(kv), "=")
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if !ok || err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid limit %q for %s: want key=non-negative integer", kv, provider)
+			}
+			switch strings.TrimSpace(key) {
+			case "rpm":
+				l.RequestsPerMinute = n
+			case "tpm":
+				l.TokensPerMinute = n
+			case "concurrency":
+				l.MaxInFlight = n
+			default:
+				return nil, fmt.Errorf("unknown limit %q for %s (want rpm, tpm or concurrency)", key, provider)
+			}
+		}
+		limits[provider] = l
+	}
+	return limits, nil
+}
+
+// limiter enforces one provider's Limits across all goroutines sharing the
+// client. Requests and tokens are token buckets refilled continuously and
+// holding up to a minute's allowance.
+type limiter struct {
+	requests *bucket
+	tokens   *bucket
+	slots    chan struct{}
+}
+
+func newLimiter(l Limits) *limiter {
+	lim := &limiter{
+		requests: newBucket(l.RequestsPerMinute),
+		tokens:   newBucket(l.TokensPerMinute),
+	}
+	if l.MaxInFlight > 0 {
+		lim.slots = make(chan struct{}, l.MaxInFlight)
+	}
+	return lim
+}
+
+// acquire waits until a request estimated at tokens may be sent. The
+// returned function must be called with the tokens actually used (0 if
+// unknown or the call failed) once the request finishes. A nil limiter
+// never waits.
+func (l *limiter) acquire(ctx context.Context, tokens int) (func(used int), error) {
+	if l == nil {
+		return func(int) {}, nil
+	}
+	if err := l.requests.take(ctx, 1); err != nil {
+		return nil, err
+	}
+	if err := l.tokens.take(ctx, tokens); err != nil {
+		l.requests.refund(1)
+		return nil, err
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			l.requests.refund(1)
+			l.tokens.refund(tokens)
+			return nil, ctx.Err()
+		}
+	}
+
+	return func(used int) {
+		if l.slots != nil {
+			<-l.slots
+		}
+		if used > 0 {
+			l.tokens.refund(tokens - used)
+		} else {
+			l.tokens.refund(tokens)
+		}
+	}, nil
+}
+
+// bucket is a token bucket refilled at perMinute per minute. A nil bucket
+// never waits.
+type bucket struct {
+	capacity float64
+	rate     float64 // per second
+
+	mu     sync.Mutex
+	level  float64
+	filled time.Time
+}
+
+func newBucket(perMinute int) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{
+		capacity: float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		level:    float64(perMinute),
+		filled:   time.Now(),
+	}
+}
+
+// take removes n tokens, waiting for them to refill. Requests larger than
+// the bucket go through once it is full. It fails at once with
+// ErrThrottled if the wait would outlast ctx's deadline.
+func (b *bucket) take(ctx context.Context, n int) error {
+	if b == nil || n <= 0 {
+		return nil
+	}
+	need := math.Min(float64(n), b.capacity)
+
+	for {
+		b.mu.Lock()
+		b.refill()
+		if b.level >= need {
+			b.level -= float64(n)
+			b.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((need - b.level) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return ErrThrottled
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// refund returns n tokens, or takes -n more when n is negative. The level
+// may go below zero, delaying later callers, when usage exceeded the
+// estimate.
+func (b *bucket) refund(n int) {
+	if b == nil || n == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	b.level = math.Min(b.level+float64(n), b.capacity)
+}
+
+func (b *bucket) refill() {
+	now := time.Now()
+	b.level = math.Min(b.level+now.Sub(b.filled).Seconds()*b.rate, b.capacity)
+	b.filled = now
+}
+
+// estimateTokens guesses a request's token use before sending it: about
+// four characters per prompt token plus the completion allowance.
+func estimateTokens(req *CompletionRequest) int {
+	chars := 0
+	for _, m := range req.Messages {
+		chars += len(m.Content)
+	}
+	return chars/4 + req.MaxTokens
+}
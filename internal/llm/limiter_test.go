@@ -0,0 +1,162 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLimits(t *testing.T) {
+	got, err := ParseLimits(" ollama:concurrency=2 ; openrouter:rpm=20, tpm=100000;")
+	if err != nil {
+		t.Fatalf("ParseLimits: %v", err)
+	}
+	want := map[string]Limits{
+		"ollama":     {MaxInFlight: 2},
+		"openrouter": {RequestsPerMinute: 20, TokensPerMinute: 100000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLimits = %+v, want %+v", got, want)
+	}
+
+	if got, err := ParseLimits(""); err != nil || len(got) != 0 {
+		t.Errorf("ParseLimits(\"\") = %v, %v; want no limits", got, err)
+	}
+
+	for _, bad := range []string{"ollama", ":rpm=1", "ollama:rpm", "ollama:rpm=-1", "ollama:rpm=x", "ollama:qps=3"} {
+		if _, err := ParseLimits(bad); err == nil {
+			t.Errorf("ParseLimits(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestLimiterNil(t *testing.T) {
+	var l *limiter
+	done, err := l.acquire(context.Background(), 1000)
+	if err != nil {
+		t.Fatalf("nil limiter: %v", err)
+	}
+	done(10)
+}
+
+func TestLimiterRequestsPerMinute(t *testing.T) {
+	// 600 rpm refills one request every 100ms.
+	l := newLimiter(Limits{RequestsPerMinute: 600})
+	l.requests.level = 1
+
+	done, err := l.acquire(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+	done(0)
+
+	start := time.Now()
+	done, err = l.acquire(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("second acquire: %v", err)
+	}
+	done(0)
+	if waited := time.Since(start); waited < 50*time.Millisecond {
+		t.Errorf("second acquire waited %v, want about 100ms for the bucket to refill", waited)
+	}
+}
+
+func TestLimiterThrottlesPastDeadline(t *testing.T) {
+	l := newLimiter(Limits{RequestsPerMinute: 1})
+	done, err := l.acquire(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+	done(0)
+
+	// The next request is a minute away.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err = l.acquire(ctx, 0)
+	if !errors.Is(err, ErrThrottled) || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("acquire error = %v, want ErrThrottled wrapping ErrRateLimited", err)
+	}
+	if waited := time.Since(start); waited > 100*time.Millisecond {
+		t.Errorf("acquire waited %v before failing, want it to fail at once", waited)
+	}
+}
+
+func TestLimiterTokens(t *testing.T) {
+	l := newLimiter(Limits{RequestsPerMinute: 100, TokensPerMinute: 1000})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	done, err := l.acquire(ctx, 800)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	// The estimate's unused tokens are refunded, and a failed call's too.
+	done(300)
+	done, err = l.acquire(ctx, 600)
+	if err != nil {
+		t.Fatalf("acquire after a refund: %v", err)
+	}
+	done(0)
+
+	// 300 used of 1000 tokens a minute leaves room for 700.
+	if _, err := l.acquire(ctx, 800); !errors.Is(err, ErrThrottled) {
+		t.Fatalf("acquire past the token budget = %v, want ErrThrottled", err)
+	}
+	// The request it took back is refunded.
+	if l.requests.level < 98 {
+		t.Errorf("request bucket at %.0f, want the throttled request refunded", l.requests.level)
+	}
+}
+
+func TestLimiterConcurrency(t *testing.T) {
+	l := newLimiter(Limits{MaxInFlight: 1})
+	done, err := l.acquire(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire with no free slot = %v, want a deadline error", err)
+	}
+
+	done(0)
+	done, err = l.acquire(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	done(0)
+}
+
+func TestClientThrottled(t *testing.T) {
+	p := answering("p", "ok")
+	c := newTestClient(0, 0, p)
+	c.limiters["p"] = newLimiter(Limits{RequestsPerMinute: 1})
+	c.timeout = 100 * time.Millisecond
+
+	if _, err := c.CompleteWithProvider(context.Background(), "p", &CompletionRequest{}); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	_, err := c.CompleteWithProvider(context.Background(), "p", &CompletionRequest{})
+	if !errors.Is(err, ErrThrottled) {
+		t.Fatalf("second call error = %v, want ErrThrottled", err)
+	}
+	if n := p.Calls(); n != 1 {
+		t.Errorf("provider called %d times, want the throttled call not sent", n)
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	req := &CompletionRequest{
+		Messages:  []Message{{Content: strings.Repeat("a", 400)}, {Content: strings.Repeat("b", 40)}},
+		MaxTokens: 500,
+	}
+	if got := estimateTokens(req); got != 610 {
+		t.Errorf("estimateTokens = %d, want 610", got)
+	}
+}
@@ -61,8 +61,15 @@ func (p *OllamaProvider) Complete(ctx context.Context, req *CompletionRequest) (
 		},
 	}
 
-	if req.JSONMode {
-		apiReq.Format = "json"
+	switch {
+	case req.Schema != nil:
+		format, err := json.Marshal(req.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("marshal schema: %w", err)
+		}
+		apiReq.Format = format
+	case req.JSONMode:
+		apiReq.Format = json.RawMessage(`"json"`)
 	}
 
 	body, err := json.Marshal(apiReq)
@@ -78,7 +85,7 @@ func (p *OllamaProvider) Complete(ctx context.Context, req *CompletionRequest) (
 
 	resp, err := p.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
+		return nil, fmt.Errorf("do request: %w", transportError(err))
 	}
 	defer resp.Body.Close()
 
@@ -88,7 +95,7 @@ func (p *OllamaProvider) Complete(ctx context.Context, req *CompletionRequest) (
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("ollama error %d: %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(p.Name(), resp.StatusCode, string(respBody), parseRetryAfter(resp.Header))
 	}
 
 	var apiResp ollamaResponse
@@ -113,7 +120,7 @@ type ollamaRequest struct {
 	Model    string          `json:"model"`
 	Messages []ollamaMessage `json:"messages"`
 	Stream   bool            `json:"stream"`
-	Format   string          `json:"format,omitempty"`
+	Format   json.RawMessage `json:"format,omitempty"` // "json" or a JSON schema
 	Options  ollamaOptions   `json:"options,omitempty"`
 }
 
@@ -1,20 +1,28 @@
 package llm
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	openai "github.com/sashabaranov/go-openai"
 )
 
 type OpenAIProvider struct {
-	client *openai.Client
+	chat *openAIChat
 }
 
 func NewOpenAIProvider(apiKey string) *OpenAIProvider {
 	return &OpenAIProvider{
-		client: openai.NewClient(apiKey),
+		chat: newOpenAIChat("openai", apiKey, openai.DefaultConfig(apiKey).BaseURL),
 	}
 }
 
@@ -50,13 +58,7 @@ func (p *OpenAIProvider) Complete(ctx context.Context, req *CompletionRequest) (
 		Temperature: float32(req.Temperature),
 	}
 
-	if req.JSONMode {
-		chatReq.ResponseFormat = &openai.ChatCompletionResponseFormat{
-			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
-		}
-	}
-
-	resp, err := p.client.CreateChatCompletion(ctx, chatReq)
+	resp, err := p.chat.complete(ctx, chatReq, req.Schema, req.JSONMode)
 	if err != nil {
 		return nil, fmt.Errorf("create completion: %w", err)
 	}
@@ -77,3 +79,192 @@ func (p *OpenAIProvider) Complete(ctx context.Context, req *CompletionRequest) (
 		Latency: time.Since(start),
 	}, nil
 }
+
+// openAIChat calls an OpenAI-compatible chat completions API.
+type openAIChat struct {
+	provider   string
+	client     *openai.Client
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+
+	noSchema sync.Map // models that rejected the json_schema format
+}
+
+func newOpenAIChat(provider, apiKey, baseURL string) *openAIChat {
+	httpClient := newOpenAIHTTPClient()
+	config := openai.DefaultConfig(apiKey)
+	config.BaseURL = baseURL
+	config.HTTPClient = httpClient
+
+	return &openAIChat{
+		provider:   provider,
+		client:     openai.NewClientWithConfig(config),
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+	}
+}
+
+// complete sends chatReq. Given a schema, the response is constrained to it
+// with the json_schema response format; a model that rejects the format is
+// asked in JSON mode instead, from then on. Without one, jsonMode asks for
+// JSON mode.
+func (c *openAIChat) complete(ctx context.Context, chatReq openai.ChatCompletionRequest, schema *Schema, jsonMode bool) (openai.ChatCompletionResponse, error) {
+	if schema != nil {
+		if _, rejected := c.noSchema.Load(chatReq.Model); !rejected {
+			resp, err := c.completeWithSchema(ctx, chatReq, schema)
+			var apiErr *APIError
+			if err == nil || !errors.As(err, &apiErr) || !schemaRejected(apiErr) {
+				return resp, err
+			}
+			c.noSchema.Store(chatReq.Model, true)
+		}
+		jsonMode = true
+	}
+
+	if jsonMode {
+		chatReq.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+	}
+
+	ctx, retryAfter := withRetryAfter(ctx)
+	resp, err := c.client.CreateChatCompletion(ctx, chatReq)
+	if err != nil {
+		return resp, openAIError(c.provider, err, *retryAfter)
+	}
+	return resp, nil
+}
+
+// openAISchemaRequest is a chat request with the json_schema response
+// format, which the go-openai release in use cannot express.
+type openAISchemaRequest struct {
+	openai.ChatCompletionRequest
+	ResponseFormat openAISchemaFormat `json:"response_format"`
+}
+
+type openAISchemaFormat struct {
+	Type       string `json:"type"`
+	JSONSchema struct {
+		Name   string         `json:"name"`
+		Schema map[string]any `json:"schema"`
+		Strict bool           `json:"strict"`
+	} `json:"json_schema"`
+}
+
+// completeWithSchema sends chatReq with a strict json_schema response
+// format, calling the API directly.
+func (c *openAIChat) completeWithSchema(ctx context.Context, chatReq openai.ChatCompletionRequest, schema *Schema) (openai.ChatCompletionResponse, error) {
+	var resp openai.ChatCompletionResponse
+
+	apiReq := openAISchemaRequest{ChatCompletionRequest: chatReq}
+	apiReq.ResponseFormat.Type = "json_schema"
+	apiReq.ResponseFormat.JSONSchema.Name = "response"
+	apiReq.ResponseFormat.JSONSchema.Schema = strictSchema(schema)
+	apiReq.ResponseFormat.JSONSchema.Strict = true
+
+	body, err := json.Marshal(apiReq)
+	if err != nil {
+		return resp, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return resp, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return resp, fmt.Errorf("do request: %w", transportError(err))
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, fmt.Errorf("read response: %w", err)
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		message := string(respBody)
+		var errResp openai.ErrorResponse
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Error != nil && errResp.Error.Message != "" {
+			message = errResp.Error.Message
+		}
+		return resp, newAPIError(c.provider, httpResp.StatusCode, message, parseRetryAfter(httpResp.Header))
+	}
+
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return resp, fmt.Errorf("unmarshal response: %w", err)
+	}
+	return resp, nil
+}
+
+// schemaRejected reports whether a request failed because the model does
+// not support the json_schema response format.
+func schemaRejected(err *APIError) bool {
+	message := strings.ToLower(err.Message)
+	return err.StatusCode == http.StatusBadRequest &&
+		(strings.Contains(message, "response_format") || strings.Contains(message, "json_schema"))
+}
+
+// strictSchema converts s to the form strict structured output accepts:
+// every object closed to other properties and requiring all of its own,
+// with the optional ones made nullable. Ranges are left out, since not
+// every model accepts them; Normalize still applies them.
+func strictSchema(s *Schema) map[string]any {
+	out := map[string]any{"type": s.Type}
+	switch s.Type {
+	case "object":
+		names := make([]string, 0, len(s.Properties))
+		properties := make(map[string]any, len(s.Properties))
+		for name, prop := range s.Properties {
+			names = append(names, name)
+			p := strictSchema(prop)
+			if !containsName(s.Required, name) {
+				p["type"] = []string{prop.Type, "null"}
+			}
+			properties[name] = p
+		}
+		sort.Strings(names)
+		out["properties"] = properties
+		out["required"] = names
+		out["additionalProperties"] = false
+	case "array":
+		if s.Items != nil {
+			out["items"] = strictSchema(s.Items)
+		}
+	}
+	return out
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// newOpenAIHTTPClient returns the HTTP client for go-openai based
+// providers, recording Retry-After since the SDK's errors drop headers.
+func newOpenAIHTTPClient() *http.Client {
+	return &http.Client{Transport: retryAfterTransport{base: http.DefaultTransport}}
+}
+
+// openAIError converts an error from the go-openai client into an
+// APIError, or classifies it as a transport failure.
+func openAIError(provider string, err error, retryAfter time.Duration) error {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return newAPIError(provider, apiErr.HTTPStatusCode, apiErr.Message, retryAfter)
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) && reqErr.HTTPStatusCode > 0 {
+		return newAPIError(provider, reqErr.HTTPStatusCode, reqErr.Error(), retryAfter)
+	}
+	return transportError(err)
+}
@@ -0,0 +1,172 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestStrictSchema(t *testing.T) {
+	got := strictSchema(judgeSchema())
+
+	want := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"score":     map[string]any{"type": "number"},
+			"reasoning": map[string]any{"type": "string"},
+			"passed":    map[string]any{"type": []string{"boolean", "null"}},
+			"turn_id":   map[string]any{"type": []string{"integer", "null"}},
+			"issues": map[string]any{
+				"type": []string{"array", "null"},
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"type":       map[string]any{"type": "string"},
+						"confidence": map[string]any{"type": []string{"number", "null"}},
+					},
+					"required":             []string{"confidence", "type"},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"issues", "passed", "reasoning", "score", "turn_id"},
+		"additionalProperties": false,
+	}
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.MarshalIndent(got, "", "  ")
+		t.Errorf("strictSchema =\n%s", gotJSON)
+	}
+}
+
+// chatServer is an OpenAI-compatible API recording the response_format of
+// each request. Models in rejectSchema fail json_schema requests.
+type chatServer struct {
+	*httptest.Server
+	rejectSchema map[string]bool
+
+	mu      sync.Mutex
+	formats []string
+}
+
+func newChatServer(t *testing.T, rejectSchema ...string) *chatServer {
+	s := &chatServer{rejectSchema: make(map[string]bool)}
+	for _, m := range rejectSchema {
+		s.rejectSchema[m] = true
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model          string `json:"model"`
+			ResponseFormat *struct {
+				Type       string `json:"type"`
+				JSONSchema *struct {
+					Name   string         `json:"name"`
+					Schema map[string]any `json:"schema"`
+					Strict bool           `json:"strict"`
+				} `json:"json_schema"`
+			} `json:"response_format"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+
+		format := "text"
+		if req.ResponseFormat != nil {
+			format = req.ResponseFormat.Type
+		}
+		s.mu.Lock()
+		s.formats = append(s.formats, format)
+		s.mu.Unlock()
+
+		if format == "json_schema" {
+			if js := req.ResponseFormat.JSONSchema; js == nil || !js.Strict || js.Schema["additionalProperties"] != false {
+				t.Errorf("json_schema format = %+v, want a strict closed schema", js)
+			}
+			if s.rejectSchema[req.Model] {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":{"message":"Invalid parameter: 'response_format' of type 'json_schema' is not supported with this model.","type":"invalid_request_error"}}`))
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"c","object":"chat.completion","model":"` + req.Model + `","choices":[{"index":0,"message":{"role":"assistant","content":"{\"score\":0.5,\"reasoning\":\"r\"}"},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *chatServer) Formats() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.formats...)
+}
+
+func newTestOpenAI(baseURL string) *OpenAIProvider {
+	return &OpenAIProvider{chat: newOpenAIChat("openai", "test-key", baseURL)}
+}
+
+func TestOpenAIJSONSchema(t *testing.T) {
+	srv := newChatServer(t)
+	p := newTestOpenAI(srv.URL)
+
+	resp, err := p.Complete(context.Background(), &CompletionRequest{Model: "gpt-4o-mini", Schema: judgeSchema()})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if resp.Content != `{"score":0.5,"reasoning":"r"}` || resp.Usage.TotalTokens != 15 || resp.FinishReason != "stop" {
+		t.Errorf("response = %+v", resp)
+	}
+	if got := srv.Formats(); !reflect.DeepEqual(got, []string{"json_schema"}) {
+		t.Errorf("formats = %v, want json_schema", got)
+	}
+}
+
+func TestOpenAIJSONSchemaFallback(t *testing.T) {
+	srv := newChatServer(t, "old-model")
+	p := newTestOpenAI(srv.URL)
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.Complete(context.Background(), &CompletionRequest{Model: "old-model", Schema: judgeSchema()}); err != nil {
+			t.Fatalf("Complete %d: %v", i+1, err)
+		}
+	}
+	if _, err := p.Complete(context.Background(), &CompletionRequest{Model: "gpt-4o-mini", Schema: judgeSchema()}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if _, err := p.Complete(context.Background(), &CompletionRequest{Model: "gpt-4o-mini"}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	// The rejecting model is asked in JSON mode once it has refused, the
+	// other keeps json_schema, and free text requests set no format.
+	want := []string{"json_schema", "json_object", "json_object", "json_schema", "text"}
+	if got := srv.Formats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("formats = %v, want %v", got, want)
+	}
+}
+
+func TestOpenAIJSONSchemaErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"Rate limit reached"}}`))
+	}))
+	defer srv.Close()
+
+	_, err := newTestOpenAI(srv.URL).Complete(context.Background(), &CompletionRequest{Schema: judgeSchema()})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("error = %v, want a rate limited APIError", err)
+	}
+	if apiErr.Message != "Rate limit reached" || apiErr.RetryAfter.Seconds() != 7 {
+		t.Errorf("APIError = %+v, want the message and a 7s Retry-After", apiErr)
+	}
+	if schemaRejected(apiErr) {
+		t.Error("a rate limit was taken for the schema being rejected")
+	}
+}
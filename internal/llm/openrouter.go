@@ -9,17 +9,14 @@ import (
 )
 
 type OpenRouterProvider struct {
-	client          *openai.Client
+	chat            *openAIChat
 	model           string
 	enableReasoning bool
 }
 
 func NewOpenRouterProvider(apiKey, model string, enableReasoning bool) *OpenRouterProvider {
-	config := openai.DefaultConfig(apiKey)
-	config.BaseURL = "https://openrouter.ai/api/v1"
-
 	return &OpenRouterProvider{
-		client:          openai.NewClientWithConfig(config),
+		chat:            newOpenAIChat("openrouter", apiKey, "https://openrouter.ai/api/v1"),
 		model:           model,
 		enableReasoning: enableReasoning,
 	}
@@ -60,37 +57,11 @@ func (p *OpenRouterProvider) Complete(ctx context.Context, req *CompletionReques
 		Temperature: float32(req.Temperature),
 	}
 
-	if req.JSONMode {
-		chatReq.ResponseFormat = &openai.ChatCompletionResponseFormat{
-			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
-		}
-	}
-
 	// Note: Reasoning mode is passed via custom parameters that OpenRouter supports
 	// The go-openai library doesn't directly support this, but OpenRouter will
 	// recognize it from the model capabilities
 
-	var resp openai.ChatCompletionResponse
-	var err error
-
-	// Implement exponential backoff for rate limits
-	maxRetries := 3
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		resp, err = p.client.CreateChatCompletion(ctx, chatReq)
-		if err == nil {
-			break
-		}
-
-		// Check if it's a rate limit error (429)
-		if attempt < maxRetries && isRateLimitError(err) {
-			waitTime := time.Duration(1<<uint(attempt)) * time.Second // 1s, 2s, 4s
-			time.Sleep(waitTime)
-			continue
-		}
-
-		return nil, fmt.Errorf("create completion (attempt %d): %w", attempt+1, err)
-	}
-
+	resp, err := p.chat.complete(ctx, chatReq, req.Schema, req.JSONMode)
 	if err != nil {
 		return nil, fmt.Errorf("create completion: %w", err)
 	}
@@ -111,22 +82,3 @@ func (p *OpenRouterProvider) Complete(ctx context.Context, req *CompletionReques
 		Latency: time.Since(start),
 	}, nil
 }
-
-// isRateLimitError checks if the error is a rate limit error
-func isRateLimitError(err error) bool {
-	if err == nil {
-		return false
-	}
-	// Check for common rate limit error indicators
-	errStr := err.Error()
-	return contains(errStr, "429") || contains(errStr, "rate limit") || contains(errStr, "Too Many Requests")
-}
-
-func contains(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,33 @@
+package llm
+
+// prices is the cost per 1K tokens (prompt, completion) of hosted models.
+var prices = map[string]struct{ prompt, completion float64 }{
+	"gpt-4o":            {0.0025, 0.010},
+	"gpt-4o-mini":       {0.00015, 0.0006},
+	"gpt-4":             {0.03, 0.06},
+	"gpt-4-turbo":       {0.01, 0.03},
+	"gpt-3.5-turbo":     {0.0005, 0.0015},
+	"claude-3-opus":     {0.015, 0.075},
+	"claude-3-sonnet":   {0.003, 0.015},
+	"claude-3-haiku":    {0.00025, 0.00125},
+	"claude-3-5-sonnet": {0.003, 0.015},
+}
+
+// EstimateCost estimates the cost in USD of a call to model. Unknown
+// models, including self-hosted ones, cost nothing.
+func EstimateCost(model string, promptTokens, completionTokens int) float64 {
+	p, ok := prices[model]
+	if !ok {
+		return 0
+	}
+	return float64(promptTokens)/1000*p.prompt + float64(completionTokens)/1000*p.completion
+}
+
+// Cost is the estimated cost of the response: nothing when it was served
+// from the response cache.
+func (r *CompletionResponse) Cost() float64 {
+	if r.Cached {
+		return 0
+	}
+	return EstimateCost(r.ModelName, r.Usage.PromptTokens, r.Usage.CompletionTokens)
+}
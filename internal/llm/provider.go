@@ -3,9 +3,13 @@ package llm
 import (
 	"context"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/saisaravanan/healing-eval/internal/config"
+	"github.com/saisaravanan/healing-eval/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Provider interface {
@@ -19,6 +23,10 @@ type CompletionRequest struct {
 	MaxTokens   int
 	Temperature float64
 	JSONMode    bool
+	// Schema asks for a JSON response of this shape. Providers that support
+	// structured output constrain the response to it; the others fall back
+	// to JSONMode, so callers must still validate.
+	Schema *Schema
 }
 
 type Message struct {
@@ -30,6 +38,8 @@ type CompletionResponse struct {
 	Content      string
 	FinishReason string
 	ModelName    string
+	Provider     string // provider that served the request
+	Cached       bool   // served from the response cache
 	Usage        Usage
 	Latency      time.Duration
 }
@@ -42,8 +52,13 @@ type Usage struct {
 
 type Client struct {
 	providers       map[string]Provider
+	breakers        map[string]*breaker
+	limiters        map[string]*limiter
+	cache           Cache // nil when caching is off
 	defaultProvider string
+	fallback        []Route // tried in order after the default provider
 	timeout         time.Duration
+	retry           RetryPolicy
 }
 
 func NewClient(cfg *config.LLMConfig) (*Client, error) {
@@ -69,6 +84,35 @@ func NewClient(cfg *config.LLMConfig) (*Client, error) {
 		c.providers["openrouter"] = NewOpenRouterProvider(cfg.OpenRouterAPIKey, cfg.OpenRouterModel, cfg.OpenRouterReasoning)
 	}
 
+	c.retry = RetryPolicy{
+		MaxRetries: cfg.MaxRetries,
+		BaseDelay:  cfg.RetryBaseDelay,
+		MaxDelay:   cfg.RetryMaxDelay,
+	}
+
+	if cfg.DefaultProvider == "fake" {
+		// The provider being recorded is called directly, so it retries
+		// on its own.
+		var upstream Provider
+		if p, ok := c.providers[cfg.FakeRecordProvider]; ok {
+			upstream = WithRetry(p, c.retry)
+		}
+		fake, err := NewFakeProvider(FakeOptions{
+			Mode:          cfg.FakeMode,
+			FixtureDir:    cfg.FakeFixtureDir,
+			Upstream:      upstream,
+			Strict:        cfg.FakeStrict,
+			Latency:       cfg.FakeLatency,
+			RateLimitRate: cfg.FakeRateLimitRate,
+			MalformedRate: cfg.FakeMalformedRate,
+			Seed:          int64(cfg.FakeSeed),
+		})
+		if err != nil {
+			return nil, err
+		}
+		c.providers["fake"] = fake
+	}
+
 	if len(c.providers) == 0 {
 		return nil, fmt.Errorf("no LLM providers configured")
 	}
@@ -80,35 +124,195 @@ func NewClient(cfg *config.LLMConfig) (*Client, error) {
 		}
 	}
 
+	fallback, err := ParseRoutes(cfg.FallbackProviders)
+	if err != nil {
+		return nil, fmt.Errorf("LLM_FALLBACK_PROVIDERS: %w", err)
+	}
+	for _, r := range fallback {
+		if !c.HasProvider(r.Provider) {
+			return nil, fmt.Errorf("fallback provider %q is not configured", r.Provider)
+		}
+	}
+	c.fallback = fallback
+
+	c.breakers = make(map[string]*breaker, len(c.providers))
+	for name := range c.providers {
+		c.breakers[name] = newBreaker(name, cfg.BreakerFailureThreshold, cfg.BreakerOpenDuration)
+	}
+
+	// Limits may name providers that are not configured in this
+	// environment; those are ignored.
+	limits, err := ParseLimits(cfg.RateLimits)
+	if err != nil {
+		return nil, fmt.Errorf("LLM_RATE_LIMITS: %w", err)
+	}
+	c.limiters = make(map[string]*limiter)
+	for name, l := range limits {
+		if !knownProviders[name] {
+			return nil, fmt.Errorf("LLM_RATE_LIMITS: unknown provider %q", name)
+		}
+		if c.HasProvider(name) {
+			c.limiters[name] = newLimiter(l)
+		}
+	}
+
 	return c, nil
 }
 
+// knownProviders lists the provider names NewClient can configure.
+var knownProviders = map[string]bool{
+	"openai": true, "anthropic": true, "ollama": true, "openrouter": true, "fake": true,
+}
+
+// UseCache makes the client answer repeated requests from cache. A nil
+// cache turns caching off.
+func (c *Client) UseCache(cache Cache) {
+	c.cache = cache
+}
+
+// DefaultProvider returns the name of the provider Complete uses.
+func (c *Client) DefaultProvider() string {
+	return c.defaultProvider
+}
+
+func (c *Client) HasProvider(name string) bool {
+	_, ok := c.providers[name]
+	return ok
+}
+
+// Complete calls the default provider, falling back along the configured
+// chain.
 func (c *Client) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
-	return c.CompleteWithProvider(ctx, c.defaultProvider, req)
+	return c.CompleteWithFallback(ctx, c.Chain(Route{Provider: c.defaultProvider}, nil), req)
 }
 
+// CompleteWithProvider calls one provider, unless its circuit breaker is
+// open. Responses found in the cache are returned without calling it.
+// Transient failures are retried by the client's RetryPolicy, within the
+// client timeout. Every attempt, retries included, is counted by the
+// breaker and first waits for the provider's rate and concurrency limits,
+// failing with ErrThrottled when the wait would outlast the deadline.
 func (c *Client) CompleteWithProvider(ctx context.Context, providerName string, req *CompletionRequest) (*CompletionResponse, error) {
 	provider, ok := c.providers[providerName]
 	if !ok {
 		return nil, fmt.Errorf("provider %s not found", providerName)
 	}
+	parent := ctx
+
+	// Attribute names follow the OpenTelemetry GenAI semantic conventions.
+	spanName := "chat"
+	if req.Model != "" {
+		spanName += " " + req.Model
+	}
+	ctx, span := tracing.Tracer().Start(ctx, spanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("gen_ai.operation.name", "chat"),
+			attribute.String("gen_ai.system", providerName),
+			attribute.String("gen_ai.request.model", req.Model),
+		),
+	)
+	defer span.End()
+
+	var key string
+	if c.cache != nil {
+		var err error
+		if key, err = cacheKey(providerName, req); err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+	}
+	if c.cache != nil && !CacheBypassed(ctx) {
+		start := time.Now()
+		resp, hit, err := c.cache.Get(ctx, key)
+		if err != nil {
+			log.Printf("LLM cache lookup failed, calling %s: %v", providerName, err)
+		}
+		span.SetAttributes(attribute.Bool("llm.cache_hit", hit))
+		if hit {
+			resp.Provider = providerName
+			resp.Cached = true
+			resp.Latency = time.Since(start)
+			return resp, nil
+		}
+	}
+
+	// The fake provider is not retried: its injected failures are meant to
+	// reach the evaluators.
+	policy := c.retry
+	if providerName == "fake" {
+		policy = RetryPolicy{}
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
-	return provider.Complete(ctx, req)
-}
+	b := c.breakers[providerName]
+	var throttled time.Duration
+	resp, err := policy.do(ctx, func() (*CompletionResponse, error) {
+		if !b.allow() {
+			return nil, ErrCircuitOpen
+		}
 
-func (c *Client) CompleteWithFallback(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
-	var lastErr error
+		waitStart := time.Now()
+		done, err := c.limiters[providerName].acquire(ctx, estimateTokens(req))
+		throttled += time.Since(waitStart)
+		if err != nil {
+			b.release()
+			return nil, err
+		}
 
-	for name, provider := range c.providers {
 		resp, err := provider.Complete(ctx, req)
 		if err == nil {
-			return resp, nil
+			done(resp.Usage.TotalTokens)
+		} else {
+			done(0)
+		}
+		if err != nil && parent.Err() != nil {
+			// Cancelled by the caller: not the provider's fault.
+			b.release()
+		} else {
+			b.record(err)
+		}
+		return resp, err
+	})
+	span.SetAttributes(attribute.Int64("llm.throttle_wait_ms", throttled.Milliseconds()))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	resp.Provider = providerName
+
+	if c.cache != nil && usable(resp, req) {
+		if err := c.cache.Set(parent, key, resp); err != nil {
+			log.Printf("LLM cache store failed: %v", err)
 		}
-		lastErr = fmt.Errorf("%s: %w", name, err)
 	}
 
-	return nil, fmt.Errorf("all providers failed: %w", lastErr)
+	span.SetAttributes(
+		attribute.String("gen_ai.response.model", resp.ModelName),
+		attribute.Int("gen_ai.usage.input_tokens", resp.Usage.PromptTokens),
+		attribute.Int("gen_ai.usage.output_tokens", resp.Usage.CompletionTokens),
+		attribute.Float64("llm.cost_usd", resp.Cost()),
+	)
+	if resp.FinishReason != "" {
+		span.SetAttributes(attribute.StringSlice("gen_ai.response.finish_reasons", []string{resp.FinishReason}))
+	}
+	return resp, nil
+}
+
+// usable reports whether resp is what req asked for: JSON matching its
+// schema, or any JSON in JSON mode. Only usable responses are cached, so a
+// malformed one is not served again in place of a fresh attempt.
+func usable(resp *CompletionResponse, req *CompletionRequest) bool {
+	switch {
+	case req.Schema != nil:
+		_, problems := NormalizeJSON(resp.Content, req.Schema)
+		return len(problems) == 0
+	case req.JSONMode:
+		_, err := ExtractJSON(resp.Content)
+		return err == nil
+	default:
+		return true
+	}
 }
@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RetryPolicy controls how provider calls are retried after transient
+// failures: rate limits, timeouts and unavailable servers.
+type RetryPolicy struct {
+	MaxRetries int           // retries after the first attempt; 0 disables
+	BaseDelay  time.Duration // backoff before the first retry, doubled for each one after
+	MaxDelay   time.Duration // cap on the backoff and on an honoured Retry-After; 0 is no cap
+}
+
+// WithRetry wraps p so that transient failures are retried with jittered
+// exponential backoff, or after the delay the provider asked for in
+// Retry-After. Waits end early when ctx is done, and a retry that could
+// not start before ctx's deadline is not attempted.
+func WithRetry(p Provider, policy RetryPolicy) Provider {
+	if policy.MaxRetries <= 0 {
+		return p
+	}
+	return &retryProvider{Provider: p, policy: policy}
+}
+
+type retryProvider struct {
+	Provider
+	policy RetryPolicy
+}
+
+func (p *retryProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	return p.policy.do(ctx, func() (*CompletionResponse, error) {
+		return p.Provider.Complete(ctx, req)
+	})
+}
+
+// do calls call until it succeeds, fails in a way a retry cannot fix, or
+// the retries run out. A client-side throttle is not retried: waiting
+// longer would not fit the deadline either.
+func (p RetryPolicy) do(ctx context.Context, call func() (*CompletionResponse, error)) (*CompletionResponse, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := call()
+		if err == nil {
+			return resp, nil
+		}
+		if attempt >= p.MaxRetries || !isTransient(err) || errors.Is(err, ErrThrottled) || ctx.Err() != nil {
+			return nil, retriedError(attempt, err)
+		}
+
+		delay, ok := p.backoff(attempt, err)
+		if deadline, hasDeadline := ctx.Deadline(); !ok || hasDeadline && time.Until(deadline) < delay {
+			return nil, retriedError(attempt, err)
+		}
+
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+			attribute.Int("llm.retry.attempt", attempt+1),
+			attribute.Int64("llm.retry.delay_ms", delay.Milliseconds()),
+			attribute.String("llm.retry.error", err.Error()),
+		))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, retriedError(attempt, err)
+		}
+	}
+}
+
+// backoff returns the wait before retry number attempt+1. A Retry-After
+// longer than MaxDelay is not waited for and reports false.
+func (p RetryPolicy) backoff(attempt int, err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		if p.MaxDelay > 0 && apiErr.RetryAfter > p.MaxDelay {
+			return 0, false
+		}
+		return apiErr.RetryAfter, true
+	}
+
+	d := p.BaseDelay << attempt
+	if p.MaxDelay > 0 && (d > p.MaxDelay || d <= 0) {
+		d = p.MaxDelay
+	}
+	// Equal jitter: half the delay is fixed, half random, so callers that
+	// failed together do not retry together.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1)), true
+}
+
+// isTransient reports whether a failed call may succeed if repeated.
+func isTransient(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrTimeout) || errors.Is(err, ErrUnavailable)
+}
+
+func retriedError(attempt int, err error) error {
+	if attempt == 0 {
+		return err
+	}
+	return fmt.Errorf("after %d attempts: %w", attempt+1, err)
+}
@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt, full := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		full *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			d, ok := p.backoff(attempt, ErrUnavailable)
+			if !ok {
+				t.Fatalf("backoff(%d) refused to retry", attempt)
+			}
+			// Equal jitter: between half and all of the capped delay.
+			if d < full/2 || d > full {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", attempt, d, full/2, full)
+			}
+		}
+	}
+
+	// Shifting far enough overflows; the cap still applies.
+	if d, ok := p.backoff(70, ErrUnavailable); !ok || d < p.MaxDelay/2 || d > p.MaxDelay {
+		t.Errorf("backoff(70) = %v, %v; want capped at %v", d, ok, p.MaxDelay)
+	}
+}
+
+func TestRetryBackoffRetryAfter(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 10 * time.Second}
+
+	err := newAPIError("p", 429, "slow down", 3*time.Second)
+	if d, ok := p.backoff(0, err); !ok || d != 3*time.Second {
+		t.Errorf("backoff with Retry-After 3s = %v, %v; want exactly 3s", d, ok)
+	}
+
+	// A Retry-After past the cap is not waited for.
+	err = newAPIError("p", 429, "slow down", time.Minute)
+	if _, ok := p.backoff(0, err); ok {
+		t.Error("backoff agreed to wait a Retry-After longer than MaxDelay")
+	}
+
+	// Without a cap any Retry-After is honoured.
+	p.MaxDelay = 0
+	if d, ok := p.backoff(0, err); !ok || d != time.Minute {
+		t.Errorf("uncapped backoff = %v, %v; want 1m", d, ok)
+	}
+}
+
+// sequence returns a provider failing with errs in turn, then answering.
+func sequence(errs ...error) *stubProvider {
+	return &stubProvider{name: "p", fn: func(call int) (*CompletionResponse, error) {
+		if call <= len(errs) {
+			return nil, errs[call-1]
+		}
+		return &CompletionResponse{Content: "ok"}, nil
+	}}
+}
+
+var fastRetries = RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+func TestWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{"success", nil, 1, nil},
+		{"transient then success", []error{ErrRateLimited, ErrTimeout}, 3, nil},
+		{"retries exhausted", []error{ErrUnavailable, ErrUnavailable, ErrUnavailable, ErrUnavailable}, 4, ErrUnavailable},
+		{"not transient", []error{errBadRequest}, 1, errBadRequest},
+		{"context overflow", []error{ErrContextOverflow}, 1, ErrContextOverflow},
+		{"throttled", []error{ErrThrottled}, 1, ErrThrottled},
+	}
+	for _, tt := range tests {
+		p := sequence(tt.errs...)
+		_, err := WithRetry(p, fastRetries).Complete(context.Background(), &CompletionRequest{})
+		if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if p.Calls() != tt.wantCalls {
+			t.Errorf("%s: %d calls, want %d", tt.name, p.Calls(), tt.wantCalls)
+		}
+	}
+}
+
+func TestWithRetryDisabled(t *testing.T) {
+	p := sequence(ErrRateLimited)
+	if got := WithRetry(p, RetryPolicy{}); got != Provider(p) {
+		t.Fatalf("WithRetry with no retries wrapped the provider")
+	}
+}
+
+func TestRetryRespectsDeadline(t *testing.T) {
+	// The only retry would start after the deadline, so it is not made.
+	p := sequence(newAPIError("p", 503, "busy", 2*time.Second))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := WithRetry(p, RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Minute}).
+		Complete(ctx, &CompletionRequest{})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("error = %v, want ErrUnavailable", err)
+	}
+	if p.Calls() != 1 {
+		t.Errorf("%d calls, want 1", p.Calls())
+	}
+	if waited := time.Since(start); waited > 50*time.Millisecond {
+		t.Errorf("gave up after %v, want at once", waited)
+	}
+}
+
+func TestClientRetriesThroughBreaker(t *testing.T) {
+	// Every attempt counts towards the breaker, so the retries trip it.
+	p := sequence(ErrUnavailable, ErrUnavailable, ErrUnavailable)
+	c := newTestClient(2, time.Hour, p)
+	c.retry = fastRetries
+
+	_, err := c.CompleteWithProvider(context.Background(), "p", &CompletionRequest{})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want the third attempt stopped by the open circuit", err)
+	}
+	if p.Calls() != 2 {
+		t.Errorf("%d calls, want 2", p.Calls())
+	}
+}
+
+func TestClientDoesNotRetryFake(t *testing.T) {
+	p := sequence(ErrRateLimited)
+	p.name = "fake"
+	c := newTestClient(0, 0, p)
+	c.retry = fastRetries
+
+	if _, err := c.CompleteWithProvider(context.Background(), "fake", &CompletionRequest{}); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("error = %v, want the injected rate limit", err)
+	}
+	if p.Calls() != 1 {
+		t.Errorf("%d calls, want 1", p.Calls())
+	}
+}
@@ -0,0 +1,218 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is the subset of JSON Schema used to request and check structured
+// responses. It marshals to standard JSON Schema, so providers with native
+// structured output can be given it directly.
+type Schema struct {
+	Type       string             `json:"type"` // object, array, string, number, integer or boolean
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Minimum    *float64           `json:"minimum,omitempty"`
+	Maximum    *float64           `json:"maximum,omitempty"`
+}
+
+// ErrNoJSON is returned by ExtractJSON when content holds no JSON object.
+var ErrNoJSON = errors.New("no JSON object in response")
+
+// ExtractJSON returns the first JSON object in content, which may be
+// wrapped in a Markdown code fence or surrounded by prose.
+func ExtractJSON(content string) (json.RawMessage, error) {
+	s := strings.TrimSpace(content)
+	if i := strings.Index(s, "```"); i >= 0 {
+		fenced := s[i+3:]
+		if nl := strings.IndexByte(fenced, '\n'); nl >= 0 {
+			fenced = fenced[nl+1:] // drop the language tag
+		}
+		if end := strings.Index(fenced, "```"); end >= 0 {
+			fenced = fenced[:end]
+		}
+		if raw, err := ExtractJSON(fenced); err == nil {
+			return raw, nil
+		}
+	}
+
+	for i := strings.IndexByte(s, '{'); i >= 0; {
+		var raw json.RawMessage
+		if err := json.NewDecoder(strings.NewReader(s[i:])).Decode(&raw); err == nil {
+			return raw, nil
+		}
+		next := strings.IndexByte(s[i+1:], '{')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return nil, ErrNoJSON
+}
+
+// NormalizeJSON extracts the JSON object from content and normalizes it
+// against schema, returning it re-encoded along with any problems left.
+func NormalizeJSON(content string, schema *Schema) (string, []string) {
+	raw, err := ExtractJSON(content)
+	if err != nil {
+		return "", []string{err.Error()}
+	}
+
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", []string{err.Error()}
+	}
+	v, problems := schema.Normalize(v)
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", []string{err.Error()}
+	}
+	return string(out), problems
+}
+
+// Normalize checks v, as decoded by encoding/json, against the schema. It
+// fixes what a small model commonly gets slightly wrong: numbers given as
+// strings, and numbers a little outside their range (up to a tenth of it),
+// which are clamped. It returns the fixed value and a description of each
+// remaining violation.
+func (s *Schema) Normalize(v any) (any, []string) {
+	var problems []string
+	v = s.normalize("", v, &problems)
+	return v, problems
+}
+
+func (s *Schema) normalize(path string, v any, problems *[]string) any {
+	fail := func(format string, args ...any) {
+		name := path
+		if name == "" {
+			name = "response"
+		}
+		*problems = append(*problems, name+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			fail("expected an object, got %s", describe(v))
+			return v
+		}
+		for _, name := range s.Required {
+			if value, ok := obj[name]; !ok || value == nil {
+				fail("missing required field %q", name)
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if value, ok := obj[name]; ok && value != nil {
+				obj[name] = s.Properties[name].normalize(joinPath(path, name), value, problems)
+			}
+		}
+		return obj
+
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			fail("expected an array, got %s", describe(v))
+			return v
+		}
+		if s.Items != nil {
+			for i := range arr {
+				arr[i] = s.Items.normalize(fmt.Sprintf("%s[%d]", path, i), arr[i], problems)
+			}
+		}
+		return arr
+
+	case "number", "integer":
+		n, ok := v.(float64)
+		if str, isString := v.(string); isString {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+			n, ok = parsed, err == nil
+		}
+		if !ok {
+			fail("expected a number, got %s", describe(v))
+			return v
+		}
+		if s.Type == "integer" && n != math.Trunc(n) {
+			fail("expected an integer, got %v", n)
+			return v
+		}
+		return s.clamp(n, fail)
+
+	case "string":
+		switch v.(type) {
+		case string:
+			return v
+		case float64, bool:
+			return fmt.Sprint(v)
+		}
+		fail("expected a string, got %s", describe(v))
+		return v
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("expected a boolean, got %s", describe(v))
+		}
+		return v
+	}
+	return v
+}
+
+// clamp brings n into [Minimum, Maximum] if it is within a tenth of the
+// range of it, and reports it otherwise.
+func (s *Schema) clamp(n float64, fail func(string, ...any)) any {
+	tolerance := 0.0
+	if s.Minimum != nil && s.Maximum != nil {
+		tolerance = (*s.Maximum - *s.Minimum) / 10
+	}
+	if s.Minimum != nil && n < *s.Minimum {
+		if n < *s.Minimum-tolerance {
+			fail("%v is below the minimum %v", n, *s.Minimum)
+		}
+		return *s.Minimum
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		if n > *s.Maximum+tolerance {
+			fail("%v is above the maximum %v", n, *s.Maximum)
+		}
+		return *s.Maximum
+	}
+	return n
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func describe(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
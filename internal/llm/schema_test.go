@@ -0,0 +1,155 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"bare", `{"score": 0.9}`, `{"score": 0.9}`},
+		{"padded", "\n  {\"a\":1}  \n", `{"a":1}`},
+		{"fenced", "```json\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"fence without tag", "```\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"prose", `Here is my evaluation: {"a": {"b": [1, 2]}} Hope it helps!`, `{"a": {"b": [1, 2]}}`},
+		{"prose around fence", "Sure!\n```json\n{\"a\": 1}\n```\nLet me know.", `{"a": 1}`},
+		{"brace in prose", `Using {braces} loosely, the answer is {"a": "}"}`, `{"a": "}"}`},
+		{"first of several", `{"a": 1} and {"b": 2}`, `{"a": 1}`},
+		{"broken fence", "```json\n{\"a\": \n```\n{\"b\": 2}", `{"b": 2}`},
+	}
+	for _, tt := range tests {
+		got, err := ExtractJSON(tt.in)
+		if err != nil {
+			t.Errorf("%s: ExtractJSON: %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: ExtractJSON = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "no json here", `{"unterminated": `, "[1, 2, 3]"} {
+		if got, err := ExtractJSON(in); !errors.Is(err, ErrNoJSON) {
+			t.Errorf("ExtractJSON(%q) = %s, %v; want ErrNoJSON", in, got, err)
+		}
+	}
+}
+
+func judgeSchema() *Schema {
+	zero, one := 0.0, 1.0
+	score := &Schema{Type: "number", Minimum: &zero, Maximum: &one}
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"score":     score,
+			"reasoning": {Type: "string"},
+			"passed":    {Type: "boolean"},
+			"turn_id":   {Type: "integer"},
+			"issues": {Type: "array", Items: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"type": {Type: "string"}, "confidence": score},
+				Required:   []string{"type"},
+			}},
+		},
+		Required: []string{"score", "reasoning"},
+	}
+}
+
+func normalize(t *testing.T, s *Schema, in string) (any, []string) {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(in), &v); err != nil {
+		t.Fatalf("unmarshal %s: %v", in, err)
+	}
+	return s.Normalize(v)
+}
+
+func TestNormalizeFixes(t *testing.T) {
+	got, problems := normalize(t, judgeSchema(),
+		`{"score": "0.8", "reasoning": 42, "turn_id": 3, "issues": [{"type": "tone", "confidence": 1.05}, {"type": "x", "confidence": -0.02}]}`)
+	if len(problems) > 0 {
+		t.Fatalf("problems = %v, want none", problems)
+	}
+	want := map[string]any{
+		"score":     0.8,
+		"reasoning": "42",
+		"turn_id":   3.0,
+		"issues": []any{
+			map[string]any{"type": "tone", "confidence": 1.0},
+			map[string]any{"type": "x", "confidence": 0.0},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Normalize = %#v, want %#v", got, want)
+	}
+}
+
+func TestNormalizeProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"not an object", `[1]`, []string{"response: expected an object, got an array"}},
+		{"missing", `{"score": 0.5}`, []string{`response: missing required field "reasoning"`}},
+		{"null required", `{"score": null, "reasoning": "r"}`, []string{`response: missing required field "score"`}},
+		{"far out of range", `{"score": 1.5, "reasoning": "r"}`, []string{"score: 1.5 is above the maximum 1"}},
+		{"below range", `{"score": -0.5, "reasoning": "r"}`, []string{"score: -0.5 is below the minimum 0"}},
+		{"not a number", `{"score": "high", "reasoning": "r"}`, []string{"score: expected a number, got a string"}},
+		{"not an integer", `{"score": 1, "reasoning": "r", "turn_id": 2.5}`, []string{"turn_id: expected an integer, got 2.5"}},
+		{"not a boolean", `{"score": 1, "reasoning": "r", "passed": "yes"}`, []string{"passed: expected a boolean, got a string"}},
+		{"not a string", `{"score": 1, "reasoning": {"a": 1}}`, []string{"reasoning: expected a string, got an object"}},
+		{"nested", `{"score": 1, "reasoning": "r", "issues": [{"confidence": 0.5}, "x"]}`, []string{
+			`issues[0]: missing required field "type"`,
+			"issues[1]: expected an object, got a string",
+		}},
+	}
+	for _, tt := range tests {
+		_, problems := normalize(t, judgeSchema(), tt.in)
+		if !reflect.DeepEqual(problems, tt.want) {
+			t.Errorf("%s: problems = %q, want %q", tt.name, problems, tt.want)
+		}
+	}
+}
+
+func TestNormalizeJSON(t *testing.T) {
+	content, problems := NormalizeJSON("```json\n{\"score\": \"1.02\", \"reasoning\": \"ok\"}\n```", judgeSchema())
+	if len(problems) > 0 {
+		t.Fatalf("problems = %v, want none", problems)
+	}
+	if content != `{"reasoning":"ok","score":1}` {
+		t.Errorf("content = %s", content)
+	}
+
+	content, problems = NormalizeJSON("I cannot evaluate this.", judgeSchema())
+	if content != "" || len(problems) != 1 || !strings.Contains(problems[0], ErrNoJSON.Error()) {
+		t.Errorf("NormalizeJSON without JSON = %q, %q; want the no JSON problem", content, problems)
+	}
+}
+
+func TestUsable(t *testing.T) {
+	schema := judgeSchema()
+	tests := []struct {
+		name    string
+		content string
+		req     CompletionRequest
+		want    bool
+	}{
+		{"matches schema", `{"score": 0.5, "reasoning": "r"}`, CompletionRequest{Schema: schema}, true},
+		{"fixable", "```json\n{\"score\": \"0.5\", \"reasoning\": \"r\"}\n```", CompletionRequest{Schema: schema}, true},
+		{"violates schema", `{"score": 0.5}`, CompletionRequest{Schema: schema}, false},
+		{"json mode", `{"anything": true}`, CompletionRequest{JSONMode: true}, true},
+		{"json mode prose", `not json`, CompletionRequest{JSONMode: true}, false},
+		{"free text", `not json`, CompletionRequest{}, true},
+	}
+	for _, tt := range tests {
+		if got := usable(&CompletionResponse{Content: tt.content}, &tt.req); got != tt.want {
+			t.Errorf("%s: usable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
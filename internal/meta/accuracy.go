@@ -11,10 +11,11 @@ func NewAccuracyTracker() *AccuracyTracker {
 }
 
 type Prediction struct {
-	EvaluatorType domain.EvaluatorType
-	Predicted     bool
-	Actual        bool
-	Category      string
+	EvaluatorType  domain.EvaluatorType
+	ConversationID string
+	Predicted      bool
+	Actual         bool
+	Category       string
 }
 
 type AccuracyResult struct {
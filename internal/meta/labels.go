@@ -0,0 +1,218 @@
+package meta
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+// Labels that mean the annotator found nothing wrong for the annotated
+// category. Anything else (e.g. "incorrect", "hallucination", "bad") is
+// treated as the human confirming an issue.
+var nonIssueLabels = map[string]bool{
+	"correct":    true,
+	"good":       true,
+	"ok":         true,
+	"pass":       true,
+	"passed":     true,
+	"acceptable": true,
+	"helpful":    true,
+	"resolved":   true,
+	"none":       true,
+	"no_issue":   true,
+	"no":         true,
+	"false":      true,
+}
+
+// LabelIndicatesIssue reports whether a human label marks a problem.
+// Numeric labels are treated as quality scores where < 0.5 is a problem.
+func LabelIndicatesIssue(label string) bool {
+	if score, ok := numericLabel(label); ok {
+		return score < 0.5
+	}
+	return !nonIssueLabels[normalizeLabel(label)]
+}
+
+// LabelScore converts a human label to a 0-1 quality score comparable with
+// evaluator scores.
+func LabelScore(label string) float64 {
+	if score, ok := numericLabel(label); ok {
+		return score
+	}
+	if LabelIndicatesIssue(label) {
+		return 0.0
+	}
+	return 1.0
+}
+
+func numericLabel(label string) (float64, bool) {
+	score, err := strconv.ParseFloat(strings.TrimSpace(label), 64)
+	if err != nil || score < 0 || score > 1 {
+		return 0, false
+	}
+	return score, true
+}
+
+func normalizeLabel(s string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "_")
+}
+
+// LabeledSet pairs evaluator output with the human annotations recorded for
+// the same conversations.
+type LabeledSet struct {
+	Evaluations []*domain.Evaluation
+	Annotations map[string][]domain.Annotation
+}
+
+// NewLabeledSet keeps only the latest evaluation per conversation and
+// evaluator type, so conversations evaluated more than once are not counted
+// twice. evals must be ordered oldest first.
+func NewLabeledSet(evals []*domain.Evaluation, anns []*domain.Annotation) *LabeledSet {
+	type key struct {
+		conversationID string
+		evaluatorType  domain.EvaluatorType
+	}
+	latest := make(map[key]int)
+	var deduped []*domain.Evaluation
+	for _, e := range evals {
+		k := key{e.ConversationID, e.EvaluatorType}
+		if i, ok := latest[k]; ok {
+			deduped[i] = e
+			continue
+		}
+		latest[k] = len(deduped)
+		deduped = append(deduped, e)
+	}
+
+	set := &LabeledSet{
+		Evaluations: deduped,
+		Annotations: make(map[string][]domain.Annotation),
+	}
+	for _, a := range anns {
+		set.Annotations[a.ConversationID] = append(set.Annotations[a.ConversationID], *a)
+	}
+	return set
+}
+
+// ConversationIDs returns the distinct conversation IDs of evals, in order.
+func ConversationIDs(evals []*domain.Evaluation) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, e := range evals {
+		if !seen[e.ConversationID] {
+			seen[e.ConversationID] = true
+			ids = append(ids, e.ConversationID)
+		}
+	}
+	return ids
+}
+
+// EvaluatorTypes returns the evaluator types present in the set, sorted.
+func (s *LabeledSet) EvaluatorTypes() []domain.EvaluatorType {
+	seen := make(map[domain.EvaluatorType]bool)
+	var types []domain.EvaluatorType
+	for _, e := range s.Evaluations {
+		if !seen[e.EvaluatorType] {
+			seen[e.EvaluatorType] = true
+			types = append(types, e.EvaluatorType)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// Predictions builds one prediction per (evaluation, annotated category):
+// the evaluator "predicted" an issue if it raised an issue of that type, and
+// the issue is "actual" if the majority of human labels for it mark a problem.
+// Issue types no human annotated are skipped since there is no ground truth.
+func (s *LabeledSet) Predictions(evalType domain.EvaluatorType) []Prediction {
+	var predictions []Prediction
+
+	for _, e := range s.Evaluations {
+		if e.EvaluatorType != evalType {
+			continue
+		}
+
+		raised := make(map[string]bool)
+		for _, issue := range e.Issues {
+			raised[normalizeLabel(issue.Type)] = true
+		}
+
+		for category, anns := range groupByCategory(s.Annotations[e.ConversationID]) {
+			predictions = append(predictions, Prediction{
+				EvaluatorType:  evalType,
+				ConversationID: e.ConversationID,
+				Predicted:      raised[category],
+				Actual:         majorityIssue(anns),
+				Category:       category,
+			})
+		}
+	}
+
+	return predictions
+}
+
+// ComparisonPairs pairs each evaluation's overall score with the mean human
+// score for its conversation, plus per-category pairs for the breakdown.
+func (s *LabeledSet) ComparisonPairs(evalType domain.EvaluatorType) ([]ComparisonPair, map[string][]ComparisonPair) {
+	var pairs []ComparisonPair
+	byCategory := make(map[string][]ComparisonPair)
+
+	for _, e := range s.Evaluations {
+		if e.EvaluatorType != evalType {
+			continue
+		}
+
+		anns := s.Annotations[e.ConversationID]
+		if len(anns) == 0 {
+			continue
+		}
+
+		pairs = append(pairs, ComparisonPair{
+			EvaluatorScore: e.Scores.Overall,
+			HumanScore:     meanLabelScore(anns),
+		})
+
+		for category, catAnns := range groupByCategory(anns) {
+			byCategory[category] = append(byCategory[category], ComparisonPair{
+				EvaluatorScore: e.Scores.Overall,
+				HumanScore:     meanLabelScore(catAnns),
+			})
+		}
+	}
+
+	return pairs, byCategory
+}
+
+func groupByCategory(anns []domain.Annotation) map[string][]domain.Annotation {
+	groups := make(map[string][]domain.Annotation)
+	for _, a := range anns {
+		category := normalizeLabel(a.Type)
+		groups[category] = append(groups[category], a)
+	}
+	return groups
+}
+
+func majorityIssue(anns []domain.Annotation) bool {
+	issueVotes := 0
+	for _, a := range anns {
+		if LabelIndicatesIssue(a.Label) {
+			issueVotes++
+		}
+	}
+	// Ties count as an issue: a disputed label is worth surfacing.
+	return issueVotes*2 >= len(anns)
+}
+
+func meanLabelScore(anns []domain.Annotation) float64 {
+	if len(anns) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, a := range anns {
+		sum += LabelScore(a.Label)
+	}
+	return sum / float64(len(anns))
+}
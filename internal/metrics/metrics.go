@@ -0,0 +1,112 @@
+// Package metrics exposes Prometheus metrics for the server and worker.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+// Path is where both cmd/server and cmd/worker serve metrics; /metrics is
+// the dashboard's HTML page.
+const Path = "/internal/metrics"
+
+const namespace = "healing_eval"
+
+var (
+	evaluationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "evaluations_total",
+		Help:      "Evaluator runs by evaluator type and status.",
+	}, []string{"evaluator", "status"})
+
+	evaluatorDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "evaluator_duration_seconds",
+		Help:      "Evaluator run latency by evaluator type and status.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 40, 80},
+	}, []string{"evaluator", "status"})
+
+	llmTokensTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_tokens_total",
+		Help:      "LLM tokens used by evaluators, by provider, model and type (prompt or completion).",
+	}, []string{"provider", "model", "type"})
+
+	llmCostTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_cost_usd_total",
+		Help:      "Estimated LLM cost of evaluators in USD, by provider and model.",
+	}, []string{"provider", "model"})
+
+	llmCacheHitsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_cache_hits_total",
+		Help:      "Evaluator LLM calls answered from the response cache, by provider and model.",
+	}, []string{"provider", "model"})
+
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "code"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// Handler serves the default registry.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveEvaluations records stored evaluator runs, successful or not.
+func ObserveEvaluations(evals ...*domain.Evaluation) {
+	for _, e := range evals {
+		evaluator := string(e.EvaluatorType)
+		status := string(e.Status)
+
+		evaluationsTotal.WithLabelValues(evaluator, status).Inc()
+		evaluatorDuration.WithLabelValues(evaluator, status).Observe(float64(e.LatencyMs) / 1000)
+
+		provider, model := e.Provider, e.ModelName
+		if e.Cached {
+			// No tokens were spent.
+			llmCacheHitsTotal.WithLabelValues(provider, model).Inc()
+			continue
+		}
+		if e.PromptTokens == 0 && e.CompletionTokens == 0 {
+			continue
+		}
+		llmTokensTotal.WithLabelValues(provider, model, "prompt").Add(float64(e.PromptTokens))
+		llmTokensTotal.WithLabelValues(provider, model, "completion").Add(float64(e.CompletionTokens))
+		llmCostTotal.WithLabelValues(provider, model).Add(e.EstimatedCostUSD)
+	}
+}
+
+// Middleware records HTTP request counts and latency. Requests are labelled
+// by route pattern (e.g. /api/v1/jobs/:id) to keep cardinality bounded.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		httpRequestsTotal.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpRequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
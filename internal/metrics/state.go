@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/saisaravanan/healing-eval/internal/queue"
+	"github.com/saisaravanan/healing-eval/internal/storage"
+)
+
+// stateTimeout bounds the queue and database reads made per scrape.
+const stateTimeout = 5 * time.Second
+
+var (
+	queueLengthDesc = prometheus.NewDesc(namespace+"_queue_length",
+		"Conversations in the ingestion queue that are not yet acked, across priority lanes.", nil, nil)
+	deadLettersDesc = prometheus.NewDesc(namespace+"_queue_dead_letters",
+		"Messages in the dead-letter stream.", nil, nil)
+	pendingRetriesDesc = prometheus.NewDesc(namespace+"_evaluator_retries_pending",
+		"Evaluator retries scheduled but not yet run.", nil, nil)
+	reviewQueueDesc = prometheus.NewDesc(namespace+"_review_queue_depth",
+		"Conversations pending human review.", nil, nil)
+)
+
+// stateCollector reads queue and review-queue depths on each scrape.
+type stateCollector struct {
+	queue   queue.Queue
+	reviews storage.ReviewQueueRepository
+}
+
+// RegisterState adds the queue and review-queue gauges to the default
+// registry. Call it once per process.
+func RegisterState(q queue.Queue, reviews storage.ReviewQueueRepository) {
+	prometheus.MustRegister(&stateCollector{queue: q, reviews: reviews})
+}
+
+func (c *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueLengthDesc
+	ch <- deadLettersDesc
+	ch <- pendingRetriesDesc
+	ch <- reviewQueueDesc
+}
+
+// Collect skips any gauge whose read fails so one outage does not hide the
+// rest.
+func (c *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), stateTimeout)
+	defer cancel()
+
+	gauge := func(name string, desc *prometheus.Desc, read func(context.Context) (int64, error)) {
+		n, err := read(ctx)
+		if err != nil {
+			log.Printf("Metrics: read %s: %v", name, err)
+			return
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(n))
+	}
+
+	gauge("queue length", queueLengthDesc, c.queue.Len)
+	gauge("dead letters", deadLettersDesc, c.queue.DeadLetterCount)
+	gauge("pending retries", pendingRetriesDesc, c.queue.PendingRetries)
+	gauge("review queue depth", reviewQueueDesc, func(ctx context.Context) (int64, error) {
+		n, err := c.reviews.CountPending(ctx)
+		return int64(n), err
+	})
+}
@@ -0,0 +1,93 @@
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Content types OTLP/HTTP exporters send.
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+)
+
+// ErrUnsupportedContentType is returned for bodies that are neither binary
+// protobuf nor JSON.
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// MediaType returns the encoding of a request with the given Content-Type.
+func MediaType(contentType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", ErrUnsupportedContentType
+	}
+	switch mediaType {
+	case ContentTypeProtobuf, ContentTypeJSON:
+		return mediaType, nil
+	}
+	return "", ErrUnsupportedContentType
+}
+
+// DecodeRequest parses a trace export encoded as mediaType.
+func DecodeRequest(mediaType string, body []byte) (*coltracepb.ExportTraceServiceRequest, error) {
+	req := &coltracepb.ExportTraceServiceRequest{}
+	switch mediaType {
+	case ContentTypeProtobuf:
+		if err := proto.Unmarshal(body, req); err != nil {
+			return nil, fmt.Errorf("decode protobuf: %w", err)
+		}
+	case ContentTypeJSON:
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, req); err != nil {
+			return nil, fmt.Errorf("decode json: %w", err)
+		}
+		fixJSONIDs(req)
+	default:
+		return nil, ErrUnsupportedContentType
+	}
+	return req, nil
+}
+
+// EncodeResponse encodes resp as mediaType.
+func EncodeResponse(mediaType string, resp *coltracepb.ExportTraceServiceResponse) ([]byte, error) {
+	if mediaType == ContentTypeJSON {
+		return protojson.Marshal(resp)
+	}
+	return proto.Marshal(resp)
+}
+
+// fixJSONIDs re-decodes trace and span IDs. OTLP/JSON writes them as hex
+// where protojson expects base64, so they arrive mangled.
+func fixJSONIDs(req *coltracepb.ExportTraceServiceRequest) {
+	for _, rs := range req.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			for _, s := range ss.GetSpans() {
+				s.TraceId = hexID(s.TraceId, 16)
+				s.SpanId = hexID(s.SpanId, 8)
+				s.ParentSpanId = hexID(s.ParentSpanId, 8)
+				for _, l := range s.Links {
+					l.TraceId = hexID(l.TraceId, 16)
+					l.SpanId = hexID(l.SpanId, 8)
+				}
+			}
+		}
+	}
+}
+
+// hexID recovers an ID of size bytes from its hex text decoded as base64.
+func hexID(b []byte, size int) []byte {
+	if len(b) == 0 || len(b) == size {
+		return b
+	}
+	id, err := hex.DecodeString(base64.StdEncoding.EncodeToString(b))
+	if err != nil || len(id) != size {
+		return b
+	}
+	return id
+}
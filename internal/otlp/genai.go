@@ -0,0 +1,537 @@
+// Package otlp converts OpenTelemetry spans that follow the GenAI semantic
+// conventions, received over OTLP/HTTP, into conversations for evaluation.
+package otlp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// Source marks conversations built from spans in their metadata.
+const Source = "otlp"
+
+// UnknownAgentVersion is used when no span or resource names the agent's
+// version.
+const UnknownAgentVersion = "unknown"
+
+// Longest conversation ID and agent version that can be stored.
+const (
+	maxIDLength      = 64
+	maxVersionLength = 32
+)
+
+// ErrNotFromTraces is returned by Merge when the stored conversation with
+// the incoming one's ID was not built from spans.
+var ErrNotFromTraces = errors.New("conversation exists and was not ingested from traces")
+
+// Operation names from the GenAI conventions that are not model calls.
+const (
+	opExecuteTool = "execute_tool"
+	opInvokeAgent = "invoke_agent"
+	opCreateAgent = "create_agent"
+	opEmbeddings  = "embeddings"
+)
+
+// conversationKeys are the attributes that name the conversation a span
+// belongs to, in order of preference.
+var conversationKeys = []string{"gen_ai.conversation.id", "session.id"}
+
+type span struct {
+	span     *tracepb.Span
+	attrs    map[string]interface{}
+	resource map[string]interface{}
+}
+
+func (s *span) operation() string {
+	return stringAttr(s.attrs, "gen_ai.operation.name")
+}
+
+func (s *span) start() time.Time {
+	return time.Unix(0, int64(s.span.StartTimeUnixNano)).UTC()
+}
+
+func (s *span) end() time.Time {
+	return time.Unix(0, int64(s.span.EndTimeUnixNano)).UTC()
+}
+
+func (s *span) isGenAI() bool {
+	for key := range s.attrs {
+		if strings.HasPrefix(key, "gen_ai.") {
+			return true
+		}
+	}
+	return false
+}
+
+// Metadata is stored on conversations built from spans.
+type Metadata struct {
+	Source      string   `json:"source"`
+	ServiceName string   `json:"service_name,omitempty"`
+	AgentName   string   `json:"agent_name,omitempty"`
+	TraceIDs    []string `json:"trace_ids"`
+
+	// ConversationID is the ID given by the spans when it was too long to
+	// store and the conversation's ID is a hash of it.
+	ConversationID string `json:"conversation_id,omitempty"`
+}
+
+// Convert builds one conversation per conversation found in an export.
+// Spans are grouped by gen_ai.conversation.id or session.id, taken from the
+// span itself or its nearest ancestor in the same export, and by trace
+// otherwise. Model-call spans supply the turns; execute_tool spans supply
+// tool results and latency. IDs too long to store are replaced by a hash
+// and agent versions are truncated. Exports without GenAI spans yield
+// nothing.
+func Convert(req *coltracepb.ExportTraceServiceRequest) []*domain.Conversation {
+	var all []*span
+	byID := make(map[string]*span)
+	for _, rs := range req.GetResourceSpans() {
+		resource := attributes(rs.GetResource().GetAttributes())
+		for _, ss := range rs.GetScopeSpans() {
+			for _, s := range ss.GetSpans() {
+				sp := &span{span: s, attrs: attributes(s.Attributes), resource: resource}
+				all = append(all, sp)
+				byID[hex.EncodeToString(s.SpanId)] = sp
+			}
+		}
+	}
+
+	var order []string
+	groups := make(map[string][]*span)
+	for _, s := range all {
+		if !s.isGenAI() {
+			continue
+		}
+		id := conversationID(s, byID)
+		if _, ok := groups[id]; !ok {
+			order = append(order, id)
+		}
+		groups[id] = append(groups[id], s)
+	}
+
+	var convs []*domain.Conversation
+	for _, id := range order {
+		if conv := buildConversation(id, groups[id]); conv != nil {
+			convs = append(convs, conv)
+		}
+	}
+	return convs
+}
+
+func conversationID(s *span, byID map[string]*span) string {
+	seen := make(map[*span]bool)
+	for cur := s; cur != nil && !seen[cur]; cur = byID[hex.EncodeToString(cur.span.ParentSpanId)] {
+		seen[cur] = true
+		for _, key := range conversationKeys {
+			if id := stringAttr(cur.attrs, key); id != "" {
+				return id
+			}
+		}
+	}
+	return hex.EncodeToString(s.span.TraceId)
+}
+
+// transcript accumulates the turns of one conversation.
+type transcript struct {
+	turns []domain.Turn
+	seen  []message // non-system messages already turned into turns
+	calls map[string]callRef
+}
+
+// callRef locates a tool call by turn and call index.
+type callRef struct{ turn, call int }
+
+func buildConversation(id string, spans []*span) *domain.Conversation {
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].span.StartTimeUnixNano < spans[j].span.StartTimeUnixNano
+	})
+
+	// Model-call spans hold the messages. Agent spans often repeat them, so
+	// they are only read when no model call was captured.
+	var chats, agents, tools []*span
+	for _, s := range spans {
+		switch s.operation() {
+		case opExecuteTool:
+			tools = append(tools, s)
+		case opInvokeAgent, opCreateAgent:
+			agents = append(agents, s)
+		case opEmbeddings:
+		default:
+			chats = append(chats, s)
+		}
+	}
+
+	t := &transcript{calls: make(map[string]callRef)}
+	read := 0
+	for _, s := range chats {
+		if t.addSpan(s) {
+			read++
+		}
+	}
+	if read == 0 {
+		for _, s := range agents {
+			t.addSpan(s)
+		}
+	}
+	for _, s := range tools {
+		t.addToolSpan(s)
+	}
+	if len(t.turns) == 0 {
+		return nil
+	}
+
+	meta := Metadata{Source: Source}
+	if len(id) > maxIDLength {
+		meta.ConversationID = id
+		id = hashID(id)
+	}
+	traces := make(map[string]bool)
+	agentVersion := ""
+	for _, s := range spans {
+		if traceID := hex.EncodeToString(s.span.TraceId); !traces[traceID] {
+			traces[traceID] = true
+			meta.TraceIDs = append(meta.TraceIDs, traceID)
+		}
+		if meta.ServiceName == "" {
+			meta.ServiceName = stringAttr(s.resource, "service.name")
+		}
+		if meta.AgentName == "" {
+			meta.AgentName = stringAttr(s.attrs, "gen_ai.agent.name")
+		}
+		if agentVersion == "" {
+			agentVersion = stringAttr(s.attrs, "gen_ai.agent.version")
+		}
+		if agentVersion == "" {
+			agentVersion = stringAttr(s.resource, "service.version")
+		}
+	}
+	if agentVersion == "" {
+		agentVersion = UnknownAgentVersion
+	}
+	agentVersion = truncate(agentVersion, maxVersionLength)
+	metaJSON, _ := json.Marshal(meta)
+
+	return &domain.Conversation{
+		ID:           id,
+		AgentVersion: agentVersion,
+		Turns:        sortTurns(t.turns),
+		Metadata:     metaJSON,
+	}
+}
+
+// hashID derives a storable conversation ID from one that is too long.
+func hashID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return Source + "-" + hex.EncodeToString(sum[:16])
+}
+
+// truncate shortens s to at most n bytes without splitting a character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// addSpan appends the turns a model call added. Calls usually resend the
+// history, possibly trimmed or without tool messages, so leading input
+// already in the transcript is skipped. It reports whether the span carried
+// messages.
+func (t *transcript) addSpan(s *span) bool {
+	input, output := spanMessages(s)
+	if len(input) == 0 && len(output) == 0 {
+		return false
+	}
+
+	var msgs []message
+	for _, m := range input {
+		if m.Role != "system" && m.Role != "developer" {
+			msgs = append(msgs, m)
+		}
+	}
+
+	i, pos := 0, 0
+	for ; i < len(msgs); i++ {
+		j := indexMessage(t.seen[pos:], msgs[i])
+		if j < 0 {
+			break
+		}
+		pos += j + 1
+	}
+
+	for _, m := range msgs[i:] {
+		t.add(m, s.start())
+	}
+	for _, m := range output {
+		t.add(m, s.end())
+	}
+	t.seen = append(t.seen, msgs[i:]...)
+	t.seen = append(t.seen, output...)
+	return true
+}
+
+func indexMessage(msgs []message, m message) int {
+	for i, seen := range msgs {
+		if seen.Role == m.Role && seen.Content == m.Content {
+			return i
+		}
+	}
+	return -1
+}
+
+func (t *transcript) add(m message, ts time.Time) {
+	if m.Role == "tool" {
+		ref, ok := t.calls[m.ToolCallID]
+		if !ok {
+			if ref, ok = lastOpenCall(t.turns, "", ts); !ok {
+				return
+			}
+		}
+		tc := &t.turns[ref.turn].ToolCalls[ref.call]
+		if tc.Result == nil {
+			tc.Result = &domain.ToolResult{Status: "success", Data: toolData(m.Content)}
+		}
+		return
+	}
+
+	turn := domain.Turn{Role: m.Role, Content: m.Content, Timestamp: ts}
+	for _, c := range m.ToolCalls {
+		if c.ID != "" {
+			t.calls[c.ID] = callRef{turn: len(t.turns), call: len(turn.ToolCalls)}
+		}
+		turn.ToolCalls = append(turn.ToolCalls, domain.ToolCall{ToolName: c.Name, Parameters: c.Arguments})
+	}
+	t.turns = append(t.turns, turn)
+}
+
+// addToolSpan records a tool execution's result and latency on the call
+// that requested it: the one with its gen_ai.tool.call.id, else the latest
+// unanswered call of the same tool. Executions no model call asked for get
+// an assistant turn of their own.
+func (t *transcript) addToolSpan(s *span) {
+	name := stringAttr(s.attrs, "gen_ai.tool.name")
+
+	ref, ok := t.calls[stringAttr(s.attrs, "gen_ai.tool.call.id")]
+	if !ok {
+		ref, ok = lastOpenCall(t.turns, name, s.start())
+	}
+	if !ok {
+		t.turns = append(t.turns, domain.Turn{
+			Role:      "assistant",
+			Timestamp: s.start(),
+			ToolCalls: []domain.ToolCall{{
+				ToolName:   name,
+				Parameters: jsonValue(json.RawMessage(quoted(stringAttr(s.attrs, "gen_ai.tool.call.arguments")))),
+			}},
+		})
+		ref = callRef{turn: len(t.turns) - 1}
+	}
+
+	tc := &t.turns[ref.turn].ToolCalls[ref.call]
+	tc.LatencyMs = int(s.end().Sub(s.start()).Milliseconds())
+	tc.Result = toolSpanResult(s, tc.Result)
+}
+
+// lastOpenCall finds the latest call without a result made at or before
+// ts, restricted to one tool when name is set.
+func lastOpenCall(turns []domain.Turn, name string, ts time.Time) (callRef, bool) {
+	for i := len(turns) - 1; i >= 0; i-- {
+		if turns[i].Timestamp.After(ts) {
+			continue
+		}
+		for j := len(turns[i].ToolCalls) - 1; j >= 0; j-- {
+			tc := turns[i].ToolCalls[j]
+			if tc.Result == nil && (name == "" || tc.ToolName == name) {
+				return callRef{turn: i, call: j}, true
+			}
+		}
+	}
+	return callRef{}, false
+}
+
+func toolSpanResult(s *span, prev *domain.ToolResult) *domain.ToolResult {
+	result := &domain.ToolResult{Status: "success"}
+	if prev != nil {
+		result.Data = prev.Data
+	}
+	if v := stringAttr(s.attrs, "gen_ai.tool.call.result"); v != "" {
+		result.Data = toolData(v)
+	}
+	if isError(s.span) {
+		result.Status = "error"
+		result.Error = s.span.GetStatus().GetMessage()
+		if result.Error == "" {
+			result.Error = stringAttr(s.attrs, "error.type")
+		}
+	}
+	return result
+}
+
+// toolData stores a tool's output as JSON, quoting plain text.
+func toolData(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	return json.RawMessage(quoted(s))
+}
+
+func quoted(s string) string {
+	if s == "" {
+		return ""
+	}
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// sortTurns orders turns by time, keeping the order of turns from the same
+// span, and numbers them from 1.
+func sortTurns(turns []domain.Turn) []domain.Turn {
+	sort.SliceStable(turns, func(i, j int) bool {
+		return turns[i].Timestamp.Before(turns[j].Timestamp)
+	})
+	for i := range turns {
+		turns[i].TurnID = i + 1
+	}
+	return turns
+}
+
+// Merge adds the turns of a conversation converted from a later export to
+// the stored one, since a long conversation's spans arrive over several
+// exports. Leading turns already stored, such as resent history, are
+// skipped and tool results are attached to the calls they answer. With no
+// stored conversation it returns incoming; a stored conversation that was
+// not built from spans is left alone and ErrNotFromTraces returned.
+func Merge(existing, incoming *domain.Conversation) (*domain.Conversation, error) {
+	if existing == nil {
+		return incoming, nil
+	}
+	var meta Metadata
+	if json.Unmarshal(existing.Metadata, &meta) != nil || meta.Source != Source {
+		return nil, ErrNotFromTraces
+	}
+
+	merged := *incoming
+	turns := append([]domain.Turn(nil), existing.Turns...)
+	pos, stored := 0, true
+	for _, turn := range incoming.Turns {
+		if stored {
+			if j := indexTurn(turns[pos:], turn); j >= 0 {
+				pos += j + 1
+				fillResults(&turns[pos-1], turn)
+				continue
+			}
+			// A tool execution is timed before the history its export
+			// resends, so it may have matched a later stored turn.
+			if j := indexTurn(turns[:pos], turn); j >= 0 {
+				fillResults(&turns[j], turn)
+				continue
+			}
+			stored = false
+		}
+
+		// An execution whose call was stored by an earlier export.
+		if turn.Role == "assistant" && turn.Content == "" && len(turn.ToolCalls) > 0 {
+			var unmatched []domain.ToolCall
+			for _, tc := range turn.ToolCalls {
+				if ref, ok := lastOpenCall(turns, tc.ToolName, turn.Timestamp); ok && tc.Result != nil {
+					turns[ref.turn].ToolCalls[ref.call].Result = tc.Result
+					turns[ref.turn].ToolCalls[ref.call].LatencyMs = tc.LatencyMs
+					continue
+				}
+				unmatched = append(unmatched, tc)
+			}
+			if len(unmatched) == 0 {
+				continue
+			}
+			turn.ToolCalls = unmatched
+		}
+		turns = append(turns, turn)
+	}
+	merged.Turns = sortTurns(turns)
+
+	var incomingMeta Metadata
+	json.Unmarshal(incoming.Metadata, &incomingMeta)
+	for _, id := range incomingMeta.TraceIDs {
+		if !containsString(meta.TraceIDs, id) {
+			meta.TraceIDs = append(meta.TraceIDs, id)
+		}
+	}
+	if meta.ServiceName == "" {
+		meta.ServiceName = incomingMeta.ServiceName
+	}
+	if meta.AgentName == "" {
+		meta.AgentName = incomingMeta.AgentName
+	}
+	merged.Metadata, _ = json.Marshal(meta)
+	if merged.AgentVersion == UnknownAgentVersion {
+		merged.AgentVersion = existing.AgentVersion
+	}
+	merged.Feedback = existing.Feedback
+
+	return &merged, nil
+}
+
+// indexTurn finds turn by role and content, or by the tools called when it
+// has no content.
+func indexTurn(turns []domain.Turn, turn domain.Turn) int {
+	for i, t := range turns {
+		if t.Role != turn.Role || t.Content != turn.Content {
+			continue
+		}
+		if turn.Content == "" && !sameTools(t.ToolCalls, turn.ToolCalls) {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func sameTools(a, b []domain.ToolCall) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ToolName != b[i].ToolName {
+			return false
+		}
+	}
+	return true
+}
+
+// fillResults copies results the stored turn's calls are missing.
+func fillResults(stored *domain.Turn, turn domain.Turn) {
+	if !sameTools(stored.ToolCalls, turn.ToolCalls) {
+		return
+	}
+	for i, tc := range turn.ToolCalls {
+		if stored.ToolCalls[i].Result == nil && tc.Result != nil {
+			stored.ToolCalls[i].Result = tc.Result
+			stored.ToolCalls[i].LatencyMs = tc.LatencyMs
+		}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,286 @@
+package otlp
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+const baseTime = uint64(1760000000000000000)
+
+func attr(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+// testSpan is a span starting ms milliseconds after baseTime and lasting
+// 10ms.
+func testSpan(trace, id, parent byte, ms uint64, attrs ...*commonpb.KeyValue) *tracepb.Span {
+	s := &tracepb.Span{
+		TraceId:           []byte{trace, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		SpanId:            []byte{id, 1, 2, 3, 4, 5, 6, 7},
+		StartTimeUnixNano: baseTime + ms*1e6,
+		EndTimeUnixNano:   baseTime + (ms+10)*1e6,
+		Attributes:        attrs,
+	}
+	if parent != 0 {
+		s.ParentSpanId = []byte{parent, 1, 2, 3, 4, 5, 6, 7}
+	}
+	return s
+}
+
+func chatSpan(trace, id, parent byte, ms uint64, conversation, input, output string) *tracepb.Span {
+	attrs := []*commonpb.KeyValue{
+		attr("gen_ai.operation.name", "chat"),
+		attr("gen_ai.input.messages", input),
+		attr("gen_ai.output.messages", output),
+	}
+	if conversation != "" {
+		attrs = append(attrs, attr("gen_ai.conversation.id", conversation))
+	}
+	return testSpan(trace, id, parent, ms, attrs...)
+}
+
+func export(resource []*commonpb.KeyValue, spans ...*tracepb.Span) *coltracepb.ExportTraceServiceRequest {
+	return &coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{{
+			Resource:   &resourcepb.Resource{Attributes: resource},
+			ScopeSpans: []*tracepb.ScopeSpans{{Spans: spans}},
+		}},
+	}
+}
+
+func roles(turns []domain.Turn) string {
+	var parts []string
+	for _, t := range turns {
+		parts = append(parts, t.Role+":"+t.Content)
+	}
+	return strings.Join(parts, " | ")
+}
+
+func metadata(t *testing.T, conv *domain.Conversation) Metadata {
+	t.Helper()
+	var meta Metadata
+	if err := json.Unmarshal(conv.Metadata, &meta); err != nil {
+		t.Fatalf("unmarshal metadata: %v", err)
+	}
+	return meta
+}
+
+func TestConvertGroupsByConversation(t *testing.T) {
+	req := export(
+		[]*commonpb.KeyValue{attr("service.name", "agent"), attr("service.version", "2.1.0")},
+		// An agent span names the conversation; its child chat inherits it.
+		testSpan(1, 1, 0, 0, attr("gen_ai.operation.name", "invoke_agent"), attr("gen_ai.conversation.id", "conv-a")),
+		chatSpan(1, 2, 1, 10, "",
+			`[{"role":"system","content":"be brief"},{"role":"user","content":"hi"}]`,
+			`[{"role":"assistant","content":"hello"}]`),
+		// A later call resends the history.
+		chatSpan(2, 3, 0, 100, "conv-a",
+			`[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"},{"role":"user","content":"bye"}]`,
+			`[{"role":"assistant","content":"goodbye"}]`),
+		// No conversation attribute: grouped by trace.
+		chatSpan(3, 4, 0, 0, "", `[{"role":"user","content":"other"}]`, `[{"role":"assistant","content":"reply"}]`),
+		// Not a GenAI span.
+		testSpan(3, 5, 0, 0, attr("http.method", "GET")),
+	)
+
+	convs := Convert(req)
+	if len(convs) != 2 {
+		t.Fatalf("Convert returned %d conversations, want 2", len(convs))
+	}
+
+	a := convs[0]
+	if a.ID != "conv-a" {
+		t.Errorf("ID = %q, want conv-a", a.ID)
+	}
+	if a.AgentVersion != "2.1.0" {
+		t.Errorf("AgentVersion = %q, want 2.1.0", a.AgentVersion)
+	}
+	if got, want := roles(a.Turns), "user:hi | assistant:hello | user:bye | assistant:goodbye"; got != want {
+		t.Errorf("turns = %s, want %s", got, want)
+	}
+	for i, turn := range a.Turns {
+		if turn.TurnID != i+1 {
+			t.Errorf("turn %d has TurnID %d", i, turn.TurnID)
+		}
+	}
+	meta := metadata(t, a)
+	if meta.Source != Source || meta.ServiceName != "agent" || len(meta.TraceIDs) != 2 {
+		t.Errorf("metadata = %+v, want otlp source, agent service and two traces", meta)
+	}
+
+	b := convs[1]
+	if b.ID != "03"+"0102030405060708090a0b0c0d0e0f" {
+		t.Errorf("ID = %q, want the trace ID", b.ID)
+	}
+}
+
+func TestConvertToolCalls(t *testing.T) {
+	req := export(nil,
+		chatSpan(1, 1, 0, 0, "conv-tools",
+			`[{"role":"user","content":"weather in Paris?"}]`,
+			`[{"role":"assistant","tool_calls":[{"id":"call-1","function":{"name":"weather","arguments":"{\"city\":\"Paris\"}"}}]}]`),
+		testSpan(1, 2, 0, 20,
+			attr("gen_ai.operation.name", "execute_tool"),
+			attr("gen_ai.conversation.id", "conv-tools"),
+			attr("gen_ai.tool.name", "weather"),
+			attr("gen_ai.tool.call.id", "call-1"),
+			attr("gen_ai.tool.call.result", `{"temp":21}`)),
+		chatSpan(1, 3, 0, 40, "conv-tools",
+			`[{"role":"user","content":"weather in Paris?"},{"role":"tool","tool_call_id":"call-1","content":"{\"temp\":21}"}]`,
+			`[{"role":"assistant","content":"It is 21 degrees."}]`),
+	)
+
+	convs := Convert(req)
+	if len(convs) != 1 {
+		t.Fatalf("Convert returned %d conversations, want 1", len(convs))
+	}
+	conv := convs[0]
+	if conv.AgentVersion != UnknownAgentVersion {
+		t.Errorf("AgentVersion = %q, want %q", conv.AgentVersion, UnknownAgentVersion)
+	}
+	if len(conv.Turns) != 3 {
+		t.Fatalf("turns = %s, want user, tool call and answer", roles(conv.Turns))
+	}
+	calls := conv.Turns[1].ToolCalls
+	if len(calls) != 1 || calls[0].ToolName != "weather" {
+		t.Fatalf("tool calls = %+v, want one weather call", calls)
+	}
+	if string(calls[0].Parameters) != `{"city":"Paris"}` {
+		t.Errorf("parameters = %s", calls[0].Parameters)
+	}
+	if r := calls[0].Result; r == nil || r.Status != "success" || string(r.Data) != `{"temp":21}` {
+		t.Errorf("result = %+v, want the tool span's result", r)
+	}
+	if calls[0].LatencyMs != 10 {
+		t.Errorf("latency = %dms, want 10ms", calls[0].LatencyMs)
+	}
+}
+
+func TestConvertLongIDs(t *testing.T) {
+	longID := strings.Repeat("x", maxIDLength+1)
+	longVersion := strings.Repeat("v", maxVersionLength-1) + "é"
+	req := export(
+		[]*commonpb.KeyValue{attr("service.version", longVersion)},
+		chatSpan(1, 1, 0, 0, longID, `[{"role":"user","content":"hi"}]`, `[{"role":"assistant","content":"hello"}]`),
+	)
+
+	convs := Convert(req)
+	if len(convs) != 1 {
+		t.Fatalf("Convert returned %d conversations, want 1", len(convs))
+	}
+	conv := convs[0]
+	if conv.ID != hashID(longID) || len(conv.ID) > maxIDLength || !strings.HasPrefix(conv.ID, Source+"-") {
+		t.Errorf("ID = %q, want a hash of the long ID", conv.ID)
+	}
+	if meta := metadata(t, conv); meta.ConversationID != longID {
+		t.Errorf("metadata conversation ID = %q, want the original", meta.ConversationID)
+	}
+	// The two-byte character would straddle the limit, so it is dropped.
+	if want := strings.Repeat("v", maxVersionLength-1); conv.AgentVersion != want {
+		t.Errorf("AgentVersion = %q, want %q", conv.AgentVersion, want)
+	}
+
+	short := Convert(export(nil, chatSpan(1, 1, 0, 0, "short", `[{"role":"user","content":"hi"}]`, "[]")))
+	if len(short) != 1 || short[0].ID != "short" || metadata(t, short[0]).ConversationID != "" {
+		t.Errorf("short ID converted to %+v, want it kept", short)
+	}
+}
+
+func TestConvertWithoutGenAISpans(t *testing.T) {
+	req := export(nil, testSpan(1, 1, 0, 0, attr("http.method", "GET")))
+	if convs := Convert(req); len(convs) != 0 {
+		t.Errorf("Convert returned %d conversations, want none", len(convs))
+	}
+}
+
+func TestMerge(t *testing.T) {
+	first := Convert(export(
+		[]*commonpb.KeyValue{attr("service.name", "agent"), attr("service.version", "1.0")},
+		chatSpan(1, 1, 0, 0, "conv-m",
+			`[{"role":"user","content":"weather?"}]`,
+			`[{"role":"assistant","tool_calls":[{"id":"call-1","function":{"name":"weather","arguments":"{}"}}]}]`),
+	))[0]
+	first.Feedback = &domain.Feedback{OpsReview: &domain.OpsReview{Quality: "good"}}
+
+	// The next export resends the history, runs the tool and answers.
+	second := Convert(export(nil,
+		testSpan(2, 2, 0, 20,
+			attr("gen_ai.operation.name", "execute_tool"),
+			attr("gen_ai.conversation.id", "conv-m"),
+			attr("gen_ai.tool.name", "weather"),
+			attr("gen_ai.tool.call.result", "sunny")),
+		chatSpan(2, 3, 0, 40, "conv-m",
+			`[{"role":"user","content":"weather?"}]`,
+			`[{"role":"assistant","content":"It is sunny."}]`),
+	))[0]
+
+	merged, err := Merge(first, second)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if got, want := roles(merged.Turns), "user:weather? | assistant: | assistant:It is sunny."; got != want {
+		t.Fatalf("turns = %s, want %s", got, want)
+	}
+	call := merged.Turns[1].ToolCalls[0]
+	if call.Result == nil || string(call.Result.Data) != `"sunny"` {
+		t.Errorf("result = %+v, want the later export's result on the stored call", call.Result)
+	}
+	for i, turn := range merged.Turns {
+		if turn.TurnID != i+1 {
+			t.Errorf("turn %d has TurnID %d", i, turn.TurnID)
+		}
+	}
+	if merged.AgentVersion != "1.0" {
+		t.Errorf("AgentVersion = %q, want the stored 1.0", merged.AgentVersion)
+	}
+	if merged.Feedback == nil || merged.Feedback.OpsReview.Quality != "good" {
+		t.Errorf("feedback = %+v, want the stored feedback kept", merged.Feedback)
+	}
+	meta := metadata(t, merged)
+	if meta.ServiceName != "agent" || len(meta.TraceIDs) != 2 {
+		t.Errorf("metadata = %+v, want agent service and both traces", meta)
+	}
+
+	// Merging the same export again adds nothing.
+	again, err := Merge(merged, second)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if roles(again.Turns) != roles(merged.Turns) {
+		t.Errorf("re-merge turns = %s, want %s", roles(again.Turns), roles(merged.Turns))
+	}
+}
+
+func TestMergeNew(t *testing.T) {
+	incoming := &domain.Conversation{ID: "new"}
+	merged, err := Merge(nil, incoming)
+	if err != nil || merged != incoming {
+		t.Errorf("Merge(nil, incoming) = %v, %v; want incoming", merged, err)
+	}
+}
+
+func TestMergeNotFromTraces(t *testing.T) {
+	existing := &domain.Conversation{
+		ID:       "api-1",
+		Turns:    []domain.Turn{{TurnID: 1, Role: "user", Content: "stored"}},
+		Metadata: json.RawMessage(`{"source":"api"}`),
+	}
+	incoming := Convert(export(nil,
+		chatSpan(1, 1, 0, 0, "api-1", `[{"role":"user","content":"hijack"}]`, "[]")))[0]
+
+	if _, err := Merge(existing, incoming); !errors.Is(err, ErrNotFromTraces) {
+		t.Errorf("Merge error = %v, want ErrNotFromTraces", err)
+	}
+	existing.Metadata = nil
+	if _, err := Merge(existing, incoming); !errors.Is(err, ErrNotFromTraces) {
+		t.Errorf("Merge without metadata error = %v, want ErrNotFromTraces", err)
+	}
+}
@@ -0,0 +1,385 @@
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// message is a chat message normalised from any of the encodings the GenAI
+// semantic conventions have used.
+type message struct {
+	Role       string
+	Content    string
+	ToolCalls  []toolCall
+	ToolCallID string // set on tool results
+}
+
+type toolCall struct {
+	ID        string
+	Name      string
+	Arguments json.RawMessage
+}
+
+// rawMessage is the union of the message shapes found in span attributes
+// and events: the current parts-based form, OpenAI-style chat messages and
+// the gen_ai.choice event body.
+type rawMessage struct {
+	Role       string          `json:"role"`
+	Content    json.RawMessage `json:"content"`
+	Parts      []rawPart       `json:"parts"`
+	ToolCalls  []rawToolCall   `json:"tool_calls"`
+	ToolCallID string          `json:"tool_call_id"`
+	ID         string          `json:"id"`
+	Message    *rawMessage     `json:"message"`
+}
+
+type rawPart struct {
+	Type      string          `json:"type"`
+	Content   json.RawMessage `json:"content"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+	Response  json.RawMessage `json:"response"`
+}
+
+type rawToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+	Function  *struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+func (m rawMessage) normalise(defaultRole string) message {
+	if m.Message != nil {
+		// gen_ai.choice wraps the message.
+		inner := *m.Message
+		if inner.Role == "" {
+			inner.Role = m.Role
+		}
+		return inner.normalise(defaultRole)
+	}
+
+	msg := message{Role: normaliseRole(m.Role), ToolCallID: m.ToolCallID}
+	if msg.Role == "" {
+		msg.Role = defaultRole
+	}
+	if msg.Role == "tool" && msg.ToolCallID == "" {
+		msg.ToolCallID = m.ID
+	}
+
+	var text []string
+	if s := contentText(m.Content); s != "" {
+		text = append(text, s)
+	}
+	for _, p := range m.Parts {
+		switch p.Type {
+		case "tool_call":
+			msg.ToolCalls = append(msg.ToolCalls, toolCall{ID: p.ID, Name: p.Name, Arguments: jsonValue(p.Arguments)})
+		case "tool_call_response":
+			msg.ToolCallID = p.ID
+			text = append(text, string(jsonValue(p.Response)))
+		default:
+			if s := contentText(p.Content); s != "" {
+				text = append(text, s)
+			} else if p.Text != "" {
+				text = append(text, p.Text)
+			}
+		}
+	}
+	msg.Content = strings.Join(text, "\n")
+
+	for _, tc := range m.ToolCalls {
+		call := toolCall{ID: tc.ID, Name: tc.Name, Arguments: jsonValue(tc.Arguments)}
+		if tc.Function != nil {
+			call.Name = tc.Function.Name
+			call.Arguments = jsonValue(tc.Function.Arguments)
+		}
+		msg.ToolCalls = append(msg.ToolCalls, call)
+	}
+
+	return msg
+}
+
+func normaliseRole(role string) string {
+	switch role {
+	case "human":
+		return "user"
+	case "model", "ai":
+		return "assistant"
+	}
+	return role
+}
+
+// contentText returns message content given as a string or as a list of
+// text parts.
+func contentText(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+
+	var parts []rawPart
+	if json.Unmarshal(raw, &parts) == nil {
+		var text []string
+		for _, p := range parts {
+			if p.Text != "" {
+				text = append(text, p.Text)
+			} else if s := contentText(p.Content); s != "" {
+				text = append(text, s)
+			}
+		}
+		return strings.Join(text, "\n")
+	}
+
+	return string(raw)
+}
+
+// jsonValue returns raw as JSON, unwrapping strings that themselves hold
+// JSON, as tool arguments usually do.
+func jsonValue(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 || string(raw) == "null" {
+		return json.RawMessage("{}")
+	}
+
+	var s string
+	if json.Unmarshal(raw, &s) == nil && json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	return raw
+}
+
+// spanMessages returns the messages a GenAI span sent and received, reading
+// whichever encoding the instrumentation used: gen_ai.input.messages and
+// gen_ai.output.messages attributes, indexed gen_ai.prompt.N.* and
+// gen_ai.completion.N.* attributes, or per-message span events.
+func spanMessages(s *span) (input, output []message) {
+	if v, ok := s.attrs["gen_ai.input.messages"]; ok {
+		input = decodeMessages(v, "user")
+	}
+	if v, ok := s.attrs["gen_ai.output.messages"]; ok {
+		output = decodeMessages(v, "assistant")
+	}
+	if input != nil || output != nil {
+		return input, output
+	}
+
+	input = indexedMessages(s.attrs, "gen_ai.prompt.", "user")
+	output = indexedMessages(s.attrs, "gen_ai.completion.", "assistant")
+	if input != nil || output != nil {
+		return input, output
+	}
+
+	for _, e := range s.span.Events {
+		attrs := attributes(e.Attributes)
+		switch e.Name {
+		case "gen_ai.system.message", "gen_ai.user.message", "gen_ai.assistant.message", "gen_ai.tool.message":
+			role := strings.TrimSuffix(strings.TrimPrefix(e.Name, "gen_ai."), ".message")
+			input = append(input, eventMessage(attrs, role))
+		case "gen_ai.choice":
+			output = append(output, eventMessage(attrs, "assistant"))
+		case "gen_ai.content.prompt":
+			input = append(input, decodeMessages(attrs["gen_ai.prompt"], "user")...)
+		case "gen_ai.content.completion":
+			output = append(output, decodeMessages(attrs["gen_ai.completion"], "assistant")...)
+		}
+	}
+	return input, output
+}
+
+// decodeMessages reads a list of messages held in an attribute, either as a
+// JSON string or as structured attribute values.
+func decodeMessages(v interface{}, defaultRole string) []message {
+	var data []byte
+	if s, ok := v.(string); ok {
+		data = []byte(s)
+	} else {
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil
+		}
+	}
+
+	var raw []rawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+
+	msgs := make([]message, len(raw))
+	for i, m := range raw {
+		msgs[i] = m.normalise(defaultRole)
+	}
+	return msgs
+}
+
+// eventMessage reads a message event, whose body is carried either as JSON
+// in gen_ai.event.content or as the event's own attributes.
+func eventMessage(attrs map[string]interface{}, role string) message {
+	var raw rawMessage
+	if body, ok := attrs["gen_ai.event.content"].(string); ok {
+		json.Unmarshal([]byte(body), &raw)
+	} else if data, err := json.Marshal(attrs); err == nil {
+		json.Unmarshal(data, &raw)
+	}
+	if tc, ok := attrs["tool_calls"].(string); ok && raw.ToolCalls == nil {
+		json.Unmarshal([]byte(tc), &raw.ToolCalls)
+	}
+	if raw.Role == "" {
+		raw.Role = role
+	}
+	return raw.normalise(role)
+}
+
+// indexedMessages reads messages flattened into attributes such as
+// gen_ai.prompt.0.role, gen_ai.prompt.0.content and
+// gen_ai.completion.0.tool_calls.0.name.
+func indexedMessages(attrs map[string]interface{}, prefix, defaultRole string) []message {
+	byIndex := make(map[int]*rawMessage)
+	calls := make(map[int]map[int]*rawToolCall)
+
+	for key, v := range attrs {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		idx, field, ok := strings.Cut(strings.TrimPrefix(key, prefix), ".")
+		i, err := strconv.Atoi(idx)
+		if !ok || err != nil {
+			continue
+		}
+
+		m, ok := byIndex[i]
+		if !ok {
+			m = &rawMessage{}
+			byIndex[i] = m
+		}
+		s := fmt.Sprint(v)
+
+		switch field {
+		case "role":
+			m.Role = s
+		case "content":
+			m.Content, _ = json.Marshal(s)
+		case "tool_call_id":
+			m.ToolCallID = s
+		default:
+			rest, ok := strings.CutPrefix(field, "tool_calls.")
+			if !ok {
+				continue
+			}
+			idx, name, ok := strings.Cut(rest, ".")
+			j, err := strconv.Atoi(idx)
+			if !ok || err != nil {
+				continue
+			}
+			if calls[i] == nil {
+				calls[i] = make(map[int]*rawToolCall)
+			}
+			tc, ok := calls[i][j]
+			if !ok {
+				tc = &rawToolCall{}
+				calls[i][j] = tc
+			}
+			switch name {
+			case "id":
+				tc.ID = s
+			case "name":
+				tc.Name = s
+			case "arguments":
+				tc.Arguments, _ = json.Marshal(s)
+			}
+		}
+	}
+	if len(byIndex) == 0 {
+		return nil
+	}
+
+	indexes := make([]int, 0, len(byIndex))
+	for i := range byIndex {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	msgs := make([]message, 0, len(indexes))
+	for _, i := range indexes {
+		m := byIndex[i]
+		callIndexes := make([]int, 0, len(calls[i]))
+		for j := range calls[i] {
+			callIndexes = append(callIndexes, j)
+		}
+		sort.Ints(callIndexes)
+		for _, j := range callIndexes {
+			m.ToolCalls = append(m.ToolCalls, *calls[i][j])
+		}
+		msgs = append(msgs, m.normalise(defaultRole))
+	}
+	return msgs
+}
+
+// attributes converts OTLP key-values to plain Go values.
+func attributes(kvs []*commonpb.KeyValue) map[string]interface{} {
+	attrs := make(map[string]interface{}, len(kvs))
+	for _, kv := range kvs {
+		attrs[kv.Key] = anyValue(kv.Value)
+	}
+	return attrs
+}
+
+func anyValue(v *commonpb.AnyValue) interface{} {
+	if v == nil {
+		return nil
+	}
+	switch val := v.Value.(type) {
+	case *commonpb.AnyValue_StringValue:
+		return val.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return val.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return val.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return val.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(val.BytesValue)
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]interface{}, len(val.ArrayValue.GetValues()))
+		for i, item := range val.ArrayValue.GetValues() {
+			values[i] = anyValue(item)
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		return attributes(val.KvlistValue.GetValues())
+	}
+	return nil
+}
+
+// stringAttr returns the string form of a scalar attribute, or "".
+func stringAttr(attrs map[string]interface{}, key string) string {
+	switch v := attrs[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}, map[string]interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func isError(s *tracepb.Span) bool {
+	return s.GetStatus().GetCode() == tracepb.Status_STATUS_CODE_ERROR
+}
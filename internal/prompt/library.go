@@ -0,0 +1,175 @@
+// Package prompt renders the LLM prompts used by evaluators and the
+// improvement suggester from versioned text/template files.
+//
+// Templates are embedded from templates/<name>.tmpl, where the name carries
+// its version (e.g. "llm_judge.v1"). A file with the same name in the
+// override directory replaces the embedded one, and every rendered prompt
+// carries a content hash so score changes can be traced to prompt changes.
+package prompt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+const templateExt = ".tmpl"
+
+//go:embed templates/*.tmpl
+var embedded embed.FS
+
+var funcs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"join":  strings.Join,
+	"inc":   func(i int) int { return i + 1 },
+	"truncate": func(n int, s string) string {
+		if len(s) <= n {
+			return s
+		}
+		return s[:n] + "..."
+	},
+}
+
+// Template is a parsed prompt template with its name and content hash.
+type Template struct {
+	Name string
+	Hash string
+	tmpl *template.Template
+}
+
+// Rendered is the output of a template together with its provenance.
+type Rendered struct {
+	Text string
+	Name string
+	Hash string
+}
+
+// Parse compiles a template from text. The hash is the first 12 hex chars
+// of the SHA-256 of the text.
+func Parse(name, text string) (*Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template %s: %w", name, err)
+	}
+
+	sum := sha256.Sum256([]byte(text))
+	return &Template{
+		Name: name,
+		Hash: hex.EncodeToString(sum[:])[:12],
+		tmpl: tmpl,
+	}, nil
+}
+
+func (t *Template) Render(data any) (*Rendered, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render template %s: %w", t.Name, err)
+	}
+	return &Rendered{Text: buf.String(), Name: t.Name, Hash: t.Hash}, nil
+}
+
+// Library holds the set of named templates available at runtime.
+type Library struct {
+	templates map[string]*Template
+}
+
+// NewLibrary parses the embedded templates and then any *.tmpl files in
+// overrideDir, which replace embedded templates of the same name. An empty
+// overrideDir uses the embedded templates only.
+func NewLibrary(overrideDir string) (*Library, error) {
+	lib := &Library{templates: make(map[string]*Template)}
+
+	if err := lib.load(embedded, "templates"); err != nil {
+		return nil, err
+	}
+
+	if overrideDir != "" {
+		if _, err := os.Stat(overrideDir); err != nil {
+			return nil, fmt.Errorf("prompt override dir: %w", err)
+		}
+		if err := lib.load(os.DirFS(overrideDir), "."); err != nil {
+			return nil, err
+		}
+	}
+
+	return lib, nil
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultLibrary *Library
+)
+
+// Default returns the library of embedded templates.
+func Default() *Library {
+	defaultOnce.Do(func() {
+		lib, err := NewLibrary("")
+		if err != nil {
+			panic(fmt.Sprintf("prompt: embedded templates: %v", err))
+		}
+		defaultLibrary = lib
+	})
+	return defaultLibrary
+}
+
+func (l *Library) load(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("read templates: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != templateExt {
+			continue
+		}
+
+		text, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			return fmt.Errorf("read template %s: %w", entry.Name(), err)
+		}
+
+		t, err := Parse(strings.TrimSuffix(entry.Name(), templateExt), string(text))
+		if err != nil {
+			return err
+		}
+		l.templates[t.Name] = t
+	}
+
+	return nil
+}
+
+// Get returns the named template, or an error if it does not exist.
+func (l *Library) Get(name string) (*Template, error) {
+	t, ok := l.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("prompt template %q not found", name)
+	}
+	return t, nil
+}
+
+func (l *Library) Render(name string, data any) (*Rendered, error) {
+	t, err := l.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return t.Render(data)
+}
+
+// Names returns the available template names, sorted.
+func (l *Library) Names() []string {
+	names := make([]string, 0, len(l.templates))
+	for name := range l.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,345 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/tracing"
+)
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter is a stream message that could not be processed: its payload
+// was malformed or it exceeded the delivery limit.
+type DeadLetter struct {
+	ID             string    `json:"id"`         // entry ID in the dead-letter stream
+	MessageID      string    `json:"message_id"` // original stream entry ID
+	Priority       Priority  `json:"priority"`   // lane the message was queued on
+	ConversationID string    `json:"conversation_id,omitempty"`
+	Data           string    `json:"data"`
+	Reason         string    `json:"reason"`
+	Deliveries     int64     `json:"deliveries"`
+	DeadAt         time.Time `json:"dead_at"`
+}
+
+// DeadLetterFunc is called after a message is dead-lettered.
+type DeadLetterFunc func(ctx context.Context, letter DeadLetter)
+
+// Conversation decodes the dead letter's payload.
+func (d *DeadLetter) Conversation() (*domain.Conversation, error) {
+	var conv domain.Conversation
+	if err := json.Unmarshal([]byte(d.Data), &conv); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	return &conv, nil
+}
+
+func (q *RedisQueue) deadLetterKey() string {
+	return q.streamName + ":dead"
+}
+
+// Reclaim takes over up to count messages that have been pending on any
+// consumer for longer than the claim idle time, e.g. after a worker crashed.
+// Lanes are scanned from most to least urgent. Messages past the delivery
+// limit are dead-lettered instead of returned.
+func (q *RedisQueue) Reclaim(ctx context.Context, count int64) ([]Message, error) {
+	var messages []Message
+	for _, p := range Priorities {
+		if int64(len(messages)) >= count {
+			break
+		}
+		claimed, err := q.reclaimLane(ctx, p, count-int64(len(messages)))
+		messages = append(messages, claimed...)
+		if err != nil {
+			return messages, err
+		}
+	}
+	return messages, nil
+}
+
+func (q *RedisQueue) reclaimLane(ctx context.Context, lane Priority, count int64) ([]Message, error) {
+	q.claimMu.Lock()
+	start, ok := q.claimCursors[lane]
+	q.claimMu.Unlock()
+	if !ok {
+		start = "0-0"
+	}
+
+	stream := q.streamFor(lane)
+	claimed, next, err := q.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   stream,
+		Group:    q.consumerGroup,
+		Consumer: q.consumerName,
+		MinIdle:  q.claimMinIdle,
+		Start:    start,
+		Count:    count,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("xautoclaim %s: %w", stream, err)
+	}
+
+	q.claimMu.Lock()
+	q.claimCursors[lane] = next
+	q.claimMu.Unlock()
+
+	if len(claimed) == 0 {
+		return nil, nil
+	}
+
+	deliveries, err := q.deliveryCounts(ctx, stream, claimed)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []Message
+	for _, msg := range claimed {
+		if n := deliveries[msg.ID]; q.maxDeliveries > 0 && n > int64(q.maxDeliveries) {
+			reason := fmt.Sprintf("exceeded %d deliveries", q.maxDeliveries)
+			if err := q.deadLetter(ctx, lane, msg, reason, n); err != nil {
+				return messages, err
+			}
+			continue
+		}
+
+		m, err := q.decode(ctx, lane, msg, deliveries[msg.ID])
+		if err != nil {
+			return messages, err
+		}
+		if m != nil {
+			messages = append(messages, *m)
+		}
+	}
+
+	return messages, nil
+}
+
+// deliveryCounts looks up how often each claimed message has been delivered.
+func (q *RedisQueue) deliveryCounts(ctx context.Context, stream string, msgs []redis.XMessage) (map[string]int64, error) {
+	pipe := q.client.Pipeline()
+	cmds := make([]*redis.XPendingExtCmd, len(msgs))
+	for i, msg := range msgs {
+		cmds[i] = pipe.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: stream,
+			Group:  q.consumerGroup,
+			Start:  msg.ID,
+			End:    msg.ID,
+			Count:  1,
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("xpending: %w", err)
+	}
+
+	// A message whose count is unknown must not pass as never delivered,
+	// or it would escape the delivery limit.
+	counts := make(map[string]int64, len(msgs))
+	for _, cmd := range cmds {
+		pending, err := cmd.Result()
+		if err != nil {
+			return nil, fmt.Errorf("xpending: %w", err)
+		}
+		for _, p := range pending {
+			counts[p.ID] = p.RetryCount
+		}
+	}
+	return counts, nil
+}
+
+// decode turns a stream entry into a Message. Entries without a valid
+// conversation payload are dead-lettered and nil is returned.
+func (q *RedisQueue) decode(ctx context.Context, lane Priority, msg redis.XMessage, deliveries int64) (*Message, error) {
+	data, ok := msg.Values["data"].(string)
+	if !ok {
+		return nil, q.deadLetter(ctx, lane, msg, "missing data field", deliveries)
+	}
+
+	var conv domain.Conversation
+	if err := json.Unmarshal([]byte(data), &conv); err != nil {
+		return nil, q.deadLetter(ctx, lane, msg, fmt.Sprintf("unmarshal: %v", err), deliveries)
+	}
+
+	trace := make(map[string]string)
+	for _, field := range tracing.Fields() {
+		if v, ok := msg.Values[field].(string); ok {
+			trace[field] = v
+		}
+	}
+
+	bypass, _ := msg.Values["bypass_cache"].(string)
+
+	return &Message{ID: msg.ID, Priority: lane, Conversation: &conv, Trace: trace, BypassCache: bypass == "1"}, nil
+}
+
+// deadLetter copies msg to the dead-letter stream and acks the original.
+func (q *RedisQueue) deadLetter(ctx context.Context, lane Priority, msg redis.XMessage, reason string, deliveries int64) error {
+	data, _ := msg.Values["data"].(string)
+	conversationID, _ := msg.Values["conversation_id"].(string)
+
+	letter := DeadLetter{
+		MessageID:      msg.ID,
+		Priority:       lane,
+		ConversationID: conversationID,
+		Data:           data,
+		Reason:         reason,
+		Deliveries:     deliveries,
+		DeadAt:         time.Now().UTC(),
+	}
+
+	pipe := q.client.TxPipeline()
+	add := pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.deadLetterKey(),
+		Values: map[string]interface{}{
+			"message_id":      msg.ID,
+			"priority":        string(lane),
+			"conversation_id": conversationID,
+			"data":            data,
+			"reason":          reason,
+			"deliveries":      deliveries,
+			"dead_at":         letter.DeadAt.Format(time.RFC3339Nano),
+		},
+	})
+	pipe.XAck(ctx, q.streamFor(lane), q.consumerGroup, msg.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("dead-letter %s: %w", msg.ID, err)
+	}
+
+	if q.onDeadLetter != nil {
+		letter.ID = add.Val()
+		q.onDeadLetter(ctx, letter)
+	}
+	return nil
+}
+
+// OnDeadLetter registers fn to be called for every message dead-lettered
+// from now on. It must be set before the queue is consumed.
+func (q *RedisQueue) OnDeadLetter(fn DeadLetterFunc) {
+	q.onDeadLetter = fn
+}
+
+// DeadLetters returns up to limit dead letters, newest first.
+func (q *RedisQueue) DeadLetters(ctx context.Context, limit int64) ([]DeadLetter, error) {
+	msgs, err := q.client.XRevRangeN(ctx, q.deadLetterKey(), "+", "-", limit).Result()
+	if err != nil {
+		return nil, fmt.Errorf("xrevrange: %w", err)
+	}
+
+	letters := make([]DeadLetter, len(msgs))
+	for i, msg := range msgs {
+		letters[i] = toDeadLetter(msg)
+	}
+	return letters, nil
+}
+
+func (q *RedisQueue) DeadLetterCount(ctx context.Context) (int64, error) {
+	return q.client.XLen(ctx, q.deadLetterKey()).Result()
+}
+
+func (q *RedisQueue) GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error) {
+	if !validStreamID(id) {
+		return nil, ErrDeadLetterNotFound
+	}
+	msgs, err := q.client.XRange(ctx, q.deadLetterKey(), id, id).Result()
+	if err != nil {
+		return nil, fmt.Errorf("xrange: %w", err)
+	}
+	if len(msgs) == 0 {
+		return nil, ErrDeadLetterNotFound
+	}
+
+	letter := toDeadLetter(msgs[0])
+	return &letter, nil
+}
+
+// RequeueDeadLetter publishes the dead letter's payload to its original lane
+// again and removes it from the dead-letter stream.
+func (q *RedisQueue) RequeueDeadLetter(ctx context.Context, id string) error {
+	letter, err := q.GetDeadLetter(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	pipe := q.client.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.streamFor(letter.Priority),
+		Values: map[string]interface{}{
+			"conversation_id": letter.ConversationID,
+			"data":            letter.Data,
+		},
+	})
+	pipe.XDel(ctx, q.deadLetterKey(), id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("requeue %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// PurgeDeadLetters deletes the given dead letters, or all of them when no
+// IDs are given, and returns how many were removed.
+func (q *RedisQueue) PurgeDeadLetters(ctx context.Context, ids ...string) (int64, error) {
+	if len(ids) == 0 {
+		n, err := q.client.XLen(ctx, q.deadLetterKey()).Result()
+		if err != nil {
+			return 0, fmt.Errorf("xlen: %w", err)
+		}
+		if err := q.client.Del(ctx, q.deadLetterKey()).Err(); err != nil {
+			return 0, fmt.Errorf("del: %w", err)
+		}
+		return n, nil
+	}
+
+	var valid []string
+	for _, id := range ids {
+		if validStreamID(id) {
+			valid = append(valid, id)
+		}
+	}
+	if len(valid) == 0 {
+		return 0, nil
+	}
+	n, err := q.client.XDel(ctx, q.deadLetterKey(), valid...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("xdel: %w", err)
+	}
+	return n, nil
+}
+
+// validStreamID reports whether id has the <ms>-<seq> form of a stream
+// entry ID; Redis rejects anything else with an error.
+func validStreamID(id string) bool {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return false
+	}
+	_, msErr := strconv.ParseUint(ms, 10, 64)
+	_, seqErr := strconv.ParseUint(seq, 10, 64)
+	return msErr == nil && seqErr == nil
+}
+
+func toDeadLetter(msg redis.XMessage) DeadLetter {
+	letter := DeadLetter{ID: msg.ID}
+	letter.MessageID, _ = msg.Values["message_id"].(string)
+	letter.Priority = PriorityNormal
+	if s, ok := msg.Values["priority"].(string); ok && s != "" {
+		letter.Priority = Priority(s)
+	}
+	letter.ConversationID, _ = msg.Values["conversation_id"].(string)
+	letter.Data, _ = msg.Values["data"].(string)
+	letter.Reason, _ = msg.Values["reason"].(string)
+
+	if s, ok := msg.Values["deliveries"].(string); ok {
+		letter.Deliveries, _ = strconv.ParseInt(s, 10, 64)
+	}
+	if s, ok := msg.Values["dead_at"].(string); ok {
+		letter.DeadAt, _ = time.Parse(time.RFC3339Nano, s)
+	}
+
+	return letter
+}
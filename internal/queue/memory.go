@@ -0,0 +1,390 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/saisaravanan/healing-eval/internal/config"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/tracing"
+)
+
+// MemoryQueue is an in-process Queue for a single server running API and
+// worker together. Consumed messages stay pending until acked, and are
+// reclaimed and dead-lettered like RedisQueue's; nothing survives a restart.
+type MemoryQueue struct {
+	claimMinIdle  time.Duration
+	maxDeliveries int
+	lanes         *laneScheduler
+
+	mu          sync.Mutex
+	nextID      int64
+	ready       map[Priority][]Message
+	pending     map[string]*pendingMessage
+	deadLetters []DeadLetter // oldest first
+	retries     []scheduledRetry
+	notify      chan struct{} // closed and replaced on every publish
+
+	onDeadLetter DeadLetterFunc
+}
+
+type pendingMessage struct {
+	msg         Message
+	deliveries  int64
+	deliveredAt time.Time
+}
+
+type scheduledRetry struct {
+	id  string
+	job RetryJob
+	at  time.Time
+}
+
+func NewMemoryQueue(workerCfg *config.WorkerConfig) (*MemoryQueue, error) {
+	weights, err := ParsePriorityWeights(workerCfg.PriorityWeights)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MemoryQueue{
+		claimMinIdle:  workerCfg.ClaimMinIdle,
+		maxDeliveries: workerCfg.MaxDeliveries,
+		lanes:         newLaneScheduler(weights),
+		ready:         make(map[Priority][]Message),
+		pending:       make(map[string]*pendingMessage),
+		notify:        make(chan struct{}),
+	}, nil
+}
+
+func (q *MemoryQueue) newID() string {
+	q.nextID++
+	return strconv.FormatInt(q.nextID, 10)
+}
+
+func (q *MemoryQueue) Publish(ctx context.Context, conv *domain.Conversation, priority Priority) error {
+	return q.PublishBatch(ctx, []*domain.Conversation{conv}, priority)
+}
+
+func (q *MemoryQueue) PublishBatch(ctx context.Context, convs []*domain.Conversation, priority Priority) error {
+	if priority == "" {
+		priority = PriorityNormal
+	}
+
+	traceFields := tracing.Inject(ctx)
+	bypassCache := llm.CacheBypassed(ctx)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, conv := range convs {
+		c := *conv
+		q.ready[priority] = append(q.ready[priority], Message{ID: q.newID(), Priority: priority, Conversation: &c, Trace: traceFields, BypassCache: bypassCache})
+	}
+
+	q.wake()
+	return nil
+}
+
+// wake unblocks waiting consumers. Callers hold q.mu.
+func (q *MemoryQueue) wake() {
+	close(q.notify)
+	q.notify = make(chan struct{})
+}
+
+// Consume returns up to count messages from one lane, chosen like
+// RedisQueue's, waiting up to blockDuration for the first one to arrive.
+func (q *MemoryQueue) Consume(ctx context.Context, count int64, blockDuration time.Duration) ([]Message, error) {
+	timer := time.NewTimer(blockDuration)
+	defer timer.Stop()
+
+	for {
+		q.mu.Lock()
+		if q.readyLen() > 0 {
+			for _, p := range q.lanes.order() {
+				ready := q.ready[p]
+				if len(ready) == 0 {
+					continue
+				}
+				n := int(count)
+				if n <= 0 || n > len(ready) {
+					n = len(ready)
+				}
+				messages := append([]Message(nil), ready[:n]...)
+				q.ready[p] = ready[n:]
+				now := time.Now()
+				for _, msg := range messages {
+					q.pending[msg.ID] = &pendingMessage{msg: msg, deliveries: 1, deliveredAt: now}
+				}
+				q.mu.Unlock()
+				return messages, nil
+			}
+		}
+		notify := q.notify
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-timer.C:
+			return nil, nil
+		case <-notify:
+		}
+	}
+}
+
+func (q *MemoryQueue) Ack(ctx context.Context, messages ...Message) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, msg := range messages {
+		delete(q.pending, msg.ID)
+	}
+	return nil
+}
+
+// readyLen counts unconsumed messages across lanes. Callers hold q.mu.
+func (q *MemoryQueue) readyLen() int {
+	n := 0
+	for _, ready := range q.ready {
+		n += len(ready)
+	}
+	return n
+}
+
+// Len counts messages not yet acked, read or not, as RedisQueue.Len does.
+func (q *MemoryQueue) Len(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int64(q.readyLen() + len(q.pending)), nil
+}
+
+func (q *MemoryQueue) Close() error {
+	return nil
+}
+
+func (q *MemoryQueue) Reclaim(ctx context.Context, count int64) ([]Message, error) {
+	messages, dead := q.reclaim(count)
+	if q.onDeadLetter != nil {
+		for _, letter := range dead {
+			q.onDeadLetter(ctx, letter)
+		}
+	}
+	return messages, nil
+}
+
+// OnDeadLetter registers fn to be called, outside the queue's lock, for
+// every message dead-lettered from now on.
+func (q *MemoryQueue) OnDeadLetter(fn DeadLetterFunc) {
+	q.onDeadLetter = fn
+}
+
+func (q *MemoryQueue) reclaim(count int64) ([]Message, []DeadLetter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var idle []*pendingMessage
+	for _, p := range q.pending {
+		if now.Sub(p.deliveredAt) >= q.claimMinIdle {
+			idle = append(idle, p)
+		}
+	}
+	sort.Slice(idle, func(i, j int) bool {
+		if ri, rj := laneRank(idle[i].msg.Priority), laneRank(idle[j].msg.Priority); ri != rj {
+			return ri < rj
+		}
+		return idle[i].deliveredAt.Before(idle[j].deliveredAt)
+	})
+
+	var messages []Message
+	var dead []DeadLetter
+	for _, p := range idle {
+		if count > 0 && int64(len(messages)) >= count {
+			break
+		}
+
+		p.deliveries++
+		p.deliveredAt = now
+
+		if q.maxDeliveries > 0 && p.deliveries > int64(q.maxDeliveries) {
+			delete(q.pending, p.msg.ID)
+			data, _ := json.Marshal(p.msg.Conversation)
+			letter := DeadLetter{
+				ID:             q.newID(),
+				MessageID:      p.msg.ID,
+				Priority:       p.msg.Priority,
+				ConversationID: p.msg.Conversation.ID,
+				Data:           string(data),
+				Reason:         fmt.Sprintf("exceeded %d deliveries", q.maxDeliveries),
+				Deliveries:     p.deliveries,
+				DeadAt:         now,
+			}
+			q.deadLetters = append(q.deadLetters, letter)
+			dead = append(dead, letter)
+			continue
+		}
+
+		messages = append(messages, p.msg)
+	}
+
+	return messages, dead
+}
+
+func (q *MemoryQueue) DeadLetters(ctx context.Context, limit int64) ([]DeadLetter, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var letters []DeadLetter
+	for i := len(q.deadLetters) - 1; i >= 0; i-- {
+		if limit > 0 && int64(len(letters)) >= limit {
+			break
+		}
+		letters = append(letters, q.deadLetters[i])
+	}
+	return letters, nil
+}
+
+func (q *MemoryQueue) DeadLetterCount(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int64(len(q.deadLetters)), nil
+}
+
+func (q *MemoryQueue) GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, letter := range q.deadLetters {
+		if letter.ID == id {
+			l := letter
+			return &l, nil
+		}
+	}
+	return nil, ErrDeadLetterNotFound
+}
+
+func (q *MemoryQueue) RequeueDeadLetter(ctx context.Context, id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, letter := range q.deadLetters {
+		if letter.ID != id {
+			continue
+		}
+
+		conv, err := letter.Conversation()
+		if err != nil {
+			return err
+		}
+
+		q.deadLetters = append(q.deadLetters[:i], q.deadLetters[i+1:]...)
+		lane := letter.Priority
+		if lane == "" {
+			lane = PriorityNormal
+		}
+		q.ready[lane] = append(q.ready[lane], Message{ID: q.newID(), Priority: lane, Conversation: conv})
+		q.wake()
+		return nil
+	}
+	return ErrDeadLetterNotFound
+}
+
+func (q *MemoryQueue) PurgeDeadLetters(ctx context.Context, ids ...string) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(ids) == 0 {
+		n := int64(len(q.deadLetters))
+		q.deadLetters = nil
+		return n, nil
+	}
+
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+
+	kept := q.deadLetters[:0]
+	for _, letter := range q.deadLetters {
+		if !remove[letter.ID] {
+			kept = append(kept, letter)
+		}
+	}
+	n := int64(len(q.deadLetters) - len(kept))
+	q.deadLetters = kept
+	return n, nil
+}
+
+func (q *MemoryQueue) ScheduleRetry(ctx context.Context, job RetryJob, at time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job.ScheduledAt = time.Now()
+	q.retries = append(q.retries, scheduledRetry{id: q.newID(), job: job, at: at})
+	q.sortRetries()
+	return nil
+}
+
+// ClaimDueRetries leases due jobs like RedisQueue's: they are rescheduled
+// ClaimMinIdle ahead until passed to CompleteRetry.
+func (q *MemoryQueue) ClaimDueRetries(ctx context.Context, now time.Time, limit int64) ([]RetryJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var jobs []RetryJob
+	for i := range q.retries {
+		if q.retries[i].at.After(now) || limit > 0 && int64(len(jobs)) >= limit {
+			break
+		}
+		q.retries[i].at = now.Add(q.claimMinIdle)
+		job := q.retries[i].job
+		job.lease = q.retries[i].id
+		jobs = append(jobs, job)
+	}
+	q.sortRetries()
+	return jobs, nil
+}
+
+func (q *MemoryQueue) CompleteRetry(ctx context.Context, job RetryJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, r := range q.retries {
+		if r.id == job.lease {
+			q.retries = append(q.retries[:i], q.retries[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (q *MemoryQueue) sortRetries() {
+	sort.SliceStable(q.retries, func(i, j int) bool {
+		return q.retries[i].at.Before(q.retries[j].at)
+	})
+}
+
+func (q *MemoryQueue) PendingRetries(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int64(len(q.retries)), nil
+}
+
+func (q *MemoryQueue) PendingRetriesFor(ctx context.Context, conversationID string) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var n int64
+	for _, r := range q.retries {
+		if r.job.ConversationID == conversationID {
+			n++
+		}
+	}
+	return n, nil
+}
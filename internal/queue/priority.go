@@ -0,0 +1,134 @@
+package queue
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Priority selects the ingestion lane a conversation is queued on. Each lane
+// is its own stream and workers share their reads between lanes by weight.
+type Priority string
+
+const (
+	PriorityHigh   Priority = "high"
+	PriorityNormal Priority = "normal"
+	PriorityBulk   Priority = "bulk"
+)
+
+// Priorities lists the lanes from most to least urgent.
+var Priorities = []Priority{PriorityHigh, PriorityNormal, PriorityBulk}
+
+// laneRank orders lanes from most to least urgent.
+func laneRank(p Priority) int {
+	for i, known := range Priorities {
+		if p == known {
+			return i
+		}
+	}
+	return len(Priorities)
+}
+
+// DefaultPriorityWeights gives high-priority work 8 reads for every 4 normal
+// and 1 bulk read while all lanes have messages.
+var DefaultPriorityWeights = map[Priority]int{
+	PriorityHigh:   8,
+	PriorityNormal: 4,
+	PriorityBulk:   1,
+}
+
+// ParsePriority maps an ingest request's priority to a lane; empty means
+// normal.
+func ParsePriority(s string) (Priority, error) {
+	if s == "" {
+		return PriorityNormal, nil
+	}
+	p := Priority(strings.ToLower(s))
+	for _, known := range Priorities {
+		if p == known {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown priority %q (want high, normal or bulk)", s)
+}
+
+// ParsePriorityWeights parses "high=8,normal=4,bulk=1". Lanes left out keep
+// their default weight; an empty string returns the defaults.
+func ParsePriorityWeights(s string) (map[Priority]int, error) {
+	weights := make(map[Priority]int, len(DefaultPriorityWeights))
+	for p, w := range DefaultPriorityWeights {
+		weights[p] = w
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("priority weight %q: want lane=weight", part)
+		}
+		p, err := ParsePriority(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		w, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || w < 1 {
+			return nil, fmt.Errorf("priority weight %q: weight must be a positive integer", part)
+		}
+		weights[p] = w
+	}
+
+	return weights, nil
+}
+
+// laneScheduler picks the lane to read next with smooth weighted round
+// robin, so lanes are interleaved in proportion to their weights rather than
+// drained one after another.
+type laneScheduler struct {
+	mu      sync.Mutex
+	weights map[Priority]int
+	current map[Priority]int
+}
+
+func newLaneScheduler(weights map[Priority]int) *laneScheduler {
+	if weights == nil {
+		weights = DefaultPriorityWeights
+	}
+	return &laneScheduler{weights: weights, current: make(map[Priority]int)}
+}
+
+// order returns every lane, starting with the one whose turn it is and
+// followed by the rest by weight, so an empty lane falls through to the next.
+func (s *laneScheduler) order() []Priority {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	var picked Priority
+	for _, p := range Priorities {
+		s.current[p] += s.weights[p]
+		total += s.weights[p]
+		if picked == "" || s.current[p] > s.current[picked] {
+			picked = p
+		}
+	}
+	s.current[picked] -= total
+
+	lanes := []Priority{picked}
+	rest := make([]Priority, 0, len(Priorities)-1)
+	for _, p := range Priorities {
+		if p != picked {
+			rest = append(rest, p)
+		}
+	}
+	sort.SliceStable(rest, func(i, j int) bool {
+		return s.weights[rest[i]] > s.weights[rest[j]]
+	})
+
+	return append(lanes, rest...)
+}
@@ -0,0 +1,169 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/saisaravanan/healing-eval/internal/config"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+func TestParsePriorityWeights(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[Priority]int
+		wantErr bool
+	}{
+		{in: "", want: DefaultPriorityWeights},
+		{in: "high=8,normal=4,bulk=1", want: DefaultPriorityWeights},
+		{in: " HIGH = 20 ", want: map[Priority]int{PriorityHigh: 20, PriorityNormal: 4, PriorityBulk: 1}},
+		{in: "bulk=2,", want: map[Priority]int{PriorityHigh: 8, PriorityNormal: 4, PriorityBulk: 2}},
+		{in: "high", wantErr: true},
+		{in: "urgent=3", wantErr: true},
+		{in: "high=0", wantErr: true},
+		{in: "high=x", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePriorityWeights(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParsePriorityWeights(%q) = %v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePriorityWeights(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePriorityWeights(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLaneSchedulerWeights(t *testing.T) {
+	s := newLaneScheduler(DefaultPriorityWeights)
+
+	counts := make(map[Priority]int)
+	run, longest := 0, 0
+	var last Priority
+	for i := 0; i < 13*10; i++ {
+		order := s.order()
+		if len(order) != len(Priorities) {
+			t.Fatalf("order() = %v, want every lane", order)
+		}
+		counts[order[0]]++
+		if order[0] == last {
+			run++
+		} else {
+			run = 1
+		}
+		last = order[0]
+		if run > longest {
+			longest = run
+		}
+	}
+
+	want := map[Priority]int{PriorityHigh: 80, PriorityNormal: 40, PriorityBulk: 10}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("picks = %v, want %v", counts, want)
+	}
+	// Smooth round robin interleaves lanes rather than draining high first.
+	if longest > 2 {
+		t.Errorf("a lane was picked %d times in a row, want at most 2", longest)
+	}
+}
+
+func TestLaneSchedulerFallthrough(t *testing.T) {
+	s := newLaneScheduler(DefaultPriorityWeights)
+	for i := 0; i < 13; i++ {
+		order := s.order()
+		rest := order[1:]
+		for j := 1; j < len(rest); j++ {
+			if DefaultPriorityWeights[rest[j-1]] < DefaultPriorityWeights[rest[j]] {
+				t.Fatalf("order() = %v, want the remaining lanes by weight", order)
+			}
+		}
+	}
+}
+
+func newTestMemoryQueue(t *testing.T, weights string) *MemoryQueue {
+	t.Helper()
+	q, err := NewMemoryQueue(&config.WorkerConfig{PriorityWeights: weights, ClaimMinIdle: time.Minute, MaxDeliveries: 3})
+	if err != nil {
+		t.Fatalf("NewMemoryQueue: %v", err)
+	}
+	return q
+}
+
+func publishN(t *testing.T, q Queue, p Priority, n int) {
+	t.Helper()
+	convs := make([]*domain.Conversation, n)
+	for i := range convs {
+		convs[i] = &domain.Conversation{ID: fmt.Sprintf("%s-%d", p, i)}
+	}
+	if err := q.PublishBatch(context.Background(), convs, p); err != nil {
+		t.Fatalf("publish %s: %v", p, err)
+	}
+}
+
+func TestMemoryQueueWeightedConsume(t *testing.T) {
+	q := newTestMemoryQueue(t, "high=3,normal=2,bulk=1")
+	for _, p := range Priorities {
+		publishN(t, q, p, 20)
+	}
+
+	counts := make(map[Priority]int)
+	for i := 0; i < 6*3; i++ {
+		msgs, err := q.Consume(context.Background(), 1, time.Millisecond)
+		if err != nil {
+			t.Fatalf("consume: %v", err)
+		}
+		if len(msgs) != 1 {
+			t.Fatalf("consumed %d messages, want 1", len(msgs))
+		}
+		counts[msgs[0].Priority]++
+	}
+
+	want := map[Priority]int{PriorityHigh: 9, PriorityNormal: 6, PriorityBulk: 3}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("consumed %v, want %v", counts, want)
+	}
+}
+
+func TestMemoryQueueConsumeEmptyLanes(t *testing.T) {
+	q := newTestMemoryQueue(t, "")
+	publishN(t, q, PriorityBulk, 2)
+
+	// Bulk is read even on high's turn while the other lanes are empty, and
+	// a batch comes from one lane in publish order.
+	msgs, err := q.Consume(context.Background(), 10, time.Millisecond)
+	if err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+	if len(msgs) != 2 || msgs[0].Conversation.ID != "bulk-0" || msgs[1].Conversation.ID != "bulk-1" {
+		t.Fatalf("consumed %+v, want bulk-0 and bulk-1", msgs)
+	}
+
+	msgs, err = q.Consume(context.Background(), 10, time.Millisecond)
+	if err != nil || len(msgs) != 0 {
+		t.Fatalf("consume on an empty queue = %v, %v; want nothing", msgs, err)
+	}
+}
+
+func TestMemoryQueueDefaultPriority(t *testing.T) {
+	q := newTestMemoryQueue(t, "")
+	if err := q.Publish(context.Background(), &domain.Conversation{ID: "c"}, ""); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	msgs, err := q.Consume(context.Background(), 1, time.Millisecond)
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("consume = %v, %v; want one message", msgs, err)
+	}
+	if msgs[0].Priority != PriorityNormal {
+		t.Errorf("priority = %q, want normal", msgs[0].Priority)
+	}
+}
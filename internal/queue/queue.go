@@ -0,0 +1,47 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+// Queue carries conversations from the API to the workers, plus the delayed
+// per-evaluator retry jobs. Messages stay pending until acked; ones that
+// cannot be processed end up as dead letters. RedisQueue is the production implementation;
+// MemoryQueue serves standalone runs.
+type Queue interface {
+	Publish(ctx context.Context, conv *domain.Conversation, priority Priority) error
+	PublishBatch(ctx context.Context, convs []*domain.Conversation, priority Priority) error
+	// Consume reads from the priority lanes by weighted fair scheduling.
+	Consume(ctx context.Context, count int64, blockDuration time.Duration) ([]Message, error)
+	Ack(ctx context.Context, messages ...Message) error
+	// Len counts messages not yet acked: waiting to be read or pending.
+	Len(ctx context.Context) (int64, error)
+	Close() error
+
+	// Reclaim returns messages left pending too long by any consumer,
+	// dead-lettering those past the delivery limit.
+	Reclaim(ctx context.Context, count int64) ([]Message, error)
+
+	DeadLetters(ctx context.Context, limit int64) ([]DeadLetter, error)
+	DeadLetterCount(ctx context.Context) (int64, error)
+	GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error)
+	RequeueDeadLetter(ctx context.Context, id string) error
+	PurgeDeadLetters(ctx context.Context, ids ...string) (int64, error)
+	OnDeadLetter(fn DeadLetterFunc)
+
+	ScheduleRetry(ctx context.Context, job RetryJob, at time.Time) error
+	ClaimDueRetries(ctx context.Context, now time.Time, limit int64) ([]RetryJob, error)
+	CompleteRetry(ctx context.Context, job RetryJob) error
+	PendingRetries(ctx context.Context) (int64, error)
+	// PendingRetriesFor counts the conversation's retry jobs not yet
+	// completed, claimed ones included.
+	PendingRetriesFor(ctx context.Context, conversationID string) (int64, error)
+}
+
+var (
+	_ Queue = (*RedisQueue)(nil)
+	_ Queue = (*MemoryQueue)(nil)
+)
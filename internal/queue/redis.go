@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/saisaravanan/healing-eval/internal/config"
 	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/tracing"
 )
 
 type RedisQueue struct {
@@ -17,6 +20,14 @@ type RedisQueue struct {
 	streamName    string
 	consumerGroup string
 	consumerName  string
+	claimMinIdle  time.Duration
+	maxDeliveries int
+
+	lanes        *laneScheduler
+	onDeadLetter DeadLetterFunc
+
+	claimMu      sync.Mutex
+	claimCursors map[Priority]string // XAUTOCLAIM start ID per lane, resumed between calls
 }
 
 func NewRedisQueue(cfg *config.RedisConfig, workerCfg *config.WorkerConfig) (*RedisQueue, error) {
@@ -73,11 +84,20 @@ func NewRedisQueue(cfg *config.RedisConfig, workerCfg *config.WorkerConfig) (*Re
 		return nil, fmt.Errorf("failed to connect to Redis at %s after %d attempts: %w", redisAddr, maxRetries, lastErr)
 	}
 
+	weights, err := ParsePriorityWeights(workerCfg.PriorityWeights)
+	if err != nil {
+		return nil, err
+	}
+
 	q := &RedisQueue{
 		client:        client,
 		streamName:    workerCfg.StreamName,
 		consumerGroup: workerCfg.ConsumerGroup,
 		consumerName:  workerCfg.ConsumerName,
+		claimMinIdle:  workerCfg.ClaimMinIdle,
+		maxDeliveries: workerCfg.MaxDeliveries,
+		lanes:         newLaneScheduler(weights),
+		claimCursors:  make(map[Priority]string),
 	}
 
 	groupCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
@@ -90,37 +110,32 @@ func NewRedisQueue(cfg *config.RedisConfig, workerCfg *config.WorkerConfig) (*Re
 	return q, nil
 }
 
-func (q *RedisQueue) ensureConsumerGroup(ctx context.Context) error {
-	err := q.client.XGroupCreateMkStream(ctx, q.streamName, q.consumerGroup, "0").Err()
-	if err != nil && err.Error() != "BUSYGROUP Consumer Group name already exists" {
-		return fmt.Errorf("create consumer group: %w", err)
+// streamFor returns the stream backing a priority lane. The normal lane keeps
+// the base stream name so existing deployments drain their backlog.
+func (q *RedisQueue) streamFor(p Priority) string {
+	if p == PriorityNormal || p == "" {
+		return q.streamName
 	}
-	return nil
+	return q.streamName + ":" + string(p)
 }
 
-func (q *RedisQueue) Publish(ctx context.Context, conv *domain.Conversation) error {
-	data, err := json.Marshal(conv)
-	if err != nil {
-		return fmt.Errorf("marshal: %w", err)
-	}
-
-	_, err = q.client.XAdd(ctx, &redis.XAddArgs{
-		Stream: q.streamName,
-		Values: map[string]interface{}{
-			"conversation_id": conv.ID,
-			"data":            string(data),
-		},
-	}).Result()
-
-	if err != nil {
-		return fmt.Errorf("xadd: %w", err)
+func (q *RedisQueue) ensureConsumerGroup(ctx context.Context) error {
+	for _, p := range Priorities {
+		err := q.client.XGroupCreateMkStream(ctx, q.streamFor(p), q.consumerGroup, "0").Err()
+		if err != nil && err.Error() != "BUSYGROUP Consumer Group name already exists" {
+			return fmt.Errorf("create consumer group for %s lane: %w", p, err)
+		}
 	}
-
 	return nil
 }
 
-func (q *RedisQueue) PublishBatch(ctx context.Context, convs []*domain.Conversation) error {
+func (q *RedisQueue) Publish(ctx context.Context, conv *domain.Conversation, priority Priority) error {
+	return q.PublishBatch(ctx, []*domain.Conversation{conv}, priority)
+}
+
+func (q *RedisQueue) PublishBatch(ctx context.Context, convs []*domain.Conversation, priority Priority) error {
 	pipe := q.client.Pipeline()
+	traceFields := tracing.Inject(ctx)
 
 	for _, conv := range convs {
 		data, err := json.Marshal(conv)
@@ -128,12 +143,20 @@ func (q *RedisQueue) PublishBatch(ctx context.Context, convs []*domain.Conversat
 			return fmt.Errorf("marshal %s: %w", conv.ID, err)
 		}
 
+		values := map[string]interface{}{
+			"conversation_id": conv.ID,
+			"data":            string(data),
+		}
+		for k, v := range traceFields {
+			values[k] = v
+		}
+		if llm.CacheBypassed(ctx) {
+			values["bypass_cache"] = "1"
+		}
+
 		pipe.XAdd(ctx, &redis.XAddArgs{
-			Stream: q.streamName,
-			Values: map[string]interface{}{
-				"conversation_id": conv.ID,
-				"data":            string(data),
-			},
+			Stream: q.streamFor(priority),
+			Values: values,
 		})
 	}
 
@@ -147,16 +170,45 @@ func (q *RedisQueue) PublishBatch(ctx context.Context, convs []*domain.Conversat
 
 type Message struct {
 	ID           string
+	Priority     Priority
 	Conversation *domain.Conversation
+	Trace        map[string]string // publisher's trace context, see tracing.Extract
+	BypassCache  bool              // published under llm.WithCacheBypass
 }
 
+// Consume reads up to count new messages from one lane, chosen by weighted
+// round robin; lanes without messages fall through to the next. When every
+// lane is empty it blocks on all of them for up to blockDuration.
 func (q *RedisQueue) Consume(ctx context.Context, count int64, blockDuration time.Duration) ([]Message, error) {
-	streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+	for _, p := range q.lanes.order() {
+		messages, err := q.read(ctx, []Priority{p}, count, -1)
+		if err != nil || len(messages) > 0 {
+			return messages, err
+		}
+	}
+
+	return q.read(ctx, Priorities, count, blockDuration)
+}
+
+// read issues one XREADGROUP over the given lanes; a negative block does not
+// wait.
+func (q *RedisQueue) read(ctx context.Context, lanes []Priority, count int64, block time.Duration) ([]Message, error) {
+	streams := make([]string, 0, len(lanes)*2)
+	laneOf := make(map[string]Priority, len(lanes))
+	for _, p := range lanes {
+		streams = append(streams, q.streamFor(p))
+		laneOf[q.streamFor(p)] = p
+	}
+	for range lanes {
+		streams = append(streams, ">")
+	}
+
+	result, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
 		Group:    q.consumerGroup,
 		Consumer: q.consumerName,
-		Streams:  []string{q.streamName, ">"},
+		Streams:  streams,
 		Count:    count,
-		Block:    blockDuration,
+		Block:    block,
 	}).Result()
 
 	if err != nil {
@@ -167,36 +219,36 @@ func (q *RedisQueue) Consume(ctx context.Context, count int64, blockDuration tim
 	}
 
 	var messages []Message
-	for _, stream := range streams {
+	for _, stream := range result {
 		for _, msg := range stream.Messages {
-			data, ok := msg.Values["data"].(string)
-			if !ok {
-				continue
+			m, err := q.decode(ctx, laneOf[stream.Stream], msg, 1)
+			if err != nil {
+				return messages, err
 			}
-
-			var conv domain.Conversation
-			if err := json.Unmarshal([]byte(data), &conv); err != nil {
-				continue
+			if m != nil {
+				messages = append(messages, *m)
 			}
-
-			messages = append(messages, Message{
-				ID:           msg.ID,
-				Conversation: &conv,
-			})
 		}
 	}
 
 	return messages, nil
 }
 
-func (q *RedisQueue) Ack(ctx context.Context, messageIDs ...string) error {
-	if len(messageIDs) == 0 {
+func (q *RedisQueue) Ack(ctx context.Context, messages ...Message) error {
+	if len(messages) == 0 {
 		return nil
 	}
 
-	_, err := q.client.XAck(ctx, q.streamName, q.consumerGroup, messageIDs...).Result()
-	if err != nil {
-		return fmt.Errorf("xack: %w", err)
+	byStream := make(map[string][]string)
+	for _, msg := range messages {
+		stream := q.streamFor(msg.Priority)
+		byStream[stream] = append(byStream[stream], msg.ID)
+	}
+
+	for stream, ids := range byStream {
+		if err := q.client.XAck(ctx, stream, q.consumerGroup, ids...).Err(); err != nil {
+			return fmt.Errorf("xack: %w", err)
+		}
 	}
 
 	return nil
@@ -206,8 +258,34 @@ func (q *RedisQueue) Close() error {
 	return q.client.Close()
 }
 
+// Len returns the number of messages not yet acked across all lanes: those
+// the consumer group has yet to read (its lag) and those read but pending.
+// Acked entries stay in the streams, so XLEN would overcount.
 func (q *RedisQueue) Len(ctx context.Context) (int64, error) {
-	return q.client.XLen(ctx, q.streamName).Result()
+	var total int64
+	for _, p := range Priorities {
+		groups, err := q.client.XInfoGroups(ctx, q.streamFor(p)).Result()
+		if err != nil {
+			return 0, fmt.Errorf("xinfo groups %s: %w", p, err)
+		}
+		for _, g := range groups {
+			if g.Name == q.consumerGroup {
+				total += g.Lag + g.Pending
+			}
+		}
+	}
+	return total, nil
+}
+
+// TryLease takes the named lease for ttl if no process holds it, so that a
+// periodic job runs on one replica only. The lease is not released early;
+// it lapses after ttl.
+func (q *RedisQueue) TryLease(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	ok, err := q.client.SetNX(ctx, q.streamName+":lease:"+name, q.consumerName, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("setnx: %w", err)
+	}
+	return ok, nil
 }
 
 func (q *RedisQueue) Client() *redis.Client {
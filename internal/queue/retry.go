@@ -0,0 +1,134 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+// RetryJob re-runs a single evaluator for a conversation. Attempt is the
+// retry number (1 for the first retry).
+type RetryJob struct {
+	ConversationID string               `json:"conversation_id"`
+	EvaluatorType  domain.EvaluatorType `json:"evaluator_type"`
+	Attempt        int                  `json:"attempt"`
+	LastError      string               `json:"last_error"`
+	ScheduledAt    time.Time            `json:"scheduled_at"`
+
+	lease string // identifies the claimed entry for CompleteRetry
+}
+
+// retryKey is a sorted set of pending retry jobs scored by due time (unix ms).
+func (q *RedisQueue) retryKey() string {
+	return q.streamName + ":retries"
+}
+
+// retryCountKey is a hash counting the pending retry jobs of each
+// conversation.
+func (q *RedisQueue) retryCountKey() string {
+	return q.streamName + ":retries:conversations"
+}
+
+// ScheduleRetry enqueues job to become due at the given time.
+func (q *RedisQueue) ScheduleRetry(ctx context.Context, job RetryJob, at time.Time) error {
+	job.ScheduledAt = time.Now()
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	if _, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, q.retryKey(), redis.Z{
+			Score:  float64(at.UnixMilli()),
+			Member: string(data),
+		})
+		pipe.HIncrBy(ctx, q.retryCountKey(), job.ConversationID, 1)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("zadd: %w", err)
+	}
+
+	return nil
+}
+
+// claimRetriesScript leases up to ARGV[2] jobs due by ARGV[1] by moving
+// their score to ARGV[3], the lease expiry. Running as one script, each job
+// is claimed by exactly one worker.
+var claimRetriesScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, member in ipairs(due) do
+	redis.call('ZADD', KEYS[1], 'XX', ARGV[3], member)
+end
+return due
+`)
+
+// ClaimDueRetries leases and returns up to limit retry jobs that are due.
+// A claimed job stays scheduled, ClaimMinIdle in the future, until it is
+// passed to CompleteRetry; if the worker dies first it becomes due again.
+func (q *RedisQueue) ClaimDueRetries(ctx context.Context, now time.Time, limit int64) ([]RetryJob, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	members, err := claimRetriesScript.Run(ctx, q.client, []string{q.retryKey()},
+		now.UnixMilli(), limit, now.Add(q.claimMinIdle).UnixMilli()).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("claim retries: %w", err)
+	}
+
+	var jobs []RetryJob
+	for _, member := range members {
+		var job RetryJob
+		if err := json.Unmarshal([]byte(member), &job); err != nil {
+			q.client.ZRem(ctx, q.retryKey(), member)
+			continue
+		}
+		job.lease = member
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// completeRetryScript removes the leased job ARGV[1] and, if it was still
+// scheduled, takes it off conversation ARGV[2]'s count.
+var completeRetryScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 1 then
+	if redis.call('HINCRBY', KEYS[2], ARGV[2], -1) <= 0 then
+		redis.call('HDEL', KEYS[2], ARGV[2])
+	end
+end
+return 0
+`)
+
+// CompleteRetry removes a claimed job once it has been handled, whatever
+// the outcome; a further attempt is scheduled as a new job.
+func (q *RedisQueue) CompleteRetry(ctx context.Context, job RetryJob) error {
+	if err := completeRetryScript.Run(ctx, q.client, []string{q.retryKey(), q.retryCountKey()},
+		job.lease, job.ConversationID).Err(); err != nil {
+		return fmt.Errorf("complete retry: %w", err)
+	}
+	return nil
+}
+
+// PendingRetries returns the number of scheduled retry jobs.
+func (q *RedisQueue) PendingRetries(ctx context.Context) (int64, error) {
+	return q.client.ZCard(ctx, q.retryKey()).Result()
+}
+
+// PendingRetriesFor returns the number of retry jobs scheduled or claimed
+// for one conversation.
+func (q *RedisQueue) PendingRetriesFor(ctx context.Context, conversationID string) (int64, error) {
+	n, err := q.client.HGet(ctx, q.retryCountKey(), conversationID).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("hget: %w", err)
+	}
+	return n, nil
+}
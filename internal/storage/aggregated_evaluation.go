@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+type AggregatedEvaluationRepo struct {
+	db *PostgresDB
+}
+
+func NewAggregatedEvaluationRepo(db *PostgresDB) *AggregatedEvaluationRepo {
+	return &AggregatedEvaluationRepo{db: db}
+}
+
+// Upsert stores the conversation's aggregated evaluation, replacing any
+// earlier result (e.g. after a retried evaluator succeeds). Per-evaluator
+// rows live in evaluations and are not duplicated here.
+func (r *AggregatedEvaluationRepo) Upsert(ctx context.Context, agg *domain.AggregatedEvaluation) error {
+	scoresJSON, err := json.Marshal(agg.Scores)
+	if err != nil {
+		return fmt.Errorf("marshal scores: %w", err)
+	}
+
+	tokenUsageJSON, err := json.Marshal(agg.TokenUsage)
+	if err != nil {
+		return fmt.Errorf("marshal token usage: %w", err)
+	}
+
+	failures := agg.FailedEvaluators
+	if failures == nil {
+		failures = []domain.EvaluatorFailure{}
+	}
+	failuresJSON, err := json.Marshal(failures)
+	if err != nil {
+		return fmt.Errorf("marshal failed evaluators: %w", err)
+	}
+
+	var toolEvalJSON []byte
+	if agg.ToolEvaluation != nil {
+		if toolEvalJSON, err = json.Marshal(agg.ToolEvaluation); err != nil {
+			return fmt.Errorf("marshal tool evaluation: %w", err)
+		}
+	}
+
+	issues := agg.Issues
+	if issues == nil {
+		issues = []domain.Issue{}
+	}
+	issuesJSON, err := json.Marshal(issues)
+	if err != nil {
+		return fmt.Errorf("marshal issues: %w", err)
+	}
+
+	if agg.CreatedAt.IsZero() {
+		agg.CreatedAt = time.Now()
+	}
+	agg.UpdatedAt = time.Now()
+
+	_, err = r.db.Pool.Exec(ctx, `
+		INSERT INTO aggregated_evaluations (
+			conversation_id, status, overall_score, scores, token_usage, failed_evaluators,
+			successful_count, expected_count, tool_evaluation, issues, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (conversation_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			overall_score = EXCLUDED.overall_score,
+			scores = EXCLUDED.scores,
+			token_usage = EXCLUDED.token_usage,
+			failed_evaluators = EXCLUDED.failed_evaluators,
+			successful_count = EXCLUDED.successful_count,
+			expected_count = EXCLUDED.expected_count,
+			tool_evaluation = EXCLUDED.tool_evaluation,
+			issues = EXCLUDED.issues,
+			updated_at = EXCLUDED.updated_at
+	`, agg.ConversationID, agg.Status, agg.Scores.Overall, scoresJSON, tokenUsageJSON, failuresJSON,
+		agg.SuccessfulCount, agg.ExpectedCount, toolEvalJSON, issuesJSON, agg.CreatedAt, agg.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("upsert: %w", err)
+	}
+
+	return nil
+}
+
+const aggregatedEvaluationColumns = `
+	conversation_id, status, scores, token_usage, COALESCE(failed_evaluators, '[]'),
+	successful_count, expected_count, tool_evaluation, COALESCE(issues, '[]'), created_at, updated_at`
+
+// GetByConversationID returns the conversation's aggregated evaluation, or
+// nil if it has not been evaluated yet.
+func (r *AggregatedEvaluationRepo) GetByConversationID(ctx context.Context, conversationID string) (*domain.AggregatedEvaluation, error) {
+	row := r.db.Pool.QueryRow(ctx, `
+		SELECT `+aggregatedEvaluationColumns+`
+		FROM aggregated_evaluations
+		WHERE conversation_id = $1
+	`, conversationID)
+
+	agg, err := scanAggregatedEvaluation(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return agg, nil
+}
+
+// List returns aggregated evaluations, newest first. A non-empty issueType
+// keeps only conversations with an issue of that type. The second return
+// value is the total number of matching rows.
+func (r *AggregatedEvaluationRepo) List(ctx context.Context, issueType string, limit, offset int) ([]*domain.AggregatedEvaluation, int, error) {
+	filter := `$1 = '' OR issues @> jsonb_build_array(jsonb_build_object('type', $1::text))`
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM aggregated_evaluations WHERE `+filter,
+		issueType).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT `+aggregatedEvaluationColumns+`
+		FROM aggregated_evaluations
+		WHERE `+filter+`
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, issueType, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var aggs []*domain.AggregatedEvaluation
+	for rows.Next() {
+		agg, err := scanAggregatedEvaluation(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		aggs = append(aggs, agg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows: %w", err)
+	}
+
+	return aggs, total, nil
+}
+
+func scanAggregatedEvaluation(row pgx.Row) (*domain.AggregatedEvaluation, error) {
+	// Per-evaluator rows are served from the evaluations table.
+	agg := domain.AggregatedEvaluation{Evaluations: []domain.Evaluation{}}
+	var scoresJSON, tokenUsageJSON, failuresJSON, toolEvalJSON, issuesJSON []byte
+
+	if err := row.Scan(
+		&agg.ConversationID, &agg.Status, &scoresJSON, &tokenUsageJSON, &failuresJSON,
+		&agg.SuccessfulCount, &agg.ExpectedCount, &toolEvalJSON, &issuesJSON, &agg.CreatedAt, &agg.UpdatedAt,
+	); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	if err := json.Unmarshal(scoresJSON, &agg.Scores); err != nil {
+		return nil, fmt.Errorf("unmarshal scores: %w", err)
+	}
+	if err := json.Unmarshal(tokenUsageJSON, &agg.TokenUsage); err != nil {
+		return nil, fmt.Errorf("unmarshal token usage: %w", err)
+	}
+	if err := json.Unmarshal(failuresJSON, &agg.FailedEvaluators); err != nil {
+		return nil, fmt.Errorf("unmarshal failed evaluators: %w", err)
+	}
+	if toolEvalJSON != nil {
+		agg.ToolEvaluation = &domain.ToolEvaluation{}
+		if err := json.Unmarshal(toolEvalJSON, agg.ToolEvaluation); err != nil {
+			return nil, fmt.Errorf("unmarshal tool evaluation: %w", err)
+		}
+	}
+	if err := json.Unmarshal(issuesJSON, &agg.Issues); err != nil {
+		return nil, fmt.Errorf("unmarshal issues: %w", err)
+	}
+
+	return &agg, nil
+}
@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+// reliabilityPrior is the number of virtual agreeing comparisons every
+// annotator starts with, so a single early disagreement doesn't tank the score.
+const reliabilityPrior = 5.0
+
+type AnnotationRepo struct {
+	db *PostgresDB
+}
+
+func NewAnnotationRepo(db *PostgresDB) *AnnotationRepo {
+	return &AnnotationRepo{db: db}
+}
+
+// CreateBatch stores annotations, registers unknown annotators and refreshes
+// the stats of every annotator who labelled the same items.
+func (r *AnnotationRepo) CreateBatch(ctx context.Context, anns []*domain.Annotation) error {
+	if len(anns) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	affected := make(map[string]bool)
+
+	for _, ann := range anns {
+		if ann.ID == "" {
+			ann.ID = uuid.New().String()
+		}
+		ann.CreatedAt = now
+
+		metadataJSON := ann.Metadata
+		if metadataJSON == nil {
+			metadataJSON = json.RawMessage("{}")
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO annotators (id, name, created_at, updated_at)
+			VALUES ($1, $1, $2, $2)
+			ON CONFLICT (id) DO NOTHING
+		`, ann.AnnotatorID, now); err != nil {
+			return fmt.Errorf("ensure annotator %s: %w", ann.AnnotatorID, err)
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO annotations (id, conversation_id, turn_id, annotator_id, annotation_type, label, confidence, metadata, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, ann.ID, ann.ConversationID, ann.TurnID, ann.AnnotatorID, ann.Type, ann.Label, ann.Confidence, metadataJSON, now); err != nil {
+			return fmt.Errorf("insert annotation: %w", err)
+		}
+
+		rows, err := tx.Query(ctx, `
+			SELECT DISTINCT annotator_id FROM annotations
+			WHERE conversation_id = $1 AND turn_id IS NOT DISTINCT FROM $2 AND annotation_type = $3
+		`, ann.ConversationID, ann.TurnID, ann.Type)
+		if err != nil {
+			return fmt.Errorf("query co-annotators: %w", err)
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan co-annotator: %w", err)
+			}
+			affected[id] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("query co-annotators: %w", err)
+		}
+	}
+
+	ids := make([]string, 0, len(affected))
+	for id := range affected {
+		ids = append(ids, id)
+	}
+
+	if err := r.refreshAnnotatorStats(ctx, tx, ids); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+// refreshAnnotatorStats recomputes total annotations, pairwise agreement with
+// other annotators on the same (conversation, turn, type) item, and a
+// reliability score smoothed towards 1.0 for annotators with few overlaps.
+func (r *AnnotationRepo) refreshAnnotatorStats(ctx context.Context, tx pgx.Tx, annotatorIDs []string) error {
+	if len(annotatorIDs) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		WITH totals AS (
+			SELECT annotator_id, COUNT(*) AS total
+			FROM annotations
+			WHERE annotator_id = ANY($1)
+			GROUP BY annotator_id
+		), pairs AS (
+			SELECT a.annotator_id,
+				COUNT(*) AS compared,
+				SUM(CASE WHEN a.label = b.label THEN 1 ELSE 0 END) AS agreed
+			FROM annotations a
+			JOIN annotations b
+				ON a.conversation_id = b.conversation_id
+				AND a.turn_id IS NOT DISTINCT FROM b.turn_id
+				AND a.annotation_type = b.annotation_type
+				AND a.annotator_id <> b.annotator_id
+			WHERE a.annotator_id = ANY($1)
+			GROUP BY a.annotator_id
+		)
+		UPDATE annotators an SET
+			total_annotations = t.total,
+			agreement_rate = CASE WHEN p.compared > 0 THEN p.agreed::float / p.compared END,
+			reliability_score = (COALESCE(p.agreed, 0) + $2::float8) / (COALESCE(p.compared, 0) + $2::float8),
+			updated_at = NOW()
+		FROM totals t
+		LEFT JOIN pairs p ON p.annotator_id = t.annotator_id
+		WHERE an.id = t.annotator_id
+	`, annotatorIDs, reliabilityPrior)
+
+	if err != nil {
+		return fmt.Errorf("refresh annotator stats: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AnnotationRepo) GetByConversationID(ctx context.Context, conversationID string) ([]*domain.Annotation, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, conversation_id, turn_id, annotator_id, annotation_type, label,
+			COALESCE(confidence, 0), metadata, created_at
+		FROM annotations
+		WHERE conversation_id = $1
+		ORDER BY created_at ASC
+	`, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanAnnotations(rows)
+}
+
+func (r *AnnotationRepo) GetByConversationIDs(ctx context.Context, conversationIDs []string) ([]*domain.Annotation, error) {
+	if len(conversationIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, conversation_id, turn_id, annotator_id, annotation_type, label,
+			COALESCE(confidence, 0), metadata, created_at
+		FROM annotations
+		WHERE conversation_id = ANY($1)
+		ORDER BY created_at ASC
+	`, conversationIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanAnnotations(rows)
+}
+
+// EvaluationDaysAnnotatedSince returns the UTC days holding successful
+// evaluations of conversations annotated at or after since: the days whose
+// accuracy rollup those annotations change.
+func (r *AnnotationRepo) EvaluationDaysAnnotatedSince(ctx context.Context, since time.Time) ([]time.Time, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT DISTINCT date_trunc('day', e.created_at AT TIME ZONE 'UTC') AS day
+		FROM evaluations e
+		WHERE e.status = 'success'
+			AND EXISTS (
+				SELECT 1 FROM annotations a
+				WHERE a.conversation_id = e.conversation_id AND a.created_at >= $1
+			)
+		ORDER BY day ASC
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var days []time.Time
+	for rows.Next() {
+		var day time.Time
+		if err := rows.Scan(&day); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		days = append(days, time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return days, nil
+}
+
+func (r *AnnotationRepo) scanAnnotations(rows pgx.Rows) ([]*domain.Annotation, error) {
+	var anns []*domain.Annotation
+
+	for rows.Next() {
+		var ann domain.Annotation
+		var metadataJSON []byte
+
+		if err := rows.Scan(
+			&ann.ID, &ann.ConversationID, &ann.TurnID, &ann.AnnotatorID, &ann.Type, &ann.Label,
+			&ann.Confidence, &metadataJSON, &ann.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		ann.Metadata = metadataJSON
+		anns = append(anns, &ann)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return anns, nil
+}
+
+func (r *AnnotationRepo) CreateAnnotator(ctx context.Context, a *domain.Annotator) error {
+	now := time.Now()
+
+	err := r.db.Pool.QueryRow(ctx, `
+		INSERT INTO annotators (id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at
+		RETURNING COALESCE(reliability_score, 1.0), COALESCE(total_annotations, 0), COALESCE(agreement_rate, 0), created_at, updated_at
+	`, a.ID, a.Name, now).Scan(&a.ReliabilityScore, &a.TotalAnnotations, &a.AgreementRate, &a.CreatedAt, &a.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("upsert annotator: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AnnotationRepo) GetAnnotator(ctx context.Context, id string) (*domain.Annotator, error) {
+	var a domain.Annotator
+
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT id, COALESCE(name, ''), COALESCE(reliability_score, 1.0), COALESCE(total_annotations, 0),
+			COALESCE(agreement_rate, 0), created_at, updated_at
+		FROM annotators
+		WHERE id = $1
+	`, id).Scan(&a.ID, &a.Name, &a.ReliabilityScore, &a.TotalAnnotations, &a.AgreementRate, &a.CreatedAt, &a.UpdatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return &a, nil
+}
+
+func (r *AnnotationRepo) ListAnnotators(ctx context.Context, limit, offset int) ([]*domain.Annotator, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, COALESCE(name, ''), COALESCE(reliability_score, 1.0), COALESCE(total_annotations, 0),
+			COALESCE(agreement_rate, 0), created_at, updated_at
+		FROM annotators
+		ORDER BY total_annotations DESC, id ASC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var annotators []*domain.Annotator
+	for rows.Next() {
+		var a domain.Annotator
+		if err := rows.Scan(&a.ID, &a.Name, &a.ReliabilityScore, &a.TotalAnnotations, &a.AgreementRate, &a.CreatedAt, &a.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		annotators = append(annotators, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return annotators, nil
+}
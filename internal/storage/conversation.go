@@ -98,10 +98,77 @@ func (r *ConversationRepo) CreateBatch(ctx context.Context, convs []*domain.Conv
 }
 
 func (r *ConversationRepo) GetByID(ctx context.Context, id string) (*domain.Conversation, error) {
+	return getConversation(ctx, r.db.Pool, id)
+}
+
+// Update holds a transaction-scoped advisory lock on the ID, which works
+// whether or not the row exists yet, while it reads, builds and upserts the
+// conversation.
+func (r *ConversationRepo) Update(ctx context.Context, id string, fn func(existing *domain.Conversation) (*domain.Conversation, error)) (*domain.Conversation, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, id); err != nil {
+		return nil, fmt.Errorf("lock: %w", err)
+	}
+
+	existing, err := getConversation(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	conv, err := fn(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	turnsJSON, err := json.Marshal(conv.Turns)
+	if err != nil {
+		return nil, fmt.Errorf("marshal turns: %w", err)
+	}
+	var feedbackJSON []byte
+	if conv.Feedback != nil {
+		if feedbackJSON, err = json.Marshal(conv.Feedback); err != nil {
+			return nil, fmt.Errorf("marshal feedback: %w", err)
+		}
+	}
+	metadataJSON := conv.Metadata
+	if metadataJSON == nil {
+		metadataJSON = json.RawMessage("{}")
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO conversations (id, agent_version, turns, feedback, metadata, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE SET
+			agent_version = EXCLUDED.agent_version,
+			turns = EXCLUDED.turns,
+			feedback = COALESCE(EXCLUDED.feedback, conversations.feedback),
+			metadata = EXCLUDED.metadata
+	`, conv.ID, conv.AgentVersion, turnsJSON, feedbackJSON, metadataJSON, time.Now()); err != nil {
+		return nil, fmt.Errorf("upsert: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return conv, nil
+}
+
+// rowQuerier is satisfied by both the pool and a transaction.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// getConversation reads one conversation, returning nil if it does not
+// exist.
+func getConversation(ctx context.Context, q rowQuerier, id string) (*domain.Conversation, error) {
 	var conv domain.Conversation
 	var turnsJSON, feedbackJSON, metadataJSON []byte
 
-	err := r.db.Pool.QueryRow(ctx, `
+	err := q.QueryRow(ctx, `
 		SELECT id, agent_version, turns, feedback, metadata, created_at, processed_at
 		FROM conversations
 		WHERE id = $1
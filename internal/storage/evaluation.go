@@ -38,15 +38,15 @@ func (r *EvaluationRepo) Create(ctx context.Context, eval *domain.Evaluation) er
 	_, err = r.db.Pool.Exec(ctx, `
 		INSERT INTO evaluations (
 			id, conversation_id, evaluator_type, 
-			status, model_name, prompt_tokens, completion_tokens, total_tokens, 
+			status, model_name, provider, prompt_tokens, completion_tokens, total_tokens, 
 			estimated_cost_usd, error_message,
-			scores, issues, confidence, raw_output, latency_ms, created_at
+			scores, issues, confidence, raw_output, latency_ms, prompt_template, prompt_hash, retry_count, cached, run_id, created_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, NULLIF($21, ''), $22)
 	`, eval.ID, eval.ConversationID, eval.EvaluatorType,
-		eval.Status, eval.ModelName, eval.PromptTokens, eval.CompletionTokens, eval.TotalTokens,
+		eval.Status, eval.ModelName, eval.Provider, eval.PromptTokens, eval.CompletionTokens, eval.TotalTokens,
 		eval.EstimatedCostUSD, eval.ErrorMessage,
-		scoresJSON, issuesJSON, eval.Confidence, eval.RawOutput, eval.LatencyMs, time.Now())
+		scoresJSON, issuesJSON, eval.Confidence, eval.RawOutput, eval.LatencyMs, eval.PromptTemplate, eval.PromptHash, eval.RetryCount, eval.Cached, eval.RunID, time.Now())
 
 	if err != nil {
 		return fmt.Errorf("insert: %w", err)
@@ -67,18 +67,21 @@ func (r *EvaluationRepo) CreateBatch(ctx context.Context, evals []*domain.Evalua
 		scoresJSON, _ := json.Marshal(eval.Scores)
 		issuesJSON, _ := json.Marshal(eval.Issues)
 
+		// A run's evaluations are stored once, however often its message
+		// is delivered.
 		batch.Queue(`
 			INSERT INTO evaluations (
 				id, conversation_id, evaluator_type, 
-				status, model_name, prompt_tokens, completion_tokens, total_tokens, 
+				status, model_name, provider, prompt_tokens, completion_tokens, total_tokens, 
 				estimated_cost_usd, error_message,
-				scores, issues, confidence, raw_output, latency_ms, created_at
+				scores, issues, confidence, raw_output, latency_ms, prompt_template, prompt_hash, retry_count, cached, run_id, created_at
 			)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, NULLIF($21, ''), $22)
+			ON CONFLICT (conversation_id, run_id, evaluator_type) WHERE run_id IS NOT NULL DO NOTHING
 		`, eval.ID, eval.ConversationID, eval.EvaluatorType,
-			eval.Status, eval.ModelName, eval.PromptTokens, eval.CompletionTokens, eval.TotalTokens,
+			eval.Status, eval.ModelName, eval.Provider, eval.PromptTokens, eval.CompletionTokens, eval.TotalTokens,
 			eval.EstimatedCostUSD, eval.ErrorMessage,
-			scoresJSON, issuesJSON, eval.Confidence, eval.RawOutput, eval.LatencyMs, now)
+			scoresJSON, issuesJSON, eval.Confidence, eval.RawOutput, eval.LatencyMs, eval.PromptTemplate, eval.PromptHash, eval.RetryCount, eval.Cached, eval.RunID, now)
 	}
 
 	results := r.db.Pool.SendBatch(ctx, batch)
@@ -96,9 +99,10 @@ func (r *EvaluationRepo) CreateBatch(ctx context.Context, evals []*domain.Evalua
 func (r *EvaluationRepo) GetByConversationID(ctx context.Context, conversationID string) ([]*domain.Evaluation, error) {
 	rows, err := r.db.Pool.Query(ctx, `
 		SELECT id, conversation_id, evaluator_type, 
-			status, model_name, prompt_tokens, completion_tokens, total_tokens, 
+			status, model_name, COALESCE(provider, ''), prompt_tokens, completion_tokens, total_tokens, 
 			estimated_cost_usd, error_message,
-			scores, issues, confidence, raw_output, latency_ms, created_at
+			scores, issues, confidence, raw_output, latency_ms,
+			COALESCE(prompt_template, ''), COALESCE(prompt_hash, ''), COALESCE(retry_count, 0), COALESCE(cached, FALSE), created_at
 		FROM evaluations
 		WHERE conversation_id = $1
 		ORDER BY created_at DESC
@@ -111,6 +115,63 @@ func (r *EvaluationRepo) GetByConversationID(ctx context.Context, conversationID
 	return r.scanEvaluations(rows)
 }
 
+// GetByRun returns the evaluations stored for one delivery of a
+// conversation's queue message.
+func (r *EvaluationRepo) GetByRun(ctx context.Context, conversationID, runID string) ([]*domain.Evaluation, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, conversation_id, evaluator_type, 
+			status, model_name, COALESCE(provider, ''), prompt_tokens, completion_tokens, total_tokens, 
+			estimated_cost_usd, error_message,
+			scores, issues, confidence, raw_output, latency_ms,
+			COALESCE(prompt_template, ''), COALESCE(prompt_hash, ''), COALESCE(retry_count, 0), COALESCE(cached, FALSE), created_at
+		FROM evaluations
+		WHERE conversation_id = $1 AND run_id = $2
+		ORDER BY created_at DESC
+	`, conversationID, runID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	evals, err := r.scanEvaluations(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range evals {
+		e.RunID = runID
+	}
+	return evals, nil
+}
+
+// GetLabeled returns successful evaluations in the date range whose
+// conversations have at least one human annotation.
+func (r *EvaluationRepo) GetLabeled(ctx context.Context, evaluatorTypes []domain.EvaluatorType, dateFrom, dateTo time.Time) ([]*domain.Evaluation, error) {
+	types := make([]string, len(evaluatorTypes))
+	for i, t := range evaluatorTypes {
+		types[i] = string(t)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT e.id, e.conversation_id, e.evaluator_type, 
+			e.status, e.model_name, COALESCE(e.provider, ''), e.prompt_tokens, e.completion_tokens, e.total_tokens, 
+			e.estimated_cost_usd, e.error_message,
+			e.scores, e.issues, e.confidence, e.raw_output, e.latency_ms,
+			COALESCE(e.prompt_template, ''), COALESCE(e.prompt_hash, ''), COALESCE(e.retry_count, 0), COALESCE(e.cached, FALSE), e.created_at
+		FROM evaluations e
+		WHERE e.created_at >= $1 AND e.created_at <= $2
+			AND e.status = 'success'
+			AND (cardinality($3::text[]) = 0 OR e.evaluator_type = ANY($3))
+			AND EXISTS (SELECT 1 FROM annotations a WHERE a.conversation_id = e.conversation_id)
+		ORDER BY e.created_at ASC
+	`, dateFrom, dateTo, types)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanEvaluations(rows)
+}
+
 func (r *EvaluationRepo) Query(ctx context.Context, req *domain.EvaluationsQueryRequest) (*domain.EvaluationsQueryResponse, error) {
 	req.SetDefaults()
 
@@ -118,48 +179,103 @@ func (r *EvaluationRepo) Query(ctx context.Context, req *domain.EvaluationsQuery
 	var args []interface{}
 	argIdx := 1
 
+	fromClause := "evaluations e"
+
 	if len(req.ConversationIDs) > 0 {
-		conditions = append(conditions, fmt.Sprintf("conversation_id = ANY($%d)", argIdx))
+		conditions = append(conditions, fmt.Sprintf("e.conversation_id = ANY($%d)", argIdx))
 		args = append(args, req.ConversationIDs)
 		argIdx++
 	}
 
+	if len(req.AgentVersions) > 0 {
+		fromClause = "evaluations e JOIN conversations c ON c.id = e.conversation_id"
+		conditions = append(conditions, fmt.Sprintf("c.agent_version = ANY($%d)", argIdx))
+		args = append(args, req.AgentVersions)
+		argIdx++
+	}
+
 	if len(req.EvaluatorTypes) > 0 {
 		types := make([]string, len(req.EvaluatorTypes))
 		for i, t := range req.EvaluatorTypes {
 			types[i] = string(t)
 		}
-		conditions = append(conditions, fmt.Sprintf("evaluator_type = ANY($%d)", argIdx))
+		conditions = append(conditions, fmt.Sprintf("e.evaluator_type = ANY($%d)", argIdx))
 		args = append(args, types)
 		argIdx++
 	}
 
+	if len(req.Statuses) > 0 {
+		statuses := make([]string, len(req.Statuses))
+		for i, s := range req.Statuses {
+			statuses[i] = string(s)
+		}
+		conditions = append(conditions, fmt.Sprintf("e.status = ANY($%d)", argIdx))
+		args = append(args, statuses)
+		argIdx++
+	}
+
 	if req.DateFrom != nil {
-		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", argIdx))
+		conditions = append(conditions, fmt.Sprintf("e.created_at >= $%d", argIdx))
 		args = append(args, req.DateFrom)
 		argIdx++
 	}
 
 	if req.DateTo != nil {
-		conditions = append(conditions, fmt.Sprintf("created_at <= $%d", argIdx))
+		conditions = append(conditions, fmt.Sprintf("e.created_at <= $%d", argIdx))
 		args = append(args, req.DateTo)
 		argIdx++
 	}
 
+	if req.MinOverallScore != nil {
+		conditions = append(conditions, fmt.Sprintf("(e.scores->>'overall')::float8 >= $%d", argIdx))
+		args = append(args, *req.MinOverallScore)
+		argIdx++
+	}
+
+	if req.MaxOverallScore != nil {
+		conditions = append(conditions, fmt.Sprintf("(e.scores->>'overall')::float8 <= $%d", argIdx))
+		args = append(args, *req.MaxOverallScore)
+		argIdx++
+	}
+
+	// Issues are stored as a JSON array (or null when none were found), so
+	// containment against '[{}]' matches any evaluation with at least one issue.
+	if req.HasIssues != nil {
+		if *req.HasIssues {
+			conditions = append(conditions, "e.issues @> '[{}]'::jsonb")
+		} else {
+			conditions = append(conditions, "NOT COALESCE(e.issues @> '[{}]'::jsonb, false)")
+		}
+	}
+
+	if len(req.IssueTypes) > 0 {
+		patterns := make([]string, len(req.IssueTypes))
+		for i, t := range req.IssueTypes {
+			pattern, err := json.Marshal([]map[string]string{{"type": t}})
+			if err != nil {
+				return nil, fmt.Errorf("marshal issue type: %w", err)
+			}
+			patterns[i] = string(pattern)
+		}
+		conditions = append(conditions, fmt.Sprintf("e.issues @> ANY($%d::jsonb[])", argIdx))
+		args = append(args, patterns)
+		argIdx++
+	}
+
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM evaluations %s", whereClause)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s %s", fromClause, whereClause)
 	var total int
 	if err := r.db.Pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, fmt.Errorf("count: %w", err)
 	}
 
-	orderColumn := "created_at"
+	orderColumn := "e.created_at"
 	if req.SortBy == "overall_score" {
-		orderColumn = "scores->>'overall'"
+		orderColumn = "(e.scores->>'overall')::float8"
 	}
 	orderDir := "DESC"
 	if req.SortOrder == "asc" {
@@ -167,15 +283,16 @@ func (r *EvaluationRepo) Query(ctx context.Context, req *domain.EvaluationsQuery
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, conversation_id, evaluator_type, 
-			status, model_name, prompt_tokens, completion_tokens, total_tokens, 
-			estimated_cost_usd, error_message,
-			scores, issues, confidence, raw_output, latency_ms, created_at
-		FROM evaluations
+		SELECT e.id, e.conversation_id, e.evaluator_type, 
+			e.status, e.model_name, COALESCE(e.provider, ''), e.prompt_tokens, e.completion_tokens, e.total_tokens, 
+			e.estimated_cost_usd, e.error_message,
+			e.scores, e.issues, e.confidence, e.raw_output, e.latency_ms,
+			COALESCE(e.prompt_template, ''), COALESCE(e.prompt_hash, ''), COALESCE(e.retry_count, 0), COALESCE(e.cached, FALSE), e.created_at
+		FROM %s
 		%s
 		ORDER BY %s %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, orderColumn, orderDir, argIdx, argIdx+1)
+	`, fromClause, whereClause, orderColumn, orderDir, argIdx, argIdx+1)
 
 	args = append(args, req.Limit, req.Offset)
 
@@ -199,6 +316,67 @@ func (r *EvaluationRepo) Query(ctx context.Context, req *domain.EvaluationsQuery
 	}, nil
 }
 
+// FailureRates counts evaluator runs in the date range per evaluator type and
+// provider, broken down by status.
+func (r *EvaluationRepo) FailureRates(ctx context.Context, evaluatorTypes []domain.EvaluatorType, dateFrom, dateTo time.Time) ([]*domain.EvaluatorFailureRate, error) {
+	types := make([]string, len(evaluatorTypes))
+	for i, t := range evaluatorTypes {
+		types[i] = string(t)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT evaluator_type, COALESCE(provider, ''), COALESCE(status, 'success'), COUNT(*)
+		FROM evaluations
+		WHERE created_at >= $1 AND created_at <= $2
+			AND (cardinality($3::text[]) = 0 OR evaluator_type = ANY($3))
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2
+	`, dateFrom, dateTo, types)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []*domain.EvaluatorFailureRate
+	byKey := make(map[string]*domain.EvaluatorFailureRate)
+
+	for rows.Next() {
+		var evalType domain.EvaluatorType
+		var provider string
+		var status domain.EvalStatus
+		var count int
+		if err := rows.Scan(&evalType, &provider, &status, &count); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		key := string(evalType) + "/" + provider
+		rate, ok := byKey[key]
+		if !ok {
+			rate = &domain.EvaluatorFailureRate{
+				EvaluatorType: evalType,
+				Provider:      provider,
+				ByStatus:      make(map[domain.EvalStatus]int),
+			}
+			byKey[key] = rate
+			rates = append(rates, rate)
+		}
+
+		rate.Total += count
+		rate.ByStatus[status] += count
+		if status != domain.EvalStatusSuccess {
+			rate.Failed += count
+		}
+	}
+
+	for _, rate := range rates {
+		if rate.Total > 0 {
+			rate.FailureRate = float64(rate.Failed) / float64(rate.Total)
+		}
+	}
+
+	return rates, nil
+}
+
 func (r *EvaluationRepo) scanEvaluations(rows pgx.Rows) ([]*domain.Evaluation, error) {
 	var evals []*domain.Evaluation
 
@@ -208,9 +386,10 @@ func (r *EvaluationRepo) scanEvaluations(rows pgx.Rows) ([]*domain.Evaluation, e
 
 		if err := rows.Scan(
 			&eval.ID, &eval.ConversationID, &eval.EvaluatorType,
-			&eval.Status, &eval.ModelName, &eval.PromptTokens, &eval.CompletionTokens, &eval.TotalTokens,
+			&eval.Status, &eval.ModelName, &eval.Provider, &eval.PromptTokens, &eval.CompletionTokens, &eval.TotalTokens,
 			&eval.EstimatedCostUSD, &eval.ErrorMessage,
-			&scoresJSON, &issuesJSON, &eval.Confidence, &eval.RawOutput, &eval.LatencyMs, &eval.CreatedAt,
+			&scoresJSON, &issuesJSON, &eval.Confidence, &eval.RawOutput, &eval.LatencyMs,
+			&eval.PromptTemplate, &eval.PromptHash, &eval.RetryCount, &eval.Cached, &eval.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan: %w", err)
 		}
@@ -225,6 +404,9 @@ func (r *EvaluationRepo) scanEvaluations(rows pgx.Rows) ([]*domain.Evaluation, e
 
 		evals = append(evals, &eval)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
 
 	return evals, nil
 }
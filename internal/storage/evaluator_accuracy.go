@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+type EvaluatorAccuracyRepo struct {
+	db *PostgresDB
+}
+
+func NewEvaluatorAccuracyRepo(db *PostgresDB) *EvaluatorAccuracyRepo {
+	return &EvaluatorAccuracyRepo{db: db}
+}
+
+// Upsert writes the rollup for (evaluator_type, metric_date), replacing any
+// earlier partial-day figures.
+func (r *EvaluatorAccuracyRepo) Upsert(ctx context.Context, acc *domain.EvaluatorAccuracy) error {
+	if acc.ID == "" {
+		acc.ID = uuid.New().String()
+	}
+
+	err := r.db.Pool.QueryRow(ctx, `
+		INSERT INTO evaluator_accuracy (
+			id, evaluator_type, metric_date, precision_score, recall_score, f1_score,
+			sample_count, human_correlation, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (evaluator_type, metric_date) DO UPDATE SET
+			precision_score = EXCLUDED.precision_score,
+			recall_score = EXCLUDED.recall_score,
+			f1_score = EXCLUDED.f1_score,
+			sample_count = EXCLUDED.sample_count,
+			human_correlation = EXCLUDED.human_correlation,
+			created_at = EXCLUDED.created_at
+		RETURNING id
+	`, acc.ID, acc.EvaluatorType, acc.MetricDate, acc.PrecisionScore, acc.RecallScore, acc.F1Score,
+		acc.SampleCount, acc.HumanCorrelation, time.Now()).Scan(&acc.ID)
+
+	if err != nil {
+		return fmt.Errorf("upsert: %w", err)
+	}
+
+	return nil
+}
+
+func (r *EvaluatorAccuracyRepo) History(ctx context.Context, evaluatorTypes []domain.EvaluatorType, dateFrom, dateTo time.Time) ([]*domain.EvaluatorAccuracy, error) {
+	types := make([]string, len(evaluatorTypes))
+	for i, t := range evaluatorTypes {
+		types[i] = string(t)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, evaluator_type, metric_date, COALESCE(precision_score, 0), COALESCE(recall_score, 0),
+			COALESCE(f1_score, 0), COALESCE(sample_count, 0), COALESCE(human_correlation, 0), created_at
+		FROM evaluator_accuracy
+		WHERE metric_date >= $1::date AND metric_date <= $2::date
+			AND (cardinality($3::text[]) = 0 OR evaluator_type = ANY($3))
+		ORDER BY evaluator_type ASC, metric_date ASC
+	`, dateFrom, dateTo, types)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var history []*domain.EvaluatorAccuracy
+	for rows.Next() {
+		var acc domain.EvaluatorAccuracy
+		if err := rows.Scan(
+			&acc.ID, &acc.EvaluatorType, &acc.MetricDate, &acc.PrecisionScore, &acc.RecallScore,
+			&acc.F1Score, &acc.SampleCount, &acc.HumanCorrelation, &acc.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		history = append(history, &acc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return history, nil
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+type JobRepo struct {
+	db *PostgresDB
+}
+
+func NewJobRepo(db *PostgresDB) *JobRepo {
+	return &JobRepo{db: db}
+}
+
+// Create stores job with every conversation queued.
+func (r *JobRepo) Create(ctx context.Context, job *domain.Job) error {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	job.CreatedAt = now
+	for i := range job.Conversations {
+		job.Conversations[i].Status = domain.JobStatusQueued
+		job.Conversations[i].UpdatedAt = now
+	}
+	job.Summarize()
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO ingest_jobs (id, created_at) VALUES ($1, $2)
+	`, job.ID, job.CreatedAt); err != nil {
+		return fmt.Errorf("insert job: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for i, c := range job.Conversations {
+		batch.Queue(`
+			INSERT INTO ingest_job_conversations (job_id, conversation_id, position, status, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (job_id, conversation_id) DO NOTHING
+		`, job.ID, c.ConversationID, i, c.Status, c.UpdatedAt)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for range job.Conversations {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return fmt.Errorf("insert job conversation: %w", err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("close batch: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetByID returns the job with its conversations, or nil if it does not
+// exist.
+func (r *JobRepo) GetByID(ctx context.Context, id string) (*domain.Job, error) {
+	job := domain.Job{ID: id}
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT created_at FROM ingest_jobs WHERE id = $1
+	`, id).Scan(&job.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query job: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT conversation_id, status, COALESCE(error_message, ''), updated_at
+		FROM ingest_job_conversations
+		WHERE job_id = $1
+		ORDER BY position
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("query job conversations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c domain.JobConversation
+		if err := rows.Scan(&c.ConversationID, &c.Status, &c.Error, &c.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		job.Conversations = append(job.Conversations, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	job.Summarize()
+	return &job, nil
+}
+
+// UpdateConversationStatus sets the conversation's status in every job where
+// it is not yet done. Failed and partial entries are included so a redelivered
+// message or a successful evaluator retry can still complete the job.
+func (r *JobRepo) UpdateConversationStatus(ctx context.Context, conversationID string, status domain.JobStatus, errMsg string) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		UPDATE ingest_job_conversations
+		SET status = $2, error_message = NULLIF($3, ''), updated_at = NOW()
+		WHERE conversation_id = $1 AND status <> 'done'
+	`, conversationID, status, errMsg)
+	if err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the job and its conversations' statuses.
+func (r *JobRepo) Delete(ctx context.Context, id string) error {
+	if _, err := r.db.Pool.Exec(ctx, `DELETE FROM ingest_jobs WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+type AggregatedEvaluationRepo struct {
+	s *Store
+}
+
+// Upsert stores the conversation's aggregated evaluation, replacing any
+// earlier result but keeping its creation time. Per-evaluator rows are not
+// duplicated here.
+func (r *AggregatedEvaluationRepo) Upsert(ctx context.Context, agg *domain.AggregatedEvaluation) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if agg.CreatedAt.IsZero() {
+		agg.CreatedAt = time.Now()
+	}
+	agg.UpdatedAt = time.Now()
+
+	stored := *agg
+	stored.Evaluations = []domain.Evaluation{}
+	if existing, ok := r.s.aggregated[agg.ConversationID]; ok {
+		stored.CreatedAt = existing.CreatedAt
+	}
+	r.s.aggregated[agg.ConversationID] = &stored
+
+	return nil
+}
+
+func (r *AggregatedEvaluationRepo) GetByConversationID(ctx context.Context, conversationID string) (*domain.AggregatedEvaluation, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	agg, ok := r.s.aggregated[conversationID]
+	if !ok {
+		return nil, nil
+	}
+	c := *agg
+	return &c, nil
+}
+
+// List returns aggregated evaluations, newest first. A non-empty issueType
+// keeps only conversations with an issue of that type. The second return
+// value is the total number of matching rows.
+func (r *AggregatedEvaluationRepo) List(ctx context.Context, issueType string, limit, offset int) ([]*domain.AggregatedEvaluation, int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var aggs []*domain.AggregatedEvaluation
+	for _, agg := range r.s.aggregated {
+		if issueType != "" && !hasIssueType(agg.Issues, issueType) {
+			continue
+		}
+		c := *agg
+		aggs = append(aggs, &c)
+	}
+
+	sort.Slice(aggs, func(i, j int) bool {
+		return aggs[i].CreatedAt.After(aggs[j].CreatedAt)
+	})
+
+	start, end := page(len(aggs), limit, offset)
+	return aggs[start:end], len(aggs), nil
+}
+
+func hasIssueType(issues []domain.Issue, issueType string) bool {
+	for _, issue := range issues {
+		if issue.Type == issueType {
+			return true
+		}
+	}
+	return false
+}
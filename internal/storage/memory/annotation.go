@@ -0,0 +1,211 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+// reliabilityPrior matches the Postgres repo: every annotator starts with
+// this many virtual agreeing comparisons.
+const reliabilityPrior = 5.0
+
+type AnnotationRepo struct {
+	s *Store
+}
+
+// CreateBatch stores annotations, registers unknown annotators and refreshes
+// the stats of every annotator who labelled the same items.
+func (r *AnnotationRepo) CreateBatch(ctx context.Context, anns []*domain.Annotation) error {
+	if len(anns) == 0 {
+		return nil
+	}
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	affected := make(map[string]bool)
+
+	for _, ann := range anns {
+		if ann.ID == "" {
+			ann.ID = uuid.New().String()
+		}
+		ann.CreatedAt = now
+
+		if _, ok := r.s.annotators[ann.AnnotatorID]; !ok {
+			r.s.annotators[ann.AnnotatorID] = &domain.Annotator{
+				ID:               ann.AnnotatorID,
+				Name:             ann.AnnotatorID,
+				ReliabilityScore: 1.0,
+				CreatedAt:        now,
+				UpdatedAt:        now,
+			}
+		}
+
+		c := *ann
+		if c.Metadata == nil {
+			c.Metadata = json.RawMessage("{}")
+		}
+		r.s.annotations = append(r.s.annotations, &c)
+
+		for _, other := range r.s.annotations {
+			if sameItem(other, &c) {
+				affected[other.AnnotatorID] = true
+			}
+		}
+	}
+
+	r.refreshAnnotatorStats(affected, now)
+	return nil
+}
+
+// refreshAnnotatorStats mirrors the Postgres repo: total annotations,
+// pairwise agreement on the same (conversation, turn, type) item, and a
+// reliability score smoothed towards 1.0. Callers hold the store lock.
+func (r *AnnotationRepo) refreshAnnotatorStats(annotatorIDs map[string]bool, now time.Time) {
+	for id := range annotatorIDs {
+		var total, compared, agreed int
+		for _, a := range r.s.annotations {
+			if a.AnnotatorID != id {
+				continue
+			}
+			total++
+			for _, b := range r.s.annotations {
+				if b.AnnotatorID != id && sameItem(a, b) {
+					compared++
+					if a.Label == b.Label {
+						agreed++
+					}
+				}
+			}
+		}
+
+		annotator := r.s.annotators[id]
+		annotator.TotalAnnotations = total
+		if compared > 0 {
+			annotator.AgreementRate = float64(agreed) / float64(compared)
+		}
+		annotator.ReliabilityScore = (float64(agreed) + reliabilityPrior) / (float64(compared) + reliabilityPrior)
+		annotator.UpdatedAt = now
+	}
+}
+
+func sameItem(a, b *domain.Annotation) bool {
+	if a.ConversationID != b.ConversationID || a.Type != b.Type {
+		return false
+	}
+	if a.TurnID == nil || b.TurnID == nil {
+		return a.TurnID == nil && b.TurnID == nil
+	}
+	return *a.TurnID == *b.TurnID
+}
+
+func (r *AnnotationRepo) GetByConversationID(ctx context.Context, conversationID string) ([]*domain.Annotation, error) {
+	return r.GetByConversationIDs(ctx, []string{conversationID})
+}
+
+func (r *AnnotationRepo) GetByConversationIDs(ctx context.Context, conversationIDs []string) ([]*domain.Annotation, error) {
+	if len(conversationIDs) == 0 {
+		return nil, nil
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	ids := stringSet(conversationIDs)
+	var anns []*domain.Annotation
+	for _, ann := range r.s.annotations {
+		if ids[ann.ConversationID] {
+			c := *ann
+			anns = append(anns, &c)
+		}
+	}
+	return anns, nil
+}
+
+func (r *AnnotationRepo) EvaluationDaysAnnotatedSince(ctx context.Context, since time.Time) ([]time.Time, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	annotated := make(map[string]bool)
+	for _, ann := range r.s.annotations {
+		if !ann.CreatedAt.Before(since) {
+			annotated[ann.ConversationID] = true
+		}
+	}
+
+	seen := make(map[time.Time]bool)
+	var days []time.Time
+	for _, e := range r.s.evaluations {
+		if e.Status != domain.EvalStatusSuccess || !annotated[e.ConversationID] {
+			continue
+		}
+		t := e.CreatedAt.UTC()
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days, nil
+}
+
+func (r *AnnotationRepo) CreateAnnotator(ctx context.Context, a *domain.Annotator) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	existing, ok := r.s.annotators[a.ID]
+	if !ok {
+		existing = &domain.Annotator{ID: a.ID, ReliabilityScore: 1.0, CreatedAt: now}
+		r.s.annotators[a.ID] = existing
+	}
+	existing.Name = a.Name
+	existing.UpdatedAt = now
+
+	*a = *existing
+	return nil
+}
+
+func (r *AnnotationRepo) GetAnnotator(ctx context.Context, id string) (*domain.Annotator, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	a, ok := r.s.annotators[id]
+	if !ok {
+		return nil, nil
+	}
+	c := *a
+	return &c, nil
+}
+
+func (r *AnnotationRepo) ListAnnotators(ctx context.Context, limit, offset int) ([]*domain.Annotator, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	annotators := make([]*domain.Annotator, 0, len(r.s.annotators))
+	for _, a := range r.s.annotators {
+		c := *a
+		annotators = append(annotators, &c)
+	}
+
+	sort.Slice(annotators, func(i, j int) bool {
+		if annotators[i].TotalAnnotations != annotators[j].TotalAnnotations {
+			return annotators[i].TotalAnnotations > annotators[j].TotalAnnotations
+		}
+		return annotators[i].ID < annotators[j].ID
+	})
+
+	start, end := page(len(annotators), limit, offset)
+	return annotators[start:end], nil
+}
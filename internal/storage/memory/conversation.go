@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+type conversationRow struct {
+	conv             domain.Conversation
+	evaluationStatus string
+}
+
+type ConversationRepo struct {
+	s *Store
+}
+
+func (r *ConversationRepo) Create(ctx context.Context, conv *domain.Conversation) error {
+	return r.CreateBatch(ctx, []*domain.Conversation{conv})
+}
+
+// CreateBatch upserts conversations, keeping stored feedback when the new
+// copy has none, like the Postgres repo.
+func (r *ConversationRepo) CreateBatch(ctx context.Context, convs []*domain.Conversation) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for _, conv := range convs {
+		r.put(conv, now)
+	}
+
+	return nil
+}
+
+// put upserts one conversation; the caller holds the lock.
+func (r *ConversationRepo) put(conv *domain.Conversation, now time.Time) {
+	c := *conv
+	if c.Metadata == nil {
+		c.Metadata = json.RawMessage("{}")
+	}
+
+	if existing, ok := r.s.conversations[c.ID]; ok {
+		if c.Feedback == nil {
+			c.Feedback = existing.conv.Feedback
+		}
+		c.CreatedAt = existing.conv.CreatedAt
+		c.ProcessedAt = existing.conv.ProcessedAt
+		existing.conv = c
+		return
+	}
+
+	c.CreatedAt = now
+	c.ProcessedAt = nil
+	r.s.conversations[c.ID] = &conversationRow{conv: c}
+}
+
+// Update runs fn under the store's lock.
+func (r *ConversationRepo) Update(ctx context.Context, id string, fn func(existing *domain.Conversation) (*domain.Conversation, error)) (*domain.Conversation, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var existing *domain.Conversation
+	if row, ok := r.s.conversations[id]; ok {
+		c := row.conv
+		existing = &c
+	}
+	conv, err := fn(existing)
+	if err != nil {
+		return nil, err
+	}
+	r.put(conv, time.Now())
+	return conv, nil
+}
+
+func (r *ConversationRepo) GetByID(ctx context.Context, id string) (*domain.Conversation, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	row, ok := r.s.conversations[id]
+	if !ok {
+		return nil, nil
+	}
+	c := row.conv
+	return &c, nil
+}
+
+func (r *ConversationRepo) UpdateFeedback(ctx context.Context, id string, feedback *domain.Feedback) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	row, ok := r.s.conversations[id]
+	if !ok {
+		return fmt.Errorf("conversation not found")
+	}
+	row.conv.Feedback = feedback
+	return nil
+}
+
+func (r *ConversationRepo) MarkProcessed(ctx context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if row, ok := r.s.conversations[id]; ok {
+		now := time.Now()
+		row.conv.ProcessedAt = &now
+	}
+	return nil
+}
+
+func (r *ConversationRepo) MarkProcessedWithStatus(ctx context.Context, id string, status string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if row, ok := r.s.conversations[id]; ok {
+		now := time.Now()
+		row.conv.ProcessedAt = &now
+		row.evaluationStatus = status
+	}
+	return nil
+}
+
+func (r *ConversationRepo) GetUnprocessed(ctx context.Context, limit int) ([]*domain.Conversation, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var convs []*domain.Conversation
+	for _, row := range r.s.conversations {
+		if row.conv.ProcessedAt == nil {
+			c := row.conv
+			convs = append(convs, &c)
+		}
+	}
+
+	sort.Slice(convs, func(i, j int) bool {
+		return convs[i].CreatedAt.Before(convs[j].CreatedAt)
+	})
+
+	start, end := page(len(convs), limit, 0)
+	return convs[start:end], nil
+}
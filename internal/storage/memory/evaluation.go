@@ -0,0 +1,273 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+type EvaluationRepo struct {
+	s *Store
+}
+
+func (r *EvaluationRepo) Create(ctx context.Context, eval *domain.Evaluation) error {
+	return r.CreateBatch(ctx, []*domain.Evaluation{eval})
+}
+
+func (r *EvaluationRepo) CreateBatch(ctx context.Context, evals []*domain.Evaluation) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for _, eval := range evals {
+		if eval.RunID != "" && r.hasRun(eval) {
+			continue
+		}
+		if eval.ID == "" {
+			eval.ID = uuid.New().String()
+		}
+		e := *eval
+		e.CreatedAt = now
+		r.s.evaluations = append(r.s.evaluations, &e)
+	}
+
+	return nil
+}
+
+func (r *EvaluationRepo) GetByConversationID(ctx context.Context, conversationID string) ([]*domain.Evaluation, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	evals := r.filter(func(e *domain.Evaluation) bool {
+		return e.ConversationID == conversationID
+	})
+	sortEvaluations(evals, "created_at", "desc")
+	return evals, nil
+}
+
+// hasRun reports whether an evaluation from the same run and evaluator is
+// stored, matching the Postgres unique index. Callers hold the store lock.
+func (r *EvaluationRepo) hasRun(eval *domain.Evaluation) bool {
+	for _, e := range r.s.evaluations {
+		if e.ConversationID == eval.ConversationID && e.RunID == eval.RunID && e.EvaluatorType == eval.EvaluatorType {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *EvaluationRepo) GetByRun(ctx context.Context, conversationID, runID string) ([]*domain.Evaluation, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	evals := r.filter(func(e *domain.Evaluation) bool {
+		return e.ConversationID == conversationID && e.RunID == runID
+	})
+	sortEvaluations(evals, "created_at", "desc")
+	return evals, nil
+}
+
+// GetLabeled returns successful evaluations in the date range whose
+// conversations have at least one human annotation.
+func (r *EvaluationRepo) GetLabeled(ctx context.Context, evaluatorTypes []domain.EvaluatorType, dateFrom, dateTo time.Time) ([]*domain.Evaluation, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	annotated := make(map[string]bool)
+	for _, ann := range r.s.annotations {
+		annotated[ann.ConversationID] = true
+	}
+
+	types := typeSet(evaluatorTypes)
+	evals := r.filter(func(e *domain.Evaluation) bool {
+		return inRange(e.CreatedAt, dateFrom, dateTo) &&
+			e.Status == domain.EvalStatusSuccess &&
+			(len(types) == 0 || types[e.EvaluatorType]) &&
+			annotated[e.ConversationID]
+	})
+	sortEvaluations(evals, "created_at", "asc")
+	return evals, nil
+}
+
+func (r *EvaluationRepo) Query(ctx context.Context, req *domain.EvaluationsQueryRequest) (*domain.EvaluationsQueryResponse, error) {
+	req.SetDefaults()
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	convIDs := stringSet(req.ConversationIDs)
+	versions := stringSet(req.AgentVersions)
+	types := typeSet(req.EvaluatorTypes)
+	issueTypes := stringSet(req.IssueTypes)
+
+	statuses := make(map[domain.EvalStatus]bool)
+	for _, s := range req.Statuses {
+		statuses[s] = true
+	}
+
+	evals := r.filter(func(e *domain.Evaluation) bool {
+		if len(convIDs) > 0 && !convIDs[e.ConversationID] {
+			return false
+		}
+		if len(versions) > 0 {
+			row, ok := r.s.conversations[e.ConversationID]
+			if !ok || !versions[row.conv.AgentVersion] {
+				return false
+			}
+		}
+		if len(types) > 0 && !types[e.EvaluatorType] {
+			return false
+		}
+		if len(statuses) > 0 && !statuses[e.Status] {
+			return false
+		}
+		if req.DateFrom != nil && e.CreatedAt.Before(*req.DateFrom) {
+			return false
+		}
+		if req.DateTo != nil && e.CreatedAt.After(*req.DateTo) {
+			return false
+		}
+		if req.MinOverallScore != nil && e.Scores.Overall < *req.MinOverallScore {
+			return false
+		}
+		if req.MaxOverallScore != nil && e.Scores.Overall > *req.MaxOverallScore {
+			return false
+		}
+		if req.HasIssues != nil && (len(e.Issues) > 0) != *req.HasIssues {
+			return false
+		}
+		if len(issueTypes) > 0 {
+			found := false
+			for _, issue := range e.Issues {
+				if issueTypes[issue.Type] {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	})
+
+	sortEvaluations(evals, req.SortBy, req.SortOrder)
+
+	total := len(evals)
+	start, end := page(total, req.Limit, req.Offset)
+
+	result := make([]domain.Evaluation, 0, end-start)
+	for _, e := range evals[start:end] {
+		result = append(result, *e)
+	}
+
+	return &domain.EvaluationsQueryResponse{
+		Evaluations: result,
+		Total:       total,
+		Limit:       req.Limit,
+		Offset:      req.Offset,
+		HasMore:     end < total,
+	}, nil
+}
+
+// FailureRates counts evaluator runs in the date range per evaluator type and
+// provider, broken down by status.
+func (r *EvaluationRepo) FailureRates(ctx context.Context, evaluatorTypes []domain.EvaluatorType, dateFrom, dateTo time.Time) ([]*domain.EvaluatorFailureRate, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	types := typeSet(evaluatorTypes)
+	evals := r.filter(func(e *domain.Evaluation) bool {
+		return inRange(e.CreatedAt, dateFrom, dateTo) && (len(types) == 0 || types[e.EvaluatorType])
+	})
+
+	var rates []*domain.EvaluatorFailureRate
+	byKey := make(map[string]*domain.EvaluatorFailureRate)
+
+	for _, e := range evals {
+		key := string(e.EvaluatorType) + "/" + e.Provider
+		rate, ok := byKey[key]
+		if !ok {
+			rate = &domain.EvaluatorFailureRate{
+				EvaluatorType: e.EvaluatorType,
+				Provider:      e.Provider,
+				ByStatus:      make(map[domain.EvalStatus]int),
+			}
+			byKey[key] = rate
+			rates = append(rates, rate)
+		}
+
+		status := e.Status
+		if status == "" {
+			status = domain.EvalStatusSuccess
+		}
+		rate.Total++
+		rate.ByStatus[status]++
+		if status != domain.EvalStatusSuccess {
+			rate.Failed++
+		}
+	}
+
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].EvaluatorType != rates[j].EvaluatorType {
+			return rates[i].EvaluatorType < rates[j].EvaluatorType
+		}
+		return rates[i].Provider < rates[j].Provider
+	})
+
+	for _, rate := range rates {
+		rate.FailureRate = float64(rate.Failed) / float64(rate.Total)
+	}
+
+	return rates, nil
+}
+
+// filter returns copies of the stored evaluations matching keep. Callers hold
+// the store lock.
+func (r *EvaluationRepo) filter(keep func(e *domain.Evaluation) bool) []*domain.Evaluation {
+	var evals []*domain.Evaluation
+	for _, e := range r.s.evaluations {
+		if keep(e) {
+			c := *e
+			evals = append(evals, &c)
+		}
+	}
+	return evals
+}
+
+func sortEvaluations(evals []*domain.Evaluation, sortBy, sortOrder string) {
+	less := func(a, b *domain.Evaluation) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	if sortBy == "overall_score" {
+		less = func(a, b *domain.Evaluation) bool { return a.Scores.Overall < b.Scores.Overall }
+	}
+
+	sort.SliceStable(evals, func(i, j int) bool {
+		if sortOrder == "asc" {
+			return less(evals[i], evals[j])
+		}
+		return less(evals[j], evals[i])
+	})
+}
+
+func inRange(t, from, to time.Time) bool {
+	return !t.Before(from) && !t.After(to)
+}
+
+func typeSet(types []domain.EvaluatorType) map[domain.EvaluatorType]bool {
+	set := make(map[domain.EvaluatorType]bool, len(types))
+	for _, t := range types {
+		set[t] = true
+	}
+	return set
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
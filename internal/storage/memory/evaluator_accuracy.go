@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+type accuracyKey struct {
+	evaluatorType domain.EvaluatorType
+	date          string
+}
+
+type EvaluatorAccuracyRepo struct {
+	s *Store
+}
+
+// Upsert writes the rollup for (evaluator_type, metric_date), replacing any
+// earlier partial-day figures.
+func (r *EvaluatorAccuracyRepo) Upsert(ctx context.Context, acc *domain.EvaluatorAccuracy) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := accuracyKey{acc.EvaluatorType, acc.MetricDate.Format("2006-01-02")}
+	if existing, ok := r.s.accuracy[key]; ok {
+		acc.ID = existing.ID
+	} else if acc.ID == "" {
+		acc.ID = uuid.New().String()
+	}
+
+	c := *acc
+	c.CreatedAt = time.Now()
+	r.s.accuracy[key] = &c
+	return nil
+}
+
+func (r *EvaluatorAccuracyRepo) History(ctx context.Context, evaluatorTypes []domain.EvaluatorType, dateFrom, dateTo time.Time) ([]*domain.EvaluatorAccuracy, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	from := dateFrom.Format("2006-01-02")
+	to := dateTo.Format("2006-01-02")
+	types := typeSet(evaluatorTypes)
+
+	var history []*domain.EvaluatorAccuracy
+	for key, acc := range r.s.accuracy {
+		if key.date < from || key.date > to {
+			continue
+		}
+		if len(types) > 0 && !types[key.evaluatorType] {
+			continue
+		}
+		c := *acc
+		history = append(history, &c)
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		if history[i].EvaluatorType != history[j].EvaluatorType {
+			return history[i].EvaluatorType < history[j].EvaluatorType
+		}
+		return history[i].MetricDate.Before(history[j].MetricDate)
+	})
+
+	return history, nil
+}
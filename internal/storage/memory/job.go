@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+type JobRepo struct {
+	s *Store
+}
+
+func (r *JobRepo) Create(ctx context.Context, job *domain.Job) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	job.CreatedAt = now
+
+	seen := make(map[string]bool, len(job.Conversations))
+	conversations := make([]domain.JobConversation, 0, len(job.Conversations))
+	for _, c := range job.Conversations {
+		if seen[c.ConversationID] {
+			continue
+		}
+		seen[c.ConversationID] = true
+		c.Status = domain.JobStatusQueued
+		c.UpdatedAt = now
+		conversations = append(conversations, c)
+	}
+	job.Conversations = conversations
+	job.Summarize()
+
+	stored := *job
+	stored.Conversations = append([]domain.JobConversation(nil), conversations...)
+	r.s.jobs[job.ID] = &stored
+	return nil
+}
+
+func (r *JobRepo) GetByID(ctx context.Context, id string) (*domain.Job, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	job, ok := r.s.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+	c := *job
+	c.Conversations = append([]domain.JobConversation(nil), job.Conversations...)
+	c.Summarize()
+	return &c, nil
+}
+
+// UpdateConversationStatus sets the conversation's status in every job where
+// it is not yet done.
+func (r *JobRepo) UpdateConversationStatus(ctx context.Context, conversationID string, status domain.JobStatus, errMsg string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for _, job := range r.s.jobs {
+		for i := range job.Conversations {
+			c := &job.Conversations[i]
+			if c.ConversationID != conversationID || c.Status == domain.JobStatusDone {
+				continue
+			}
+			c.Status = status
+			c.Error = errMsg
+			c.UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+func (r *JobRepo) Delete(ctx context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.jobs, id)
+	return nil
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+type ReviewQueueRepo struct {
+	s *Store
+}
+
+func (r *ReviewQueueRepo) AddToQueue(ctx context.Context, item *domain.ReviewQueueItem) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if item.ID == "" {
+		item.ID = uuid.New().String()
+	}
+
+	c := *item
+	c.CreatedAt = time.Now()
+	r.s.reviews[item.ID] = &c
+	return nil
+}
+
+func (r *ReviewQueueRepo) GetPending(ctx context.Context, limit int) ([]*domain.ReviewQueueItem, error) {
+	return r.GetPendingPaginated(ctx, limit, 0)
+}
+
+func (r *ReviewQueueRepo) GetPendingPaginated(ctx context.Context, limit, offset int) ([]*domain.ReviewQueueItem, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	items := r.pending()
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Priority != items[j].Priority {
+			return items[i].Priority < items[j].Priority
+		}
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+
+	start, end := page(len(items), limit, offset)
+	return items[start:end], nil
+}
+
+func (r *ReviewQueueRepo) CountPending(ctx context.Context) (int, error) {
+	return len(r.pending()), nil
+}
+
+func (r *ReviewQueueRepo) pending() []*domain.ReviewQueueItem {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var items []*domain.ReviewQueueItem
+	for _, item := range r.s.reviews {
+		if item.Status == "pending" {
+			c := *item
+			items = append(items, &c)
+		}
+	}
+	return items
+}
+
+func (r *ReviewQueueRepo) GetByID(ctx context.Context, id string) (*domain.ReviewQueueItem, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	item, ok := r.s.reviews[id]
+	if !ok {
+		return nil, fmt.Errorf("review queue item not found")
+	}
+	c := *item
+	return &c, nil
+}
+
+func (r *ReviewQueueRepo) CompleteReview(ctx context.Context, id string, reviewerNotes string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if item, ok := r.s.reviews[id]; ok {
+		now := time.Now()
+		item.Status = "completed"
+		item.ReviewedAt = &now
+		item.ReviewerNotes = reviewerNotes
+	}
+	return nil
+}
+
+func (r *ReviewQueueRepo) AssignReview(ctx context.Context, id string, assignedTo string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if item, ok := r.s.reviews[id]; ok && item.Status == "pending" {
+		item.Status = "in_progress"
+		item.AssignedTo = &assignedTo
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Package memory implements the storage repositories in process memory, for
+// standalone local runs and integration tests without Postgres.
+package memory
+
+import (
+	"sync"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/storage"
+)
+
+// Store holds every table. Repos share one lock so reads that span tables
+// (e.g. labeled evaluations) see a consistent snapshot.
+type Store struct {
+	mu sync.RWMutex
+
+	conversations map[string]*conversationRow
+	evaluations   []*domain.Evaluation // insertion order
+	aggregated    map[string]*domain.AggregatedEvaluation
+	suggestions   map[string]*domain.Suggestion
+	reviews       map[string]*domain.ReviewQueueItem
+	annotations   []*domain.Annotation // insertion order
+	annotators    map[string]*domain.Annotator
+	accuracy      map[accuracyKey]*domain.EvaluatorAccuracy
+	jobs          map[string]*domain.Job
+
+	webhooks          map[string]*domain.Webhook
+	webhookDeliveries []*domain.WebhookDelivery // insertion order
+}
+
+func NewStore() *Store {
+	return &Store{
+		conversations: make(map[string]*conversationRow),
+		aggregated:    make(map[string]*domain.AggregatedEvaluation),
+		suggestions:   make(map[string]*domain.Suggestion),
+		reviews:       make(map[string]*domain.ReviewQueueItem),
+		annotators:    make(map[string]*domain.Annotator),
+		accuracy:      make(map[accuracyKey]*domain.EvaluatorAccuracy),
+		jobs:          make(map[string]*domain.Job),
+		webhooks:      make(map[string]*domain.Webhook),
+	}
+}
+
+// NewRepositories returns every repository backed by a fresh Store.
+func NewRepositories() *storage.Repositories {
+	s := NewStore()
+	return &storage.Repositories{
+		Conversations: &ConversationRepo{s: s},
+		Evaluations:   &EvaluationRepo{s: s},
+		Aggregated:    &AggregatedEvaluationRepo{s: s},
+		Suggestions:   &SuggestionRepo{s: s},
+		Reviews:       &ReviewQueueRepo{s: s},
+		Annotations:   &AnnotationRepo{s: s},
+		Accuracy:      &EvaluatorAccuracyRepo{s: s},
+		Jobs:          &JobRepo{s: s},
+		Webhooks:      &WebhookRepo{s: s},
+	}
+}
+
+var (
+	_ storage.ConversationRepository         = (*ConversationRepo)(nil)
+	_ storage.EvaluationRepository           = (*EvaluationRepo)(nil)
+	_ storage.AggregatedEvaluationRepository = (*AggregatedEvaluationRepo)(nil)
+	_ storage.SuggestionRepository           = (*SuggestionRepo)(nil)
+	_ storage.ReviewQueueRepository          = (*ReviewQueueRepo)(nil)
+	_ storage.AnnotationRepository           = (*AnnotationRepo)(nil)
+	_ storage.EvaluatorAccuracyRepository    = (*EvaluatorAccuracyRepo)(nil)
+	_ storage.JobRepository                  = (*JobRepo)(nil)
+	_ storage.WebhookRepository              = (*WebhookRepo)(nil)
+)
+
+// page applies limit/offset to n items and returns the [start, end) bounds.
+func page(n, limit, offset int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > n {
+		offset = n
+	}
+	end := n
+	if limit > 0 && offset+limit < n {
+		end = offset + limit
+	}
+	return offset, end
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+type SuggestionRepo struct {
+	s *Store
+}
+
+func (r *SuggestionRepo) Create(ctx context.Context, s *domain.Suggestion) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	s.CreatedAt = now
+	s.UpdatedAt = now
+
+	c := *s
+	r.s.suggestions[s.ID] = &c
+	return nil
+}
+
+func (r *SuggestionRepo) GetByID(ctx context.Context, id string) (*domain.Suggestion, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	s, ok := r.s.suggestions[id]
+	if !ok {
+		return nil, nil
+	}
+	c := *s
+	return &c, nil
+}
+
+func (r *SuggestionRepo) List(ctx context.Context, status *domain.SuggestionStatus, limit, offset int) ([]*domain.Suggestion, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var suggestions []*domain.Suggestion
+	for _, s := range r.s.suggestions {
+		if status != nil && s.Status != *status {
+			continue
+		}
+		c := *s
+		suggestions = append(suggestions, &c)
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].CreatedAt.After(suggestions[j].CreatedAt)
+	})
+
+	start, end := page(len(suggestions), limit, offset)
+	return suggestions[start:end], nil
+}
+
+func (r *SuggestionRepo) UpdateStatus(ctx context.Context, id string, status domain.SuggestionStatus) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	s, ok := r.s.suggestions[id]
+	if !ok {
+		return fmt.Errorf("suggestion not found")
+	}
+	s.Status = status
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *SuggestionRepo) GetByPatternID(ctx context.Context, patternID string) (*domain.Suggestion, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var latest *domain.Suggestion
+	for _, s := range r.s.suggestions {
+		if s.PatternID != patternID || s.Status != domain.SuggestionStatusPending {
+			continue
+		}
+		if latest == nil || s.CreatedAt.After(latest.CreatedAt) {
+			latest = s
+		}
+	}
+
+	if latest == nil {
+		return nil, nil
+	}
+	c := *latest
+	return &c, nil
+}
+
+func (r *SuggestionRepo) UpdateImpact(ctx context.Context, id string, impact *domain.ImpactMetrics) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	s, ok := r.s.suggestions[id]
+	if !ok {
+		return fmt.Errorf("suggestion not found")
+	}
+	s.ImpactMeasured = impact
+	s.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+type WebhookRepo struct {
+	s *Store
+}
+
+func (r *WebhookRepo) Create(ctx context.Context, w *domain.Webhook) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	w.CreatedAt = now
+	w.UpdatedAt = now
+
+	c := *w
+	c.Events = append([]domain.WebhookEvent(nil), w.Events...)
+	r.s.webhooks[w.ID] = &c
+	return nil
+}
+
+func (r *WebhookRepo) GetByID(ctx context.Context, id string) (*domain.Webhook, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	w, ok := r.s.webhooks[id]
+	if !ok {
+		return nil, nil
+	}
+	c := *w
+	return &c, nil
+}
+
+func (r *WebhookRepo) List(ctx context.Context) ([]*domain.Webhook, error) {
+	webhooks := r.filter(func(*domain.Webhook) bool { return true })
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.After(webhooks[j].CreatedAt)
+	})
+	return webhooks, nil
+}
+
+// ListForEvent returns the active webhooks subscribed to event.
+func (r *WebhookRepo) ListForEvent(ctx context.Context, event domain.WebhookEvent) ([]*domain.Webhook, error) {
+	webhooks := r.filter(func(w *domain.Webhook) bool {
+		return w.Active && w.Subscribes(event)
+	})
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks, nil
+}
+
+func (r *WebhookRepo) filter(keep func(*domain.Webhook) bool) []*domain.Webhook {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var webhooks []*domain.Webhook
+	for _, w := range r.s.webhooks {
+		if keep(w) {
+			c := *w
+			webhooks = append(webhooks, &c)
+		}
+	}
+	return webhooks
+}
+
+func (r *WebhookRepo) SetActive(ctx context.Context, id string, active bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	w, ok := r.s.webhooks[id]
+	if !ok {
+		return fmt.Errorf("webhook not found")
+	}
+	w.Active = active
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// Delete removes the webhook and its delivery log.
+func (r *WebhookRepo) Delete(ctx context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.webhooks[id]; !ok {
+		return fmt.Errorf("webhook not found")
+	}
+	delete(r.s.webhooks, id)
+
+	kept := r.s.webhookDeliveries[:0]
+	for _, d := range r.s.webhookDeliveries {
+		if d.WebhookID != id {
+			kept = append(kept, d)
+		}
+	}
+	r.s.webhookDeliveries = kept
+	return nil
+}
+
+func (r *WebhookRepo) CreateDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now()
+	}
+
+	c := *d
+	r.s.webhookDeliveries = append(r.s.webhookDeliveries, &c)
+	return nil
+}
+
+// ListDeliveries returns the webhook's delivery attempts, newest first.
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var deliveries []*domain.WebhookDelivery
+	for i := len(r.s.webhookDeliveries) - 1; i >= 0; i-- {
+		if d := r.s.webhookDeliveries[i]; d.WebhookID == webhookID {
+			c := *d
+			deliveries = append(deliveries, &c)
+		}
+	}
+
+	start, end := page(len(deliveries), limit, offset)
+	return deliveries[start:end], nil
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+// The repository interfaces are implemented by the Postgres repos in this
+// package and by the in-memory store in storage/memory.
+
+type ConversationRepository interface {
+	Create(ctx context.Context, conv *domain.Conversation) error
+	CreateBatch(ctx context.Context, convs []*domain.Conversation) error
+	GetByID(ctx context.Context, id string) (*domain.Conversation, error)
+	// Update stores the conversation fn builds from the stored one, nil if
+	// there is none. Updates of one ID run one at a time, so none is lost.
+	// An error from fn leaves the conversation unchanged and is returned.
+	Update(ctx context.Context, id string, fn func(existing *domain.Conversation) (*domain.Conversation, error)) (*domain.Conversation, error)
+	UpdateFeedback(ctx context.Context, id string, feedback *domain.Feedback) error
+	MarkProcessed(ctx context.Context, id string) error
+	MarkProcessedWithStatus(ctx context.Context, id string, status string) error
+	GetUnprocessed(ctx context.Context, limit int) ([]*domain.Conversation, error)
+}
+
+type EvaluationRepository interface {
+	Create(ctx context.Context, eval *domain.Evaluation) error
+	CreateBatch(ctx context.Context, evals []*domain.Evaluation) error
+	GetByConversationID(ctx context.Context, conversationID string) ([]*domain.Evaluation, error)
+	GetByRun(ctx context.Context, conversationID, runID string) ([]*domain.Evaluation, error)
+	GetLabeled(ctx context.Context, evaluatorTypes []domain.EvaluatorType, dateFrom, dateTo time.Time) ([]*domain.Evaluation, error)
+	Query(ctx context.Context, req *domain.EvaluationsQueryRequest) (*domain.EvaluationsQueryResponse, error)
+	FailureRates(ctx context.Context, evaluatorTypes []domain.EvaluatorType, dateFrom, dateTo time.Time) ([]*domain.EvaluatorFailureRate, error)
+}
+
+type AggregatedEvaluationRepository interface {
+	Upsert(ctx context.Context, agg *domain.AggregatedEvaluation) error
+	GetByConversationID(ctx context.Context, conversationID string) (*domain.AggregatedEvaluation, error)
+	List(ctx context.Context, issueType string, limit, offset int) ([]*domain.AggregatedEvaluation, int, error)
+}
+
+type SuggestionRepository interface {
+	Create(ctx context.Context, s *domain.Suggestion) error
+	GetByID(ctx context.Context, id string) (*domain.Suggestion, error)
+	List(ctx context.Context, status *domain.SuggestionStatus, limit, offset int) ([]*domain.Suggestion, error)
+	UpdateStatus(ctx context.Context, id string, status domain.SuggestionStatus) error
+	GetByPatternID(ctx context.Context, patternID string) (*domain.Suggestion, error)
+	UpdateImpact(ctx context.Context, id string, impact *domain.ImpactMetrics) error
+}
+
+type ReviewQueueRepository interface {
+	AddToQueue(ctx context.Context, item *domain.ReviewQueueItem) error
+	GetPending(ctx context.Context, limit int) ([]*domain.ReviewQueueItem, error)
+	GetPendingPaginated(ctx context.Context, limit, offset int) ([]*domain.ReviewQueueItem, error)
+	CountPending(ctx context.Context) (int, error)
+	GetByID(ctx context.Context, id string) (*domain.ReviewQueueItem, error)
+	CompleteReview(ctx context.Context, id string, reviewerNotes string) error
+	AssignReview(ctx context.Context, id string, assignedTo string) error
+}
+
+type AnnotationRepository interface {
+	CreateBatch(ctx context.Context, anns []*domain.Annotation) error
+	GetByConversationID(ctx context.Context, conversationID string) ([]*domain.Annotation, error)
+	GetByConversationIDs(ctx context.Context, conversationIDs []string) ([]*domain.Annotation, error)
+	EvaluationDaysAnnotatedSince(ctx context.Context, since time.Time) ([]time.Time, error)
+	CreateAnnotator(ctx context.Context, a *domain.Annotator) error
+	GetAnnotator(ctx context.Context, id string) (*domain.Annotator, error)
+	ListAnnotators(ctx context.Context, limit, offset int) ([]*domain.Annotator, error)
+}
+
+type EvaluatorAccuracyRepository interface {
+	Upsert(ctx context.Context, acc *domain.EvaluatorAccuracy) error
+	History(ctx context.Context, evaluatorTypes []domain.EvaluatorType, dateFrom, dateTo time.Time) ([]*domain.EvaluatorAccuracy, error)
+}
+
+type JobRepository interface {
+	Create(ctx context.Context, job *domain.Job) error
+	GetByID(ctx context.Context, id string) (*domain.Job, error)
+	UpdateConversationStatus(ctx context.Context, conversationID string, status domain.JobStatus, errMsg string) error
+	Delete(ctx context.Context, id string) error
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, w *domain.Webhook) error
+	GetByID(ctx context.Context, id string) (*domain.Webhook, error)
+	List(ctx context.Context) ([]*domain.Webhook, error)
+	ListForEvent(ctx context.Context, event domain.WebhookEvent) ([]*domain.Webhook, error)
+	SetActive(ctx context.Context, id string, active bool) error
+	Delete(ctx context.Context, id string) error
+	CreateDelivery(ctx context.Context, d *domain.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*domain.WebhookDelivery, error)
+}
+
+var (
+	_ ConversationRepository         = (*ConversationRepo)(nil)
+	_ EvaluationRepository           = (*EvaluationRepo)(nil)
+	_ AggregatedEvaluationRepository = (*AggregatedEvaluationRepo)(nil)
+	_ SuggestionRepository           = (*SuggestionRepo)(nil)
+	_ ReviewQueueRepository          = (*ReviewQueueRepo)(nil)
+	_ AnnotationRepository           = (*AnnotationRepo)(nil)
+	_ EvaluatorAccuracyRepository    = (*EvaluatorAccuracyRepo)(nil)
+	_ JobRepository                  = (*JobRepo)(nil)
+	_ WebhookRepository              = (*WebhookRepo)(nil)
+)
+
+// Repositories bundles one implementation of every repository.
+type Repositories struct {
+	Conversations ConversationRepository
+	Evaluations   EvaluationRepository
+	Aggregated    AggregatedEvaluationRepository
+	Suggestions   SuggestionRepository
+	Reviews       ReviewQueueRepository
+	Annotations   AnnotationRepository
+	Accuracy      EvaluatorAccuracyRepository
+	Jobs          JobRepository
+	Webhooks      WebhookRepository
+}
+
+func NewPostgresRepositories(db *PostgresDB) *Repositories {
+	return &Repositories{
+		Conversations: NewConversationRepo(db),
+		Evaluations:   NewEvaluationRepo(db),
+		Aggregated:    NewAggregatedEvaluationRepo(db),
+		Suggestions:   NewSuggestionRepo(db),
+		Reviews:       NewReviewQueueRepo(db),
+		Annotations:   NewAnnotationRepo(db),
+		Accuracy:      NewEvaluatorAccuracyRepo(db),
+		Jobs:          NewJobRepo(db),
+		Webhooks:      NewWebhookRepo(db),
+	}
+}
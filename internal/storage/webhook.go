@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+)
+
+type WebhookRepo struct {
+	db *PostgresDB
+}
+
+func NewWebhookRepo(db *PostgresDB) *WebhookRepo {
+	return &WebhookRepo{db: db}
+}
+
+func (r *WebhookRepo) Create(ctx context.Context, w *domain.Webhook) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	w.CreatedAt = now
+	w.UpdatedAt = now
+
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO webhooks (id, url, secret, events, description, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, w.ID, w.URL, w.Secret, eventStrings(w.Events), w.Description, w.Active, w.CreatedAt, w.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+
+	return nil
+}
+
+const webhookColumns = `id, url, secret, events, COALESCE(description, ''), active, created_at, updated_at`
+
+func (r *WebhookRepo) GetByID(ctx context.Context, id string) (*domain.Webhook, error) {
+	w, err := scanWebhook(r.db.Pool.QueryRow(ctx, `
+		SELECT `+webhookColumns+` FROM webhooks WHERE id = $1
+	`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+func (r *WebhookRepo) List(ctx context.Context) ([]*domain.Webhook, error) {
+	return r.query(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY created_at DESC`)
+}
+
+// ListForEvent returns the active webhooks subscribed to event.
+func (r *WebhookRepo) ListForEvent(ctx context.Context, event domain.WebhookEvent) ([]*domain.Webhook, error) {
+	return r.query(ctx, `
+		SELECT `+webhookColumns+` FROM webhooks
+		WHERE active AND $1 = ANY(events)
+		ORDER BY created_at
+	`, string(event))
+}
+
+func (r *WebhookRepo) query(ctx context.Context, sql string, args ...interface{}) ([]*domain.Webhook, error) {
+	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*domain.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *WebhookRepo) SetActive(ctx context.Context, id string, active bool) error {
+	result, err := r.db.Pool.Exec(ctx, `
+		UPDATE webhooks SET active = $2, updated_at = NOW() WHERE id = $1
+	`, id, active)
+	if err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	return nil
+}
+
+// Delete removes the webhook and its delivery log.
+func (r *WebhookRepo) Delete(ctx context.Context, id string) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	return nil
+}
+
+func (r *WebhookRepo) CreateDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now()
+	}
+
+	var statusCode *int
+	if d.StatusCode != 0 {
+		statusCode = &d.StatusCode
+	}
+
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO webhook_deliveries (
+			id, webhook_id, event_id, event, attempt, success, status_code,
+			error_message, duration_ms, payload, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11)
+	`, d.ID, d.WebhookID, d.EventID, d.Event, d.Attempt, d.Success, statusCode,
+		d.Error, d.DurationMs, []byte(d.Payload), d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert delivery: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeliveries returns the webhook's delivery attempts, newest first.
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, webhook_id, event_id, event, attempt, success, COALESCE(status_code, 0),
+			COALESCE(error_message, ''), duration_ms, payload, created_at
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, webhookID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.Event, &d.Attempt, &d.Success, &d.StatusCode,
+			&d.Error, &d.DurationMs, &payload, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func scanWebhook(row pgx.Row) (*domain.Webhook, error) {
+	var w domain.Webhook
+	var events []string
+	if err := row.Scan(&w.ID, &w.URL, &w.Secret, &events, &w.Description, &w.Active, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	w.Events = make([]domain.WebhookEvent, len(events))
+	for i, e := range events {
+		w.Events[i] = domain.WebhookEvent(e)
+	}
+	return &w, nil
+}
+
+func eventStrings(events []domain.WebhookEvent) []string {
+	s := make([]string, len(events))
+	for i, e := range events {
+		s[i] = string(e)
+	}
+	return s
+}
@@ -0,0 +1,318 @@
+// Package webhook delivers event notifications to subscribed webhooks with
+// HMAC-signed payloads, retrying failed deliveries with exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saisaravanan/healing-eval/internal/config"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/storage"
+)
+
+// Request headers set on every delivery.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderWebhookID = "X-Webhook-ID"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Dispatcher fans events out to the webhooks subscribed to them. A fixed
+// pool of goroutines delivers from a bounded queue, so a burst of events
+// cannot pile up goroutines; events that find the queue full are dropped
+// and logged. Every attempt is logged; retries still pending when the
+// process stops are dropped. A nil Dispatcher discards events.
+type Dispatcher struct {
+	repo        storage.WebhookRepository
+	client      *http.Client
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+
+	queue  chan *delivery
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// delivery is one event on its way to one webhook.
+type delivery struct {
+	webhook *domain.Webhook
+	payload *domain.WebhookPayload
+	body    []byte
+	attempt int
+}
+
+func NewDispatcher(repo storage.WebhookRepository, cfg *config.WebhookConfig) *Dispatcher {
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = 1000
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !cfg.AllowPrivate {
+		// Checked when connecting, after DNS resolution, so a public name
+		// cannot be pointed at an internal address. Proxies are skipped
+		// since they would be the address checked.
+		transport.Proxy = nil
+		transport.DialContext = (&net.Dialer{Timeout: 30 * time.Second, Control: publicOnly}).DialContext
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		repo:        repo,
+		client:      &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		maxAttempts: maxAttempts,
+		baseDelay:   cfg.RetryBaseDelay,
+		maxDelay:    cfg.RetryMaxDelay,
+		queue:       make(chan *delivery, queueSize),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	for i := 0; i < concurrency; i++ {
+		d.wg.Add(1)
+		go d.run()
+	}
+	return d
+}
+
+// Emit sends event with data to every active webhook subscribed to it. It
+// returns once the deliveries are queued; failures are logged rather than
+// returned so notifications never fail the caller's work.
+func (d *Dispatcher) Emit(ctx context.Context, event domain.WebhookEvent, data interface{}) {
+	if d == nil {
+		return
+	}
+
+	webhooks, err := d.repo.ListForEvent(ctx, event)
+	if err != nil {
+		log.Printf("Webhook %s: list subscribers: %v", event, err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Webhook %s: marshal data: %v", event, err)
+		return
+	}
+
+	payload := domain.WebhookPayload{
+		ID:        uuid.New().String(),
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      dataJSON,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Webhook %s: marshal payload: %v", event, err)
+		return
+	}
+
+	for _, w := range webhooks {
+		d.enqueue(&delivery{webhook: w, payload: &payload, body: body, attempt: 1})
+	}
+}
+
+// enqueue hands dl to the delivery pool without blocking.
+func (d *Dispatcher) enqueue(dl *delivery) {
+	select {
+	case <-d.ctx.Done():
+	case d.queue <- dl:
+	default:
+		log.Printf("Webhook %s to %s dropped: delivery queue full", dl.payload.Event, dl.webhook.ID)
+	}
+}
+
+// Close drops pending retries and waits for requests in flight.
+func (d *Dispatcher) Close() {
+	if d == nil {
+		return
+	}
+	d.cancel()
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) run() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case dl := <-d.queue:
+			d.deliver(dl)
+		}
+	}
+}
+
+// deliver makes one attempt and, if it fails, schedules the next.
+func (d *Dispatcher) deliver(dl *delivery) {
+	w, payload := dl.webhook, dl.payload
+	result := d.post(w, payload.Event, dl.body)
+
+	result.WebhookID = w.ID
+	result.EventID = payload.ID
+	result.Event = payload.Event
+	result.Attempt = dl.attempt
+	if dl.attempt == 1 {
+		// Retries share the event ID; the body is logged once.
+		result.Payload = dl.body
+	}
+	if err := d.repo.CreateDelivery(context.Background(), result); err != nil {
+		log.Printf("Webhook %s to %s: log delivery: %v", payload.Event, w.ID, err)
+	}
+
+	if result.Success {
+		return
+	}
+	log.Printf("Webhook %s to %s failed (attempt %d/%d): %s",
+		payload.Event, w.ID, dl.attempt, d.maxAttempts, result.Error)
+	if dl.attempt >= d.maxAttempts || d.ctx.Err() != nil {
+		return
+	}
+
+	next := *dl
+	next.attempt++
+	time.AfterFunc(d.backoff(dl.attempt), func() { d.enqueue(&next) })
+}
+
+// post sends body to the webhook once. Any 2xx response is a success.
+func (d *Dispatcher) post(w *domain.Webhook, event domain.WebhookEvent, body []byte) *domain.WebhookDelivery {
+	delivery := &domain.WebhookDelivery{CreatedAt: time.Now()}
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(event))
+	req.Header.Set(HeaderWebhookID, w.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(w.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	delivery.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		delivery.Success = true
+	} else {
+		delivery.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return delivery
+}
+
+// backoff returns the wait before the given retry (1-based).
+func (d *Dispatcher) backoff(retry int) time.Duration {
+	delay := d.baseDelay * time.Duration(1<<uint(retry-1))
+	if d.maxDelay > 0 && (delay > d.maxDelay || delay <= 0) {
+		return d.maxDelay
+	}
+	return delay
+}
+
+// Sign returns the signature header for a delivery:
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)). Receivers
+// recompute it to verify the payload and reject stale timestamps.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret returns a random signing secret.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// ErrPrivateAddress is returned for webhook URLs, or the addresses they
+// resolve to, that are not on the public internet.
+var ErrPrivateAddress = errors.New("webhook address is loopback, private or link-local")
+
+// cgnat is the carrier-grade NAT range, private in practice but not in
+// net.IP.IsPrivate.
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// PublicIP reports whether ip is a public unicast address.
+func PublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !cgnat.Contains(ip)
+}
+
+// ValidateURL checks that raw is an absolute http(s) URL and, unless
+// allowPrivate, that it does not name a loopback or private host directly.
+// Names are resolved, and checked again, only when delivering.
+func ValidateURL(raw string, allowPrivate bool) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	if allowPrivate {
+		return nil
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil && !PublicIP(ip) {
+		return ErrPrivateAddress
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// publicOnly is a net.Dialer Control func refusing non-public addresses.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !PublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
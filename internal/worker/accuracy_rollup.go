@@ -0,0 +1,177 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/meta"
+	"github.com/saisaravanan/healing-eval/internal/storage"
+)
+
+// Leaser grants a named lease held by at most one process until it lapses.
+type Leaser interface {
+	TryLease(ctx context.Context, name string, ttl time.Duration) (bool, error)
+}
+
+const rollupLease = "accuracy_rollup"
+
+// AccuracyRollup computes per-evaluator, per-day precision/recall/F1 and
+// human correlation from annotated conversations and stores them in
+// evaluator_accuracy.
+type AccuracyRollup struct {
+	evalRepo           storage.EvaluationRepository
+	annotationRepo     storage.AnnotationRepository
+	accuracyRepo       storage.EvaluatorAccuracyRepository
+	accuracyTracker    *meta.AccuracyTracker
+	calibrationService *meta.CalibrationService
+	leaser             Leaser
+}
+
+func NewAccuracyRollup(
+	evalRepo storage.EvaluationRepository,
+	annotationRepo storage.AnnotationRepository,
+	accuracyRepo storage.EvaluatorAccuracyRepository,
+) *AccuracyRollup {
+	return &AccuracyRollup{
+		evalRepo:           evalRepo,
+		annotationRepo:     annotationRepo,
+		accuracyRepo:       accuracyRepo,
+		accuracyTracker:    meta.NewAccuracyTracker(),
+		calibrationService: meta.NewCalibrationService(),
+	}
+}
+
+// UseLeaser makes Start skip runs while another replica holds the rollup
+// lease. Without one, every process running Start rolls up.
+func (r *AccuracyRollup) UseLeaser(l Leaser) {
+	r.leaser = l
+}
+
+// Start runs the rollup immediately and then on every interval until ctx is
+// cancelled. Each run refreshes yesterday and today (UTC), plus every day
+// whose evaluations were annotated since the previous run, so late
+// annotations of older conversations are reflected too.
+func (r *AccuracyRollup) Start(ctx context.Context, interval time.Duration) {
+	log.Printf("Starting accuracy rollup with interval=%v", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	since := truncateToDay(time.Now()).AddDate(0, 0, -1)
+	for {
+		if r.lead(ctx, interval) {
+			started := time.Now()
+			if err := r.runSince(ctx, since); err != nil {
+				log.Printf("Accuracy rollup failed: %v", err)
+			} else {
+				since = started
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead reports whether this process should run the rollup now. The lease
+// lapses just before the next tick so the same replica usually keeps it.
+func (r *AccuracyRollup) lead(ctx context.Context, interval time.Duration) bool {
+	if r.leaser == nil {
+		return true
+	}
+	ok, err := r.leaser.TryLease(ctx, rollupLease, interval*9/10)
+	if err != nil {
+		log.Printf("Accuracy rollup lease failed: %v", err)
+		return false
+	}
+	return ok
+}
+
+// runSince rolls up yesterday, today and the days of evaluations annotated
+// at or after since.
+func (r *AccuracyRollup) runSince(ctx context.Context, since time.Time) error {
+	days, err := r.annotationRepo.EvaluationDaysAnnotatedSince(ctx, since)
+	if err != nil {
+		return fmt.Errorf("load annotated days: %w", err)
+	}
+	today := truncateToDay(time.Now())
+	days = append(days, today.AddDate(0, 0, -1), today)
+
+	done := make(map[time.Time]bool)
+	var failed int
+	for _, day := range days {
+		if done[day] {
+			continue
+		}
+		done[day] = true
+		if err := r.RunForDate(ctx, day); err != nil {
+			log.Printf("Accuracy rollup for %s failed: %v", day.Format("2006-01-02"), err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d days failed", failed, len(done))
+	}
+	return nil
+}
+
+// RunForDate computes and upserts the rollup for every evaluator type that
+// has labeled evaluations on the given UTC day.
+func (r *AccuracyRollup) RunForDate(ctx context.Context, day time.Time) error {
+	from := truncateToDay(day)
+	to := from.Add(24*time.Hour - time.Nanosecond)
+
+	evals, err := r.evalRepo.GetLabeled(ctx, nil, from, to)
+	if err != nil {
+		return fmt.Errorf("load labeled evaluations: %w", err)
+	}
+
+	anns, err := r.annotationRepo.GetByConversationIDs(ctx, meta.ConversationIDs(evals))
+	if err != nil {
+		return fmt.Errorf("load annotations: %w", err)
+	}
+
+	set := meta.NewLabeledSet(evals, anns)
+
+	for _, et := range set.EvaluatorTypes() {
+		predictions := set.Predictions(et)
+		pairs, _ := set.ComparisonPairs(et)
+		if len(predictions) == 0 && len(pairs) == 0 {
+			continue
+		}
+
+		accuracy := r.accuracyTracker.Calculate(predictions)
+		calibration := r.calibrationService.CalculateCalibration(ctx, et, pairs, from, to)
+
+		row := &domain.EvaluatorAccuracy{
+			EvaluatorType:    et,
+			MetricDate:       from,
+			PrecisionScore:   accuracy.Precision,
+			RecallScore:      accuracy.Recall,
+			F1Score:          accuracy.F1Score,
+			SampleCount:      len(predictions),
+			HumanCorrelation: calibration.PearsonCorrelation,
+		}
+
+		if err := r.accuracyRepo.Upsert(ctx, row); err != nil {
+			return fmt.Errorf("upsert %s: %w", et, err)
+		}
+
+		log.Printf("Accuracy rollup %s %s: precision=%.2f recall=%.2f f1=%.2f samples=%d correlation=%.2f",
+			from.Format("2006-01-02"), et, row.PrecisionScore, row.RecallScore, row.F1Score,
+			row.SampleCount, row.HumanCorrelation)
+	}
+
+	return nil
+}
+
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
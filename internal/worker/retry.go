@@ -0,0 +1,259 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/metrics"
+	"github.com/saisaravanan/healing-eval/internal/queue"
+	"github.com/saisaravanan/healing-eval/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const retryPollInterval = 5 * time.Second
+
+// RetryPolicy controls how failed evaluators are retried. Delays grow
+// exponentially from BaseDelay and are capped at MaxDelay.
+type RetryPolicy struct {
+	MaxAttempts int // retries per evaluator; 0 disables retries
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Backoff returns the delay before the given retry attempt (1-based).
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// scheduleRetry enqueues the next attempt for a failed evaluator, unless the
+// policy's attempts are exhausted. It reports whether a retry was scheduled.
+func (w *Worker) scheduleRetry(ctx context.Context, conversationID string, evalType domain.EvaluatorType, attempt int, lastErr string) bool {
+	if attempt > w.retryPolicy.MaxAttempts {
+		log.Printf("Giving up on %s for %s after %d retries: %s",
+			evalType, conversationID, attempt-1, lastErr)
+		return false
+	}
+
+	delay := w.retryPolicy.Backoff(attempt)
+	job := queue.RetryJob{
+		ConversationID: conversationID,
+		EvaluatorType:  evalType,
+		Attempt:        attempt,
+		LastError:      lastErr,
+	}
+
+	if err := w.queue.ScheduleRetry(ctx, job, time.Now().Add(delay)); err != nil {
+		log.Printf("Failed to schedule retry %d of %s for %s: %v", attempt, evalType, conversationID, err)
+		return false
+	}
+
+	log.Printf("Scheduled retry %d/%d of %s for %s in %v",
+		attempt, w.retryPolicy.MaxAttempts, evalType, conversationID, delay)
+	return true
+}
+
+// runRetries polls for due retry jobs until ctx is cancelled.
+func (w *Worker) runRetries(ctx context.Context) {
+	ticker := time.NewTicker(retryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		jobs, err := w.queue.ClaimDueRetries(ctx, time.Now(), int64(w.batchSize))
+		if err != nil {
+			log.Printf("Error claiming retries: %v", err)
+		}
+
+		w.processRetries(ctx, jobs)
+	}
+}
+
+// processRetries runs claimed jobs concurrently, up to the worker's
+// concurrency, and releases each one once handled. A job interrupted by
+// shutdown is left claimed and becomes due again when its lease lapses.
+func (w *Worker) processRetries(ctx context.Context, jobs []queue.RetryJob) {
+	sem := make(chan struct{}, max(w.concurrency, 1))
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(job queue.RetryJob) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			w.processRetry(ctx, job)
+			if ctx.Err() != nil {
+				return
+			}
+			if err := w.queue.CompleteRetry(ctx, job); err != nil {
+				log.Printf("Failed to complete retry %d of %s for %s: %v",
+					job.Attempt, job.EvaluatorType, job.ConversationID, err)
+				return
+			}
+			w.finishRetrying(ctx, job.ConversationID)
+		}(job)
+	}
+	wg.Wait()
+}
+
+// processRetry re-runs one evaluator and, on success, stores it and
+// re-computes the conversation's aggregated result.
+func (w *Worker) processRetry(ctx context.Context, job queue.RetryJob) {
+	ctx, span := tracing.Tracer().Start(ctx, "retry evaluator",
+		trace.WithAttributes(
+			attribute.String("conversation.id", job.ConversationID),
+			attribute.String("evaluator.type", string(job.EvaluatorType)),
+			attribute.Int("retry.attempt", job.Attempt),
+		),
+	)
+	defer span.End()
+
+	conv, err := w.convRepo.GetByID(ctx, job.ConversationID)
+	if err != nil {
+		log.Printf("Retry %d of %s for %s: load conversation: %v",
+			job.Attempt, job.EvaluatorType, job.ConversationID, err)
+		w.scheduleRetry(ctx, job.ConversationID, job.EvaluatorType, job.Attempt+1, err.Error())
+		return
+	}
+	if conv == nil {
+		log.Printf("Retry of %s for %s dropped: conversation not found", job.EvaluatorType, job.ConversationID)
+		return
+	}
+
+	eval, failure := w.orchestrator.EvaluateOne(ctx, conv, job.EvaluatorType)
+	if failure != nil {
+		log.Printf("Retry %d of %s for %s failed: %s", job.Attempt, job.EvaluatorType, conv.ID, failure.ErrorMessage)
+		failed := failedEvaluation(conv.ID, *failure, job.Attempt)
+		if err := w.evalRepo.Create(ctx, failed); err != nil {
+			log.Printf("Retry %d of %s for %s: store failure: %v", job.Attempt, job.EvaluatorType, conv.ID, err)
+		}
+		metrics.ObserveEvaluations(failed)
+		if failure.Retryable {
+			w.scheduleRetry(ctx, conv.ID, job.EvaluatorType, job.Attempt+1, failure.ErrorMessage)
+		}
+		return
+	}
+
+	eval.RetryCount = job.Attempt
+	if err := w.evalRepo.Create(ctx, eval); err != nil {
+		log.Printf("Retry %d of %s for %s: store evaluation: %v", job.Attempt, job.EvaluatorType, conv.ID, err)
+		w.scheduleRetry(ctx, conv.ID, job.EvaluatorType, job.Attempt+1, err.Error())
+		return
+	}
+	metrics.ObserveEvaluations(eval)
+
+	result, err := w.reaggregate(ctx, conv)
+	if err != nil {
+		log.Printf("Retry of %s for %s: re-aggregate: %v", job.EvaluatorType, conv.ID, err)
+		return
+	}
+	w.webhooks.Emit(ctx, domain.WebhookEventEvaluationCompleted, result)
+
+	log.Printf("Retry %d of %s for %s succeeded: status=%s overall=%.2f success=%d/%d",
+		job.Attempt, job.EvaluatorType, conv.ID, result.Status, result.Scores.Overall,
+		result.SuccessfulCount, result.ExpectedCount)
+}
+
+// finishRetrying settles the job status of a conversation once none of its
+// retries is left: done if they all succeeded, partial otherwise.
+func (w *Worker) finishRetrying(ctx context.Context, conversationID string) {
+	pending, err := w.queue.PendingRetriesFor(ctx, conversationID)
+	if err != nil {
+		log.Printf("Failed to count pending retries of %s: %v", conversationID, err)
+		return
+	}
+	if pending > 0 {
+		return
+	}
+
+	result, err := w.aggRepo.GetByConversationID(ctx, conversationID)
+	if err != nil || result == nil {
+		log.Printf("Failed to load aggregated evaluation of %s: %v", conversationID, err)
+		return
+	}
+	w.updateJobStatus(ctx, conversationID, domain.JobStatusFor(result.Status), "")
+}
+
+// reaggregate rebuilds and stores the aggregated evaluation from the latest
+// successful stored evaluation of each pipeline evaluator.
+func (w *Worker) reaggregate(ctx context.Context, conv *domain.Conversation) (*domain.AggregatedEvaluation, error) {
+	stored, err := w.evalRepo.GetByConversationID(ctx, conv.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := w.aggregateStored(conv, stored)
+
+	if err := w.aggRepo.Upsert(ctx, result); err != nil {
+		return nil, err
+	}
+
+	if err := w.convRepo.MarkProcessedWithStatus(ctx, conv.ID, string(result.Status)); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// aggregateStored builds the aggregated evaluation from stored evaluations,
+// newest first: the latest success of each pipeline evaluator, or else its
+// latest failure.
+func (w *Worker) aggregateStored(conv *domain.Conversation, stored []*domain.Evaluation) *domain.AggregatedEvaluation {
+	latest := make(map[domain.EvaluatorType]*domain.Evaluation)
+	lastFailure := make(map[domain.EvaluatorType]*domain.Evaluation)
+	for _, e := range stored {
+		target := latest
+		if e.Status != domain.EvalStatusSuccess {
+			target = lastFailure
+		}
+		if _, ok := target[e.EvaluatorType]; !ok {
+			target[e.EvaluatorType] = e
+		}
+	}
+
+	var successful []*domain.Evaluation
+	var failures []domain.EvaluatorFailure
+	for _, et := range w.orchestrator.EvaluatorTypes() {
+		if e, ok := latest[et]; ok {
+			successful = append(successful, e)
+			continue
+		}
+		failure := domain.EvaluatorFailure{
+			EvaluatorType: et,
+			Status:        domain.EvalStatusFailed,
+			ErrorMessage:  "no successful evaluation",
+		}
+		if f, ok := lastFailure[et]; ok {
+			failure.Status = f.Status
+			failure.Provider = f.Provider
+			failure.ErrorMessage = f.ErrorMessage
+			failure.LatencyMs = f.LatencyMs
+			// Only the status survives storage; these two are always transient.
+			failure.Retryable = f.Status == domain.EvalStatusTimeout || f.Status == domain.EvalStatusRateLimited
+		}
+		failures = append(failures, failure)
+	}
+
+	return w.orchestrator.Aggregate(conv, successful, failures)
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"fmt"
+
+	"github.com/saisaravanan/healing-eval/internal/config"
+	"github.com/saisaravanan/healing-eval/internal/evaluator"
+	"github.com/saisaravanan/healing-eval/internal/llm"
+	"github.com/saisaravanan/healing-eval/internal/prompt"
+	"github.com/saisaravanan/healing-eval/internal/queue"
+	"github.com/saisaravanan/healing-eval/internal/storage"
+	"github.com/saisaravanan/healing-eval/internal/webhook"
+)
+
+// NewFromConfig builds the LLM client, prompt templates and evaluator
+// pipeline described by cfg and returns a worker over repos and q that
+// notifies webhooks.
+func NewFromConfig(cfg *config.Config, repos *storage.Repositories, q queue.Queue, webhooks *webhook.Dispatcher, cache llm.Cache) (*Worker, error) {
+	llmClient, err := llm.NewClient(&cfg.LLM)
+	if err != nil {
+		return nil, fmt.Errorf("create LLM client: %w", err)
+	}
+	llmClient.UseCache(cache)
+
+	pipeline, err := evaluator.LoadPipelineConfig(cfg.Worker.PipelineFile)
+	if err != nil {
+		return nil, fmt.Errorf("load evaluator pipeline: %w", err)
+	}
+
+	prompts, err := prompt.NewLibrary(cfg.LLM.PromptDir)
+	if err != nil {
+		return nil, fmt.Errorf("load prompt templates: %w", err)
+	}
+
+	orchestrator, err := evaluator.BuildOrchestrator(pipeline, llmClient, prompts)
+	if err != nil {
+		return nil, fmt.Errorf("build evaluator pipeline: %w", err)
+	}
+
+	return New(
+		q,
+		repos.Conversations,
+		repos.Evaluations,
+		repos.Aggregated,
+		repos.Jobs,
+		repos.Reviews,
+		orchestrator,
+		webhooks,
+		cfg.Worker.Concurrency,
+		cfg.Worker.BatchSize,
+		cfg.Worker.ClaimInterval,
+		RetryPolicy{
+			MaxAttempts: cfg.Worker.RetryMaxAttempts,
+			BaseDelay:   cfg.Worker.RetryBaseDelay,
+			MaxDelay:    cfg.Worker.RetryMaxDelay,
+		},
+	), nil
+}
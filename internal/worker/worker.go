@@ -10,47 +10,70 @@ import (
 	"github.com/saisaravanan/healing-eval/internal/domain"
 	"github.com/saisaravanan/healing-eval/internal/evaluator"
 	"github.com/saisaravanan/healing-eval/internal/feedback"
+	"github.com/saisaravanan/healing-eval/internal/llm"
 	"github.com/saisaravanan/healing-eval/internal/meta"
+	"github.com/saisaravanan/healing-eval/internal/metrics"
 	"github.com/saisaravanan/healing-eval/internal/queue"
 	"github.com/saisaravanan/healing-eval/internal/storage"
+	"github.com/saisaravanan/healing-eval/internal/tracing"
+	"github.com/saisaravanan/healing-eval/internal/webhook"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Worker struct {
-	queue              *queue.RedisQueue
-	convRepo           *storage.ConversationRepo
-	evalRepo           *storage.EvaluationRepo
-	reviewQueueRepo    *storage.ReviewQueueRepo
+	queue              queue.Queue
+	convRepo           storage.ConversationRepository
+	evalRepo           storage.EvaluationRepository
+	aggRepo            storage.AggregatedEvaluationRepository
+	jobRepo            storage.JobRepository
+	reviewQueueRepo    storage.ReviewQueueRepository
 	orchestrator       *evaluator.Orchestrator
+	webhooks           *webhook.Dispatcher
 	agreementCalc      *feedback.AgreementCalculator
 	accuracyTracker    *meta.AccuracyTracker
 	calibrationService *meta.CalibrationService
 	confidenceRouter   *feedback.ConfidenceRouter
 	concurrency        int
 	batchSize          int
+	reclaimInterval    time.Duration
+	retryPolicy        RetryPolicy
 }
 
 func New(
-	q *queue.RedisQueue,
-	convRepo *storage.ConversationRepo,
-	evalRepo *storage.EvaluationRepo,
-	reviewQueueRepo *storage.ReviewQueueRepo,
+	q queue.Queue,
+	convRepo storage.ConversationRepository,
+	evalRepo storage.EvaluationRepository,
+	aggRepo storage.AggregatedEvaluationRepository,
+	jobRepo storage.JobRepository,
+	reviewQueueRepo storage.ReviewQueueRepository,
 	orchestrator *evaluator.Orchestrator,
+	webhooks *webhook.Dispatcher,
 	concurrency int,
 	batchSize int,
+	reclaimInterval time.Duration,
+	retryPolicy RetryPolicy,
 ) *Worker {
-	return &Worker{
+	w := &Worker{
 		queue:              q,
 		convRepo:           convRepo,
 		evalRepo:           evalRepo,
+		aggRepo:            aggRepo,
+		jobRepo:            jobRepo,
 		reviewQueueRepo:    reviewQueueRepo,
 		orchestrator:       orchestrator,
+		webhooks:           webhooks,
 		agreementCalc:      feedback.NewAgreementCalculator(),
 		accuracyTracker:    meta.NewAccuracyTracker(),
 		calibrationService: meta.NewCalibrationService(),
 		confidenceRouter:   feedback.NewConfidenceRouter(),
 		concurrency:        concurrency,
 		batchSize:          batchSize,
+		reclaimInterval:    reclaimInterval,
+		retryPolicy:        retryPolicy,
 	}
+	q.OnDeadLetter(w.deadLettered)
+	return w
 }
 
 func (w *Worker) Start(ctx context.Context) error {
@@ -67,20 +90,39 @@ func (w *Worker) Start(ctx context.Context) error {
 		}(i)
 	}
 
+	if w.retryPolicy.MaxAttempts > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.runRetries(ctx)
+		}()
+	}
+
 	go func() {
+		var lastReclaim time.Time
 		for {
 			select {
 			case <-ctx.Done():
 				close(jobs)
 				return
 			default:
-				messages, err := w.queue.Consume(ctx, int64(w.batchSize), 5*time.Second)
+				var messages []queue.Message
+				var err error
+				if w.reclaimInterval > 0 && time.Since(lastReclaim) >= w.reclaimInterval {
+					lastReclaim = time.Now()
+					messages, err = w.queue.Reclaim(ctx, int64(w.batchSize))
+					if len(messages) > 0 {
+						log.Printf("Reclaimed %d idle pending messages", len(messages))
+					}
+				}
+				if err == nil && len(messages) == 0 {
+					messages, err = w.queue.Consume(ctx, int64(w.batchSize), 5*time.Second)
+				}
 				if err != nil {
 					log.Printf("Error consuming messages: %v", err)
-					time.Sleep(time.Second)
-					continue
 				}
 
+				// Messages read before an error are still ours to process.
 				for _, msg := range messages {
 					select {
 					case jobs <- msg:
@@ -89,6 +131,10 @@ func (w *Worker) Start(ctx context.Context) error {
 						return
 					}
 				}
+
+				if err != nil {
+					time.Sleep(time.Second)
+				}
 			}
 		}
 	}()
@@ -101,45 +147,112 @@ func (w *Worker) processJobs(ctx context.Context, workerID int, jobs <-chan queu
 	for msg := range jobs {
 		if err := w.processConversation(ctx, msg); err != nil {
 			log.Printf("Worker %d: error processing %s: %v", workerID, msg.Conversation.ID, err)
+			// Left unacked for redelivery, so the job waits for it rather
+			// than finishing; it fails if the message is dead-lettered.
+			w.updateJobStatus(ctx, msg.Conversation.ID, domain.JobStatusQueued, err.Error())
 			continue
 		}
 
-		if err := w.queue.Ack(ctx, msg.ID); err != nil {
+		if err := w.queue.Ack(ctx, msg); err != nil {
 			log.Printf("Worker %d: error acking %s: %v", workerID, msg.ID, err)
 		}
 	}
 }
 
-func (w *Worker) processConversation(ctx context.Context, msg queue.Message) error {
+func (w *Worker) processConversation(ctx context.Context, msg queue.Message) (err error) {
 	conv := msg.Conversation
+
+	// Continue the trace started by the ingest request.
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, msg.Trace), "process conversation",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("conversation.id", conv.ID),
+			attribute.String("queue.priority", string(msg.Priority)),
+			attribute.String("queue.message_id", msg.ID),
+		),
+	)
+	defer func() {
+		if err != nil {
+			tracing.RecordError(span, err)
+		}
+		span.End()
+	}()
+	if msg.BypassCache {
+		ctx = llm.WithCacheBypass(ctx)
+	}
+
 	log.Printf("Processing conversation: %s", conv.ID)
+	w.updateJobStatus(ctx, conv.ID, domain.JobStatusEvaluating, "")
+
+	// A redelivered message reuses what its earlier delivery stored, so a
+	// failure below costs no second round of LLM calls or duplicate rows.
+	stored, err := w.evalRepo.GetByRun(ctx, conv.ID, msg.ID)
+	if err != nil {
+		return fmt.Errorf("load stored evaluations: %w", err)
+	}
 
-	// Orchestrator ALWAYS returns result, never returns error
-	result, _ := w.orchestrator.Evaluate(ctx, conv)
+	var result *domain.AggregatedEvaluation
+	if len(stored) > 0 {
+		log.Printf("Reusing %d stored evaluations of %s from message %s", len(stored), conv.ID, msg.ID)
+		result = w.aggregateStored(conv, stored)
+	} else {
+		// Orchestrator ALWAYS returns result, never returns error
+		result, _ = w.orchestrator.Evaluate(ctx, conv)
+
+		// Store ALL evaluations (including failed ones)
+		evals := make([]*domain.Evaluation, 0, len(result.Evaluations)+len(result.FailedEvaluators))
+		for i := range result.Evaluations {
+			evals = append(evals, &result.Evaluations[i])
+		}
+		for _, failure := range result.FailedEvaluators {
+			evals = append(evals, failedEvaluation(conv.ID, failure, 0))
+		}
+		for _, e := range evals {
+			e.RunID = msg.ID
+		}
 
-	// Store ALL evaluations (including failed ones)
-	evals := make([]*domain.Evaluation, len(result.Evaluations))
-	for i := range result.Evaluations {
-		evals[i] = &result.Evaluations[i]
+		if err := w.evalRepo.CreateBatch(ctx, evals); err != nil {
+			return fmt.Errorf("store evaluations: %w", err)
+		}
+		metrics.ObserveEvaluations(evals...)
 	}
 
-	if err := w.evalRepo.CreateBatch(ctx, evals); err != nil {
-		return fmt.Errorf("store evaluations: %w", err)
+	if err := w.aggRepo.Upsert(ctx, result); err != nil {
+		return fmt.Errorf("store aggregated evaluation: %w", err)
 	}
 
+	// Mark as processed with status
+	if err := w.convRepo.MarkProcessedWithStatus(ctx, conv.ID, string(result.Status)); err != nil {
+		return fmt.Errorf("mark processed: %w", err)
+	}
+
+	// Side effects come last so a failed delivery does not repeat them.
+	w.webhooks.Emit(ctx, domain.WebhookEventEvaluationCompleted, result)
+
+	span.SetAttributes(
+		attribute.String("evaluation.status", string(result.Status)),
+		attribute.Float64("evaluation.overall_score", result.Scores.Overall),
+	)
+
 	// Log comprehensive token usage and status
 	log.Printf("Conversation %s: Status=%s, Tokens=%d, Cost=$%.4f, Success=%d/%d",
 		conv.ID, result.Status, result.TokenUsage.TotalTokens, result.TokenUsage.TotalCost,
 		result.SuccessfulCount, result.ExpectedCount)
 
-	// Log failed evaluators if any
+	// Log failed evaluators if any, and retry the transient failures. The
+	// job waits for the retries rather than finishing as partial.
+	jobStatus := domain.JobStatusFor(result.Status)
 	if len(result.FailedEvaluators) > 0 {
 		log.Printf("Failed evaluators for %s:", conv.ID)
 		for _, failure := range result.FailedEvaluators {
 			log.Printf("  - %s: %s (retryable=%v)",
 				failure.EvaluatorType, failure.ErrorMessage, failure.Retryable)
+			if failure.Retryable && w.scheduleRetry(ctx, conv.ID, failure.EvaluatorType, 1, failure.ErrorMessage) {
+				jobStatus = domain.JobStatusRetrying
+			}
 		}
 	}
+	w.updateJobStatus(ctx, conv.ID, jobStatus, "")
 
 	// Log token usage by evaluator
 	if len(result.TokenUsage.ByEvaluator) > 0 {
@@ -163,17 +276,45 @@ func (w *Worker) processConversation(ctx context.Context, msg queue.Message) err
 		}
 	}
 
-	// Mark as processed with status
-	if err := w.convRepo.MarkProcessedWithStatus(ctx, conv.ID, string(result.Status)); err != nil {
-		return err
-	}
-
 	log.Printf("Completed evaluation for %s: overall=%.2f, issues=%d",
 		conv.ID, result.Scores.Overall, len(result.Issues))
 
 	return nil
 }
 
+// deadLettered fails the job of a message that ran out of deliveries or
+// could not be decoded.
+func (w *Worker) deadLettered(ctx context.Context, letter queue.DeadLetter) {
+	if letter.ConversationID == "" {
+		return
+	}
+	log.Printf("Dead-lettered %s (message %s): %s", letter.ConversationID, letter.MessageID, letter.Reason)
+	w.updateJobStatus(ctx, letter.ConversationID, domain.JobStatusFailed, "dead-lettered: "+letter.Reason)
+}
+
+// updateJobStatus moves the conversation on in the ingestion jobs tracking
+// it. Job tracking is best-effort and never fails processing.
+func (w *Worker) updateJobStatus(ctx context.Context, conversationID string, status domain.JobStatus, errMsg string) {
+	if err := w.jobRepo.UpdateConversationStatus(ctx, conversationID, status, errMsg); err != nil {
+		log.Printf("Failed to update job status of %s: %v", conversationID, err)
+	}
+}
+
+// failedEvaluation records a failed evaluator run as an evaluation row so
+// failure rates can be reported per evaluator and provider.
+func failedEvaluation(conversationID string, failure domain.EvaluatorFailure, retryCount int) *domain.Evaluation {
+	return &domain.Evaluation{
+		ConversationID: conversationID,
+		EvaluatorType:  failure.EvaluatorType,
+		Status:         failure.Status,
+		Provider:       failure.Provider,
+		ErrorMessage:   failure.ErrorMessage,
+		LatencyMs:      failure.LatencyMs,
+		RetryCount:     retryCount,
+		CreatedAt:      time.Now(),
+	}
+}
+
 func (w *Worker) processFeedback(ctx context.Context, conv *domain.Conversation, result *domain.AggregatedEvaluation) {
 	annotations := conv.Feedback.Annotations
 	
@@ -266,7 +407,12 @@ func (w *Worker) addToReviewQueue(ctx context.Context, conv *domain.Conversation
 
 	log.Printf("Adding conversation %s to human review queue: %s (priority=%d)", conv.ID, reason, priority)
 
-	return w.reviewQueueRepo.AddToQueue(ctx, item)
+	if err := w.reviewQueueRepo.AddToQueue(ctx, item); err != nil {
+		return err
+	}
+
+	w.webhooks.Emit(ctx, domain.WebhookEventReviewRouted, item)
+	return nil
 }
 
 func (w *Worker) determineReviewReason(result *domain.AggregatedEvaluation) string {
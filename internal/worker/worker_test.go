@@ -0,0 +1,234 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/saisaravanan/healing-eval/internal/config"
+	"github.com/saisaravanan/healing-eval/internal/domain"
+	"github.com/saisaravanan/healing-eval/internal/queue"
+	"github.com/saisaravanan/healing-eval/internal/storage"
+	"github.com/saisaravanan/healing-eval/internal/storage/memory"
+)
+
+// harness is a worker over the fake LLM provider, in-memory repositories
+// and an in-memory queue.
+type harness struct {
+	worker *Worker
+	repos  *storage.Repositories
+	queue  *queue.MemoryQueue
+}
+
+func newHarness(t *testing.T, env map[string]string) *harness {
+	t.Helper()
+	t.Setenv("LLM_DEFAULT_PROVIDER", "fake")
+	t.Setenv("LLM_CACHE", "")
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	q, err := queue.NewMemoryQueue(&cfg.Worker)
+	if err != nil {
+		t.Fatalf("create queue: %v", err)
+	}
+	repos := memory.NewRepositories()
+	w, err := NewFromConfig(cfg, repos, q, nil, nil)
+	if err != nil {
+		t.Fatalf("create worker: %v", err)
+	}
+	return &harness{worker: w, repos: repos, queue: q}
+}
+
+// ingest stores conv under a new job and publishes it, as the API does, and
+// returns the job ID and the consumed message.
+func (h *harness) ingest(t *testing.T, conv *domain.Conversation) (string, queue.Message) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := h.repos.Conversations.Create(ctx, conv); err != nil {
+		t.Fatalf("create conversation: %v", err)
+	}
+	job := &domain.Job{Conversations: []domain.JobConversation{{ConversationID: conv.ID}}}
+	if err := h.repos.Jobs.Create(ctx, job); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	if err := h.queue.Publish(ctx, conv, queue.PriorityNormal); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	msgs, err := h.queue.Consume(ctx, 1, time.Second)
+	if err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("consumed %d messages, want 1", len(msgs))
+	}
+	return job.ID, msgs[0]
+}
+
+func testConversation(id string) *domain.Conversation {
+	now := time.Now().UTC()
+	return &domain.Conversation{
+		ID:           id,
+		AgentVersion: "v1.0.0",
+		Turns: []domain.Turn{
+			{TurnID: 1, Role: "user", Content: "Book me a flight to Paris next Friday.", Timestamp: now},
+			{
+				TurnID:  2,
+				Role:    "assistant",
+				Content: "I found a direct flight on Friday at 9am.",
+				ToolCalls: []domain.ToolCall{{
+					ToolName:   "flight_search",
+					Parameters: json.RawMessage(`{"destination":"Paris","date":"next Friday"}`),
+					Result:     &domain.ToolResult{Status: "success", Data: json.RawMessage(`{"flights":1}`)},
+					LatencyMs:  120,
+				}},
+				Timestamp: now.Add(time.Second),
+			},
+		},
+	}
+}
+
+func TestProcessConversation(t *testing.T) {
+	h := newHarness(t, nil)
+	ctx := context.Background()
+	conv := testConversation("worker-e2e")
+	jobID, msg := h.ingest(t, conv)
+
+	if err := h.worker.processConversation(ctx, msg); err != nil {
+		t.Fatalf("processConversation: %v", err)
+	}
+
+	agg, err := h.repos.Aggregated.GetByConversationID(ctx, conv.ID)
+	if err != nil || agg == nil {
+		t.Fatalf("aggregated evaluation = %v, %v; want stored", agg, err)
+	}
+	if agg.Status != domain.AggregatedStatusSuccess {
+		t.Errorf("status = %s, want success (failures: %+v)", agg.Status, agg.FailedEvaluators)
+	}
+
+	evals, err := h.repos.Evaluations.GetByConversationID(ctx, conv.ID)
+	if err != nil {
+		t.Fatalf("get evaluations: %v", err)
+	}
+	if len(evals) != agg.ExpectedCount {
+		t.Errorf("stored %d evaluations, want %d", len(evals), agg.ExpectedCount)
+	}
+	for _, e := range evals {
+		if e.RunID != msg.ID {
+			t.Errorf("%s evaluation run = %q, want %q", e.EvaluatorType, e.RunID, msg.ID)
+		}
+	}
+
+	job, err := h.repos.Jobs.GetByID(ctx, jobID)
+	if err != nil || job == nil {
+		t.Fatalf("job = %v, %v; want stored", job, err)
+	}
+	if job.Status != domain.JobStatusDone {
+		t.Errorf("job status = %s, want done", job.Status)
+	}
+
+	// A redelivery reuses the stored rows instead of evaluating again.
+	if err := h.worker.processConversation(ctx, msg); err != nil {
+		t.Fatalf("processConversation (redelivery): %v", err)
+	}
+	again, err := h.repos.Evaluations.GetByConversationID(ctx, conv.ID)
+	if err != nil {
+		t.Fatalf("get evaluations: %v", err)
+	}
+	if len(again) != len(evals) {
+		t.Errorf("after redelivery stored %d evaluations, want %d", len(again), len(evals))
+	}
+}
+
+func TestProcessConversationRateLimited(t *testing.T) {
+	h := newHarness(t, map[string]string{
+		"LLM_FAKE_RATE_LIMIT_RATE": "1",
+		"EVAL_RETRY_MAX_ATTEMPTS":  "1",
+		"EVAL_RETRY_BASE_DELAY":    "1ms",
+	})
+	ctx := context.Background()
+	conv := testConversation("worker-e2e-throttled")
+	jobID, msg := h.ingest(t, conv)
+
+	if err := h.worker.processConversation(ctx, msg); err != nil {
+		t.Fatalf("processConversation: %v", err)
+	}
+
+	agg, err := h.repos.Aggregated.GetByConversationID(ctx, conv.ID)
+	if err != nil || agg == nil {
+		t.Fatalf("aggregated evaluation = %v, %v; want stored", agg, err)
+	}
+	if agg.Status != domain.AggregatedStatusPartial {
+		t.Errorf("status = %s, want partial", agg.Status)
+	}
+	if len(agg.FailedEvaluators) == 0 {
+		t.Fatal("no failed evaluators, want the LLM evaluators rate limited")
+	}
+	for _, f := range agg.FailedEvaluators {
+		if f.Status != domain.EvalStatusRateLimited || !f.Retryable {
+			t.Errorf("%s failure = %s (retryable=%v), want retryable rate_limited", f.EvaluatorType, f.Status, f.Retryable)
+		}
+		if f.Provider != "fake" {
+			t.Errorf("%s failure provider = %q, want fake", f.EvaluatorType, f.Provider)
+		}
+	}
+
+	evals, err := h.repos.Evaluations.GetByConversationID(ctx, conv.ID)
+	if err != nil {
+		t.Fatalf("get evaluations: %v", err)
+	}
+	if len(evals) != agg.ExpectedCount {
+		t.Errorf("stored %d evaluations, want %d including failures", len(evals), agg.ExpectedCount)
+	}
+
+	retries, err := h.queue.PendingRetriesFor(ctx, conv.ID)
+	if err != nil {
+		t.Fatalf("pending retries: %v", err)
+	}
+	if retries != int64(len(agg.FailedEvaluators)) {
+		t.Errorf("pending retries = %d, want %d", retries, len(agg.FailedEvaluators))
+	}
+
+	// The job is not finished while the retries are pending.
+	if status := h.jobStatus(t, jobID); status != domain.JobStatusRetrying {
+		t.Errorf("job status = %s, want retrying", status)
+	}
+
+	// The only retry fails too, so the conversation settles as partial.
+	time.Sleep(5 * time.Millisecond)
+	jobs, err := h.queue.ClaimDueRetries(ctx, time.Now(), 0)
+	if err != nil {
+		t.Fatalf("claim retries: %v", err)
+	}
+	if len(jobs) != len(agg.FailedEvaluators) {
+		t.Fatalf("claimed %d retries, want %d", len(jobs), len(agg.FailedEvaluators))
+	}
+	h.worker.processRetries(ctx, jobs[:1])
+	if status := h.jobStatus(t, jobID); status != domain.JobStatusRetrying {
+		t.Errorf("job status with retries left = %s, want retrying", status)
+	}
+	h.worker.processRetries(ctx, jobs[1:])
+
+	if retries, err := h.queue.PendingRetries(ctx); err != nil || retries != 0 {
+		t.Errorf("pending retries = %d, %v; want none after the attempts ran out", retries, err)
+	}
+	if status := h.jobStatus(t, jobID); status != domain.JobStatusPartial {
+		t.Errorf("job status = %s, want partial", status)
+	}
+}
+
+func (h *harness) jobStatus(t *testing.T, jobID string) domain.JobStatus {
+	t.Helper()
+	job, err := h.repos.Jobs.GetByID(context.Background(), jobID)
+	if err != nil || job == nil {
+		t.Fatalf("job = %v, %v; want stored", job, err)
+	}
+	return job.Status
+}
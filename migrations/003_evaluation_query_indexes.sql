@@ -0,0 +1,5 @@
+-- Indexes backing the score and issue filters in EvaluationRepo.Query
+
+CREATE INDEX IF NOT EXISTS idx_evaluations_overall_score ON evaluations(((scores->>'overall')::float8));
+CREATE INDEX IF NOT EXISTS idx_evaluations_issues ON evaluations USING GIN (issues jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_evaluations_type_created_at ON evaluations(evaluator_type, created_at);
@@ -0,0 +1,3 @@
+-- One accuracy rollup row per evaluator per day, so the daily job can upsert
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_evaluator_accuracy_type_date ON evaluator_accuracy(evaluator_type, metric_date);
@@ -0,0 +1,6 @@
+-- Record which prompt template (name and content hash) produced each evaluation
+
+ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS prompt_template VARCHAR(64);
+ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS prompt_hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_evaluations_prompt ON evaluations(evaluator_type, prompt_template, prompt_hash);
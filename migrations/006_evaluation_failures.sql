@@ -0,0 +1,6 @@
+-- Record the LLM provider on each evaluation so failed runs can be broken
+-- down per evaluator and provider
+
+ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS provider VARCHAR(32);
+
+CREATE INDEX IF NOT EXISTS idx_evaluations_failures ON evaluations(created_at, evaluator_type, provider, status);
@@ -0,0 +1,20 @@
+-- One aggregated evaluation per conversation: the weighted overall score the
+-- orchestrator computes from the per-evaluator rows
+
+CREATE TABLE IF NOT EXISTS aggregated_evaluations (
+    conversation_id VARCHAR(64) PRIMARY KEY,
+    status VARCHAR(32) NOT NULL,
+    overall_score FLOAT NOT NULL,
+    scores JSONB NOT NULL,
+    token_usage JSONB NOT NULL,
+    failed_evaluators JSONB DEFAULT '[]',
+    successful_count INT NOT NULL,
+    expected_count INT NOT NULL,
+    tool_evaluation JSONB,
+    issues JSONB DEFAULT '[]',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_aggregated_evaluations_created_at ON aggregated_evaluations(created_at);
+CREATE INDEX IF NOT EXISTS idx_aggregated_evaluations_status ON aggregated_evaluations(status);
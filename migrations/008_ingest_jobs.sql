@@ -0,0 +1,19 @@
+-- Ingestion jobs: one per ingest call, tracking each conversation from
+-- queued through evaluation
+
+CREATE TABLE IF NOT EXISTS ingest_jobs (
+    id VARCHAR(64) PRIMARY KEY,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ingest_job_conversations (
+    job_id VARCHAR(64) NOT NULL REFERENCES ingest_jobs(id) ON DELETE CASCADE,
+    conversation_id VARCHAR(64) NOT NULL,
+    position INT NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'queued',
+    error_message TEXT,
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (job_id, conversation_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingest_job_conversations_conversation ON ingest_job_conversations(conversation_id, status);
@@ -0,0 +1,3 @@
+-- Mark evaluations whose LLM response came from the response cache
+
+ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS cached BOOLEAN DEFAULT FALSE;
@@ -0,0 +1,8 @@
+-- Tie evaluations to the queue delivery that produced them, so a redelivered
+-- message reuses its stored evaluations instead of evaluating again
+
+ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS run_id VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_evaluations_run
+    ON evaluations(conversation_id, run_id, evaluator_type)
+    WHERE run_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Store the event body once per event, on its first attempt, rather than
+-- copying it onto every retry
+
+ALTER TABLE webhook_deliveries ALTER COLUMN payload DROP NOT NULL;